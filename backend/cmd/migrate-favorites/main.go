@@ -0,0 +1,60 @@
+// migrate-favorites は移行前のDynamoDBお気に入りテーブル（Favorites）の項目を
+// ユーザーIDと更新情報IDをキーとするお気に入りテーブル（UserFavorites）にコピーするコマンド
+//
+// 使い方:
+//
+//	go run ./cmd/migrate-favorites -region ap-northeast-1 [-endpoint http://localhost:8000] [-table-prefix dev-] [-dry-run]
+//
+// 既にコピー済みの項目は上書きしないため、サーバーの切り替え前後に繰り返し実行できる
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	dynamodb_repo "nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/dynamodb"
+)
+
+func main() {
+	defaultRegion := os.Getenv("DYNAMODB_REGION")
+	if defaultRegion == "" {
+		defaultRegion = "ap-northeast-1"
+	}
+
+	region := flag.String("region", defaultRegion, "DynamoDBのリージョン")
+	endpoint := flag.String("endpoint", os.Getenv("DYNAMODB_ENDPOINT"), "DynamoDB Localなどの接続先（省略時はAWSの既定の接続先）")
+	tablePrefix := flag.String("table-prefix", os.Getenv("DYNAMODB_TABLE_PREFIX"), "テーブル名の接頭辞")
+	billingMode := flag.String("billing-mode", os.Getenv("DYNAMODB_BILLING_MODE"), "移行先のテーブルを作成する場合の課金モード（PAY_PER_REQUESTまたはPROVISIONED）")
+	dryRun := flag.Bool("dry-run", false, "移行前のテーブルを読み込むだけで書き込まない")
+	flag.Parse()
+
+	cfg := dynamodb_repo.DefaultConfig(*region)
+	cfg.Endpoint = *endpoint
+	cfg.TablePrefix = *tablePrefix
+	mode, err := dynamodb_repo.ParseBillingMode(*billingMode)
+	if err != nil {
+		log.Fatalf("Invalid billing mode: %v", err)
+	}
+	cfg.BillingMode = mode
+
+	client, err := dynamodb_repo.NewDynamoDBClient(cfg)
+	if err != nil {
+		log.Fatalf("Failed to create DynamoDB client: %v", err)
+	}
+
+	// 移行先のテーブルがなければ作成
+	if !*dryRun {
+		if err := dynamodb_repo.CreateFavoriteTable(client, cfg); err != nil {
+			log.Fatalf("Failed to create DynamoDB table: %v", err)
+		}
+	}
+
+	result, err := dynamodb_repo.MigrateLegacyFavorites(client, cfg.Tables(), *dryRun)
+	if err != nil {
+		log.Fatalf("Migration failed after %d items: %v", result.Scanned, err)
+	}
+
+	log.Printf("Scanned %d favorites from %s: copied %d, skipped %d (dry run: %t)\n",
+		result.Scanned, cfg.Tables().LegacyFavorites, result.Copied, result.Skipped, *dryRun)
+}
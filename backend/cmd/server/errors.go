@@ -0,0 +1,20 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/interface/apierror"
+)
+
+// respondError はエラーの種別に応じたHTTPステータスコードとエラーコードでレスポンスを返す
+// レート制限の解除日時が分かる場合は、再試行までの秒数をRetry-Afterヘッダーで返す
+func respondError(c *gin.Context, err error) {
+	status, code := apierror.Classify(err)
+	if reset, ok := apierror.ResetAt(err); ok {
+		seconds := int(max(time.Until(reset).Seconds(), 0)) + 1
+		c.Header("Retry-After", strconv.Itoa(seconds))
+	}
+	c.JSON(status, gin.H{"error": err.Error(), "code": code})
+}
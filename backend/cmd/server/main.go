@@ -2,13 +2,17 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,8 +22,12 @@ import (
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/auth"
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/backlog"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/encryption"
 	dynamodb_repo "nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/dynamodb"
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/sqldb"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/search"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/interface/graphql"
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
 )
 
@@ -38,10 +46,71 @@ func main() {
 	tokenURL := getEnv("BACKLOG_TOKEN_URL", "")
 	port := getEnv("PORT", "8081")
 	frontendURL := getEnv("FRONTEND_URL", "http://localhost:3000")
+	secureCookie := strings.HasPrefix(frontendURL, "https://")
+
+	// セッション設定
+	sessionSecret := getEnv("SESSION_SECRET", "")
+	if sessionSecret == "" {
+		log.Println("Warning: SESSION_SECRET が設定されていないため、起動ごとにランダムな署名鍵を使用します。")
+		sessionSecret = randomSecret()
+	}
+	sessionTTL, err := time.ParseDuration(getEnv("SESSION_TTL", "168h"))
+	if err != nil {
+		log.Fatalf("Invalid SESSION_TTL: %v", err)
+	}
+
+	// デモモード設定（Backlog APIを呼び出さずにモックデータを返す）
+	demoMode := getEnv("BACKLOG_DEMO_MODE", "false") == "true"
+	if demoMode {
+		log.Println("Warning: BACKLOG_DEMO_MODE が有効なため、更新情報はモックデータを返します。")
+	}
+
+	// 全文検索インデックス設定（取り込み間隔を0にするとインデックスを使用せずBacklog APIを直接検索する）
+	// 取り込んだ更新情報はリポジトリと同じ保存先に保存し、再起動後や別のサーバーでもインデックスを復元する
+	indexInterval, err := time.ParseDuration(getEnv("ACTIVITY_INDEX_INTERVAL", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid ACTIVITY_INDEX_INTERVAL: %v", err)
+	}
+	indexBackfillPages, err := strconv.Atoi(getEnv("ACTIVITY_INDEX_BACKFILL_PAGES", "10"))
+	if err != nil {
+		log.Fatalf("Invalid ACTIVITY_INDEX_BACKFILL_PAGES: %v", err)
+	}
+	indexMaxItems, err := strconv.Atoi(getEnv("ACTIVITY_INDEX_MAX_ITEMS", "10000"))
+	if err != nil {
+		log.Fatalf("Invalid ACTIVITY_INDEX_MAX_ITEMS: %v", err)
+	}
+
+	// お気に入りのスナップショットを最新の課題の内容で更新する間隔（0で更新しない）
+	favoriteRefreshInterval, err := time.ParseDuration(getEnv("FAVORITE_REFRESH_INTERVAL", "30m"))
+	if err != nil {
+		log.Fatalf("Invalid FAVORITE_REFRESH_INTERVAL: %v", err)
+	}
 
 	// DynamoDB設定
 	useDynamoDB := getEnv("USE_DYNAMODB", "false") == "true"
-	dynamoDBRegion := getEnv("DYNAMODB_REGION", "ap-northeast-1")
+	dynamoDBConfig := dynamodb_repo.DefaultConfig(getEnv("DYNAMODB_REGION", "ap-northeast-1"))
+	// DynamoDB Localを使う場合は接続先を指定する（例: http://localhost:8000）
+	dynamoDBConfig.Endpoint = getEnv("DYNAMODB_ENDPOINT", "")
+	// 同じアカウントで環境ごとにテーブルを分ける場合の接頭辞（例: dev-）
+	dynamoDBConfig.TablePrefix = getEnv("DYNAMODB_TABLE_PREFIX", "")
+	dynamoDBConfig.BillingMode, err = dynamodb_repo.ParseBillingMode(getEnv("DYNAMODB_BILLING_MODE", "PROVISIONED"))
+	if err != nil {
+		log.Fatalf("Invalid DYNAMODB_BILLING_MODE: %v", err)
+	}
+	dynamoDBConfig.ReadCapacityUnits, err = strconv.ParseInt(getEnv("DYNAMODB_READ_CAPACITY", "5"), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid DYNAMODB_READ_CAPACITY: %v", err)
+	}
+	dynamoDBConfig.WriteCapacityUnits, err = strconv.ParseInt(getEnv("DYNAMODB_WRITE_CAPACITY", "5"), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid DYNAMODB_WRITE_CAPACITY: %v", err)
+	}
+	dynamoDBConfig.EnableTTL = getEnv("DYNAMODB_ENABLE_TTL", "true") == "true"
+	dynamoDBConfig.TTLAttribute = getEnv("DYNAMODB_TTL_ATTRIBUTE", dynamodb_repo.AuthTTLAttribute)
+
+	// SQLデータベース設定（DynamoDBを使えない環境向け。DATABASE_DRIVERにsqliteまたはpostgresを指定する）
+	databaseDriver := getEnv("DATABASE_DRIVER", "")
+	databaseURL := getEnv("DATABASE_URL", "file:backlog.db")
 
 	// OpenAI APIキーの取得
 	openaiAPIKey := getEnv("OPENAI_API_KEY", "")
@@ -49,31 +118,56 @@ func main() {
 		log.Println("Warning: OPENAI_API_KEY が見つかっていません、ダミーデータをレスオンするようになります。")
 	}
 
-	authRepo := memory.NewAuthRepository()
+	var authRepo model.AuthRepository
 	var favoriteRepo model.FavoriteRepository
+	var activityStore model.ActivityStore
 
-	// リポジトリの初期化（DynamoDBとメモリから選択）
+	// リポジトリの初期化（DynamoDB・SQLデータベース・メモリから選択）
 	if useDynamoDB {
-		log.Println("Using DynamoDB for favorite repository")
+		log.Println("Using DynamoDB for auth and favorite repositories")
+
+		envelope := newAuthEnvelope()
 
 		var dynamoClient *dynamodb.Client
-		var err error
 
-		dynamoClient, err = dynamodb_repo.NewDynamoDBClient(dynamoDBRegion)
+		dynamoClient, err = dynamodb_repo.NewDynamoDBClient(dynamoDBConfig)
 
 		if err != nil {
 			log.Fatalf("Failed to create DynamoDB client: %v", err)
 		}
 
-		// DynamoDBテーブルの作成
-		if err := dynamodb_repo.CreateFavoriteTable(dynamoClient); err != nil {
+		// DynamoDBテーブルの作成（全テーブルがACTIVEになるまで待ってからリクエストを受け付ける）
+		if err := dynamodb_repo.CreateTables(dynamoClient, dynamoDBConfig); err != nil {
 			log.Fatalf("Failed to create DynamoDB table: %v", err)
 		}
 
-		favoriteRepo = dynamodb_repo.NewFavoriteRepository(dynamoClient)
+		authRepo = dynamodb_repo.NewAuthRepository(dynamoClient, envelope, dynamoDBConfig.Tables(), dynamoDBConfig.TTLAttribute)
+		favoriteRepo = dynamodb_repo.NewFavoriteRepository(dynamoClient, dynamoDBConfig.Tables())
+		activityStore = dynamodb_repo.NewActivityStore(dynamoClient, dynamoDBConfig.Tables())
+	} else if databaseDriver != "" {
+		dialect, err := sqldb.ParseDialect(databaseDriver)
+		if err != nil {
+			log.Fatalf("Invalid DATABASE_DRIVER: %v", err)
+		}
+		log.Printf("Using %s for auth and favorite repositories", dialect)
+
+		envelope := newAuthEnvelope()
+
+		// 接続時に未適用のスキーママイグレーションを実行する
+		db, err := sqldb.Open(dialect, databaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		authRepo = sqldb.NewAuthRepository(db, envelope)
+		favoriteRepo = sqldb.NewFavoriteRepository(db)
+		activityStore = sqldb.NewActivityStore(db)
 	} else {
-		log.Println("Using in-memory favorite repository")
+		log.Println("Using in-memory auth and favorite repositories")
+		authRepo = memory.NewAuthRepository()
 		favoriteRepo = memory.NewFavoriteRepository()
+		activityStore = memory.NewActivityStore()
 	}
 
 	// OAuth設定
@@ -89,16 +183,34 @@ func main() {
 	// サービスの初期化
 	authService := auth.NewBacklogAuthService(oauthConfig, spaceURL)
 	backlogClient := backlog.NewBacklogClient(spaceURL, clientID, clientSecret)
-	backlogItemService := backlog.NewBacklogItemService(backlogClient, authRepo)
+	backlogItemService := backlog.NewBacklogItemService(backlogClient, demoMode)
 
 	// ユースケースの初期化
 	authUseCase := usecase.NewAuthUseCase(authService, authRepo)
-	backlogItemUseCase := usecase.NewBacklogItemUseCase(backlogItemService, favoriteRepo, authUseCase)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte(sessionSecret), sessionTTL)
+
+	// 全文検索インデックスとバックグラウンドでの取り込み
+	var activityIndex model.ActivityIndex
+	var activityIngester *usecase.ActivityIngester
+	if indexInterval > 0 {
+		activityIndex = search.NewActivityIndex(indexMaxItems)
+		activityIngester = usecase.NewActivityIngester(authRepo, authUseCase, backlogItemService, activityIndex, activityStore, indexBackfillPages)
+		go activityIngester.Run(context.Background(), indexInterval)
+	}
+
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(backlogItemService, favoriteRepo, authUseCase, activityIndex)
+	collectionUseCase := usecase.NewCollectionUseCase(favoriteRepo)
+
+	// お気に入りのスナップショットの定期更新
+	if favoriteRefreshInterval > 0 {
+		go usecase.NewFavoriteRefresher(authRepo, backlogItemUseCase).Run(context.Background(), favoriteRefreshInterval)
+	}
 
 	r := gin.Default()
 	// CORSミドルウェア
 	r.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		// セッションCookieを送受信するため、フロントエンドのオリジンのみ許可
+		c.Writer.Header().Set("Access-Control-Allow-Origin", frontendURL)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
@@ -113,7 +225,15 @@ func main() {
 
 	// 認証関連のエンドポイント https://github.com/gin-gonic/gin
 	r.GET("/api/auth/url", func(c *gin.Context) {
-		authURL := authUseCase.GetAuthorizationURL()
+		authURL, state, err := authUseCase.GetAuthorizationURL()
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		// コールバックをログインを開始したブラウザに限定するため、stateをCookieにも保存
+		setOAuthStateCookie(c, state, authUseCase.StateTTL(), secureCookie)
+
 		c.JSON(http.StatusOK, gin.H{
 			"url": authURL,
 		})
@@ -135,113 +255,404 @@ func main() {
 			return
 		}
 
-		// ユーザー認証とトークン取得（バックエンドの処理）
-		token, user, err := authUseCase.AuthorizeCallback(code)
+		// stateはCookieと一致する場合だけ受け付け、成否にかかわらずCookieは使い捨てにする
+		browserState, _ := c.Cookie(oauthStateCookieName)
+		clearOAuthStateCookie(c, secureCookie)
+
+		// ユーザー認証とトークン取得（トークンはバックエンドにのみ保存）
+		_, user, err := authUseCase.AuthorizeCallback(code, c.Query("state"), browserState)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		// トークンとユーザー情報をURLエンコードしてフロントエンドに渡す
-		tokenJSON, _ := json.Marshal(token)
-		userJSON, _ := json.Marshal(user)
+		// フロントエンドがセッションと交換するための一度きりのコードを発行
+		exchangeCode, err := sessionUseCase.CreateExchangeCode(user.ID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
 
-		// URLセーフなBase64エンコード
-		tokenBase64 := base64.URLEncoding.EncodeToString(tokenJSON)
-		userBase64 := base64.URLEncoding.EncodeToString(userJSON)
+		// 次の定期実行を待たずにログインしたユーザーの更新情報を取り込む
+		if activityIngester != nil {
+			go func(userID string) {
+				if err := activityIngester.IngestUser(userID); err != nil {
+					log.Printf("Failed to ingest activities for user %s: %v", userID, err)
+				}
+			}(user.ID)
+		}
 
 		// フロントエンドのコールバックページにリダイレクト
-		redirectURL := fmt.Sprintf("%s/auth/callback?token=%s&user=%s", frontendURL, tokenBase64, userBase64)
+		redirectURL := fmt.Sprintf("%s/auth/callback?code=%s", frontendURL, url.QueryEscape(exchangeCode))
 		c.Redirect(http.StatusFound, redirectURL)
 	})
 
-	r.GET("/api/auth/logout/:userId", func(c *gin.Context) {
-		userID := c.Param("userId")
-		if userID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+	// 交換コードをセッションに交換するエンドポイント
+	r.POST("/api/auth/session", func(c *gin.Context) {
+		var request struct {
+			Code string `json:"code"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "exchange code is required"})
+			return
+		}
+
+		sessionToken, session, err := sessionUseCase.ExchangeSession(request.Code)
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
-		err := authUseCase.Logout(userID)
+		user, err := authUseCase.GetCurrentUser(session.UserID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
+		setSessionCookie(c, sessionToken, sessionUseCase.TTL(), secureCookie)
+		c.JSON(http.StatusOK, gin.H{
+			"user":      user,
+			"expiresAt": session.ExpiresAt,
+		})
+	})
+
+	// セッションが必要なエンドポイント
+	authorized := r.Group("/api")
+	authorized.Use(requireSession(sessionUseCase))
+
+	authorized.POST("/auth/logout", func(c *gin.Context) {
+		err := authUseCase.Logout(currentUserID(c))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		clearSessionCookie(c, secureCookie)
 		c.JSON(http.StatusOK, gin.H{"success": true})
 	})
 
 	// Backlog更新情報関連のエンドポイント
-	r.GET("/api/items", func(c *gin.Context) {
-		userID := c.Query("userId")
-		keyword := c.Query("keyword")
+	authorized.GET("/items", func(c *gin.Context) {
+		input, err := searchInputFromQuery(c)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
 
-		if userID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+		result, err := backlogItemUseCase.SearchItems(currentUserID(c), input, pageRequestFromQuery(c))
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
-		items, err := backlogItemUseCase.SearchItems(userID, keyword)
+		c.JSON(http.StatusOK, gin.H{"items": result.Items, "nextCursor": result.NextCursor})
+	})
+
+	// ユーザー自身が作成した更新情報
+	authorized.GET("/me/items", func(c *gin.Context) {
+		result, err := backlogItemUseCase.GetMyItems(currentUserID(c), pageRequestFromQuery(c))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"items": items})
+		c.JSON(http.StatusOK, gin.H{"items": result.Items, "nextCursor": result.NextCursor})
 	})
 
-	r.GET("/api/favorites/:userId", func(c *gin.Context) {
-		userID := c.Param("userId")
-		if userID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+	// ユーザー自身と、担当・ウォッチしている課題の更新情報
+	authorized.GET("/me/feed", func(c *gin.Context) {
+		result, err := backlogItemUseCase.GetPersonalFeed(currentUserID(c), pageRequestFromQuery(c))
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
-		favorites, err := backlogItemUseCase.GetFavorites(userID)
+		c.JSON(http.StatusOK, gin.H{"items": result.Items, "nextCursor": result.NextCursor})
+	})
+
+	// Backlog APIの残りリクエスト数などのレート制限の状態（診断用）
+	authorized.GET("/diagnostics/rate-limit", func(c *gin.Context) {
+		rateLimit, err := backlogItemUseCase.GetRateLimit(currentUserID(c))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"items": favorites})
+		c.JSON(http.StatusOK, gin.H{"rateLimit": rateLimit})
 	})
 
-	r.POST("/api/favorites/:userId/:itemId", func(c *gin.Context) {
-		userID := c.Param("userId")
-		itemID := c.Param("itemId")
+	// 参加しているプロジェクトの一覧
+	authorized.GET("/projects", func(c *gin.Context) {
+		projects, err := backlogItemUseCase.GetProjects(currentUserID(c))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"projects": projects})
+	})
 
-		if userID == "" || itemID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user ID and item ID are required"})
+	// プロジェクトの更新情報（:projectIdにはプロジェクトIDまたはプロジェクトキーを指定、projectId以外の検索条件は/itemsと同じ）
+	authorized.GET("/projects/:projectId/items", func(c *gin.Context) {
+		input, err := searchInputFromQuery(c)
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
-		err := backlogItemUseCase.AddFavorite(userID, itemID)
+		result, err := backlogItemUseCase.GetProjectItems(currentUserID(c), c.Param("projectId"), input, pageRequestFromQuery(c))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	})
+
+	authorized.GET("/favorites", func(c *gin.Context) {
+		filter := usecase.FavoriteFilter{
+			CollectionID: c.Query("collectionId"),
+			Tag:          c.Query("tag"),
+		}
+
+		result, err := backlogItemUseCase.GetFavorites(currentUserID(c), filter, pageRequestFromQuery(c))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"items": result.Items, "nextCursor": result.NextCursor})
+	})
+
+	authorized.POST("/favorites/:itemId", func(c *gin.Context) {
+		itemID := c.Param("itemId")
+		if itemID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item ID is required"})
+			return
+		}
+
+		err := backlogItemUseCase.AddFavorite(currentUserID(c), itemID)
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"success": true})
 	})
 
-	r.DELETE("/api/favorites/:userId/:itemId", func(c *gin.Context) {
-		userID := c.Param("userId")
+	authorized.DELETE("/favorites/:itemId", func(c *gin.Context) {
 		itemID := c.Param("itemId")
+		if itemID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item ID is required"})
+			return
+		}
+
+		err := backlogItemUseCase.RemoveFavorite(currentUserID(c), itemID)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
 
-		if userID == "" || itemID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "user ID and item ID are required"})
+	// お気に入りのコレクション・タグ・メモを更新（省略した項目は空に戻す）
+	authorized.PUT("/favorites/:itemId", func(c *gin.Context) {
+		var request struct {
+			CollectionID string   `json:"collectionId"`
+			Tags         []string `json:"tags"`
+			Note         string   `json:"note"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		err := backlogItemUseCase.AnnotateFavorite(currentUserID(c), c.Param("itemId"), usecase.FavoriteAnnotationInput{
+			CollectionID: request.CollectionID,
+			Tags:         request.Tags,
+			Note:         request.Note,
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	// お気に入りのコレクション関連のエンドポイント
+	authorized.GET("/collections", func(c *gin.Context) {
+		collections, err := collectionUseCase.ListCollections(currentUserID(c))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"collections": collections})
+	})
+
+	authorized.POST("/collections", func(c *gin.Context) {
+		var request struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		collection, err := collectionUseCase.CreateCollection(currentUserID(c), usecase.CollectionInput{
+			Name:        request.Name,
+			Description: request.Description,
+		})
+		if err != nil {
+			respondError(c, err)
 			return
 		}
 
-		err := backlogItemUseCase.RemoveFavorite(userID, itemID)
+		c.JSON(http.StatusCreated, collection)
+	})
+
+	authorized.GET("/collections/:collectionId", func(c *gin.Context) {
+		collection, err := collectionUseCase.GetCollection(currentUserID(c), c.Param("collectionId"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, collection)
+	})
+
+	authorized.PUT("/collections/:collectionId", func(c *gin.Context) {
+		var request struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		collection, err := collectionUseCase.UpdateCollection(currentUserID(c), c.Param("collectionId"), usecase.CollectionInput{
+			Name:        request.Name,
+			Description: request.Description,
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, collection)
+	})
+
+	authorized.DELETE("/collections/:collectionId", func(c *gin.Context) {
+		err := collectionUseCase.DeleteCollection(currentUserID(c), c.Param("collectionId"))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondError(c, err)
 			return
 		}
 
 		c.JSON(http.StatusOK, gin.H{"success": true})
 	})
 
+	authorized.GET("/collections/:collectionId/items", func(c *gin.Context) {
+		filter := usecase.FavoriteFilter{
+			CollectionID: c.Param("collectionId"),
+			Tag:          c.Query("tag"),
+		}
+
+		result, err := backlogItemUseCase.GetFavorites(currentUserID(c), filter, pageRequestFromQuery(c))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"items": result.Items, "nextCursor": result.NextCursor})
+	})
+
+	authorized.DELETE("/collections/:collectionId/items/:itemId", func(c *gin.Context) {
+		err := collectionUseCase.RemoveCollectionItem(currentUserID(c), c.Param("collectionId"), c.Param("itemId"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	// 共有コレクションのメンバー関連のエンドポイント
+	authorized.GET("/collections/:collectionId/members", func(c *gin.Context) {
+		members, err := collectionUseCase.ListMembers(currentUserID(c), c.Param("collectionId"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"members": members})
+	})
+
+	// BacklogユーザーIDを指定してメンバーを招待（招待済みの場合は権限を変更）
+	authorized.POST("/collections/:collectionId/members", func(c *gin.Context) {
+		var request struct {
+			UserID string               `json:"userId"`
+			Role   model.CollectionRole `json:"role"`
+		}
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+			return
+		}
+
+		member, err := collectionUseCase.InviteMember(currentUserID(c), c.Param("collectionId"), usecase.CollectionMemberInput{
+			UserID: request.UserID,
+			Role:   request.Role,
+		})
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	})
+
+	authorized.DELETE("/collections/:collectionId/members/:userId", func(c *gin.Context) {
+		err := collectionUseCase.RemoveMember(currentUserID(c), c.Param("collectionId"), c.Param("userId"))
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+
+	// GraphQLエンドポイント
+	graphqlHandler := graphql.NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, collectionUseCase)
+	r.POST("/graphql", func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		// セッションからユーザーを特定（未認証でも参照できるフィールドがあるため拒否はしない）
+		if session, err := sessionUseCase.ResolveSession(sessionTokenFromRequest(c)); err == nil {
+			ctx = graphql.WithUserID(ctx, session.UserID)
+		}
+		ctx = graphql.WithStateBinder(ctx, func(state string) {
+			setOAuthStateCookie(c, state, authUseCase.StateTTL(), secureCookie)
+		})
+		ctx = graphql.WithSessionBinder(ctx, func(sessionToken string) {
+			if sessionToken == "" {
+				clearSessionCookie(c, secureCookie)
+				return
+			}
+			setSessionCookie(c, sessionToken, sessionUseCase.TTL(), secureCookie)
+		})
+
+		graphqlHandler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	})
+
 	// AI分析APIエンドポイント
 	r.POST("/api/ai/analyze", handleAIAnalyze)
 
@@ -269,6 +680,32 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
+// newAuthEnvelope はAUTH_ENCRYPTION_KEY（Base64エンコードされた32バイト）からトークンを暗号化するエンベロープを生成
+func newAuthEnvelope() *encryption.Envelope {
+	encodedKey := getEnv("AUTH_ENCRYPTION_KEY", "")
+	if encodedKey == "" {
+		log.Fatal("AUTH_ENCRYPTION_KEY が設定されていません。`openssl rand -base64 32` で生成した値を設定してください。")
+	}
+	masterKey, err := encryption.ParseKey(encodedKey)
+	if err != nil {
+		log.Fatalf("Invalid AUTH_ENCRYPTION_KEY (`openssl rand -base64 32` で生成してください): %v", err)
+	}
+	envelope, err := encryption.NewEnvelope(masterKey)
+	if err != nil {
+		log.Fatalf("Invalid AUTH_ENCRYPTION_KEY (`openssl rand -base64 32` で生成してください): %v", err)
+	}
+	return envelope
+}
+
+// randomSecret はセッション署名用のランダムな鍵を生成
+func randomSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		log.Fatalf("Failed to generate session secret: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
 // handleAIAnalyze は更新情報のAI分析を行うハンドラー
 func handleAIAnalyze(c *gin.Context) {
 	var request struct {
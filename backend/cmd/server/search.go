@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+// searchInputFromQuery はクエリパラメータから検索条件を組み立てる
+// 複数の値はパラメータの繰り返し（projectId=1&projectId=2）またはカンマ区切り（projectId=1,2）で指定する
+func searchInputFromQuery(c *gin.Context) (usecase.SearchInput, error) {
+	input := usecase.SearchInput{
+		Keyword:        c.Query("keyword"),
+		ProjectIDs:     queryValues(c, "projectId"),
+		CreatedUserIDs: queryValues(c, "createdUserId"),
+		Since:          c.Query("since"),
+		Until:          c.Query("until"),
+	}
+
+	for _, v := range queryValues(c, "typeId") {
+		typeID, err := strconv.Atoi(v)
+		if err != nil {
+			return usecase.SearchInput{}, fmt.Errorf("%w: invalid typeId %q", usecase.ErrInvalidSearchCriteria, v)
+		}
+		input.TypeIDs = append(input.TypeIDs, typeID)
+	}
+
+	return input, nil
+}
+
+// pageRequestFromQuery はクエリパラメータからページングの指定を組み立てる
+func pageRequestFromQuery(c *gin.Context) model.PageRequest {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return model.PageRequest{Cursor: c.Query("cursor"), Limit: limit}
+}
+
+// queryValues は繰り返し・カンマ区切りで指定されたクエリパラメータの値を空要素を除いて取得
+func queryValues(c *gin.Context, key string) []string {
+	var values []string
+	for _, param := range c.QueryArray(key) {
+		for _, v := range strings.Split(param, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
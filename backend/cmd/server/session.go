@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/interface/apierror"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+const (
+	// sessionCookieName はセッショントークンを格納するCookie名
+	sessionCookieName = "backlog_session"
+	// oauthStateCookieName はログインを開始したブラウザのOAuth stateを格納するCookie名
+	oauthStateCookieName = "backlog_oauth_state"
+	// oauthStateCookiePath はOAuth stateのCookieを送信するパス（コールバック以外には送信しない）
+	oauthStateCookiePath = "/api/auth/callback"
+	// contextKeyUserID はginコンテキストに認証済みユーザーIDを格納するキー
+	contextKeyUserID = "userID"
+)
+
+// requireSession はセッションから呼び出し元ユーザーを特定し、セッションが無効な場合はリクエストを拒否するミドルウェア
+func requireSession(sessionUseCase *usecase.SessionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := sessionUseCase.ResolveSession(sessionTokenFromRequest(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "valid session is required",
+				"code":  apierror.CodeUnauthorized,
+			})
+			return
+		}
+
+		c.Set(contextKeyUserID, session.UserID)
+		c.Next()
+	}
+}
+
+// currentUserID はrequireSessionで特定されたユーザーIDを取得
+func currentUserID(c *gin.Context) string {
+	return c.GetString(contextKeyUserID)
+}
+
+// sessionTokenFromRequest はCookieまたはAuthorizationヘッダーからセッショントークンを取得
+func sessionTokenFromRequest(c *gin.Context) string {
+	if token, err := c.Cookie(sessionCookieName); err == nil && token != "" {
+		return token
+	}
+
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return token
+	}
+
+	return ""
+}
+
+// setSessionCookie はセッショントークンをHttpOnly Cookieとして設定
+func setSessionCookie(c *gin.Context, token string, ttl time.Duration, secure bool) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookieName, token, int(ttl.Seconds()), "/", "", secure, true)
+}
+
+// clearSessionCookie はセッションCookieを削除
+func clearSessionCookie(c *gin.Context, secure bool) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookieName, "", -1, "/", "", secure, true)
+}
+
+// setOAuthStateCookie はログインを開始したブラウザにOAuth stateをHttpOnly Cookieとして設定
+// Backlogからのリダイレクト（トップレベルのGET）でも送信されるようSameSite=Laxにする
+func setOAuthStateCookie(c *gin.Context, state string, ttl time.Duration, secure bool) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookieName, state, int(ttl.Seconds()), oauthStateCookiePath, "", secure, true)
+}
+
+// clearOAuthStateCookie はOAuth stateのCookieを削除
+func clearOAuthStateCookie(c *gin.Context, secure bool) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookieName, "", -1, oauthStateCookiePath, "", secure, true)
+}
@@ -12,7 +12,10 @@ require (
 	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.42.4
 	github.com/gin-gonic/gin v1.9.1
 	github.com/google/uuid v1.6.0
+	github.com/graph-gophers/graphql-go v1.5.0
 	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.9.0
+	github.com/mattn/go-sqlite3 v1.14.33
 	golang.org/x/oauth2 v0.13.0
 )
 
@@ -0,0 +1,100 @@
+package model
+
+// ActivityContent は更新情報の種別ごとの内容を表す
+// 種別に対応するいずれか1つのフィールドだけが設定される
+type ActivityContent struct {
+	Issue       *IssueContent       `json:"issue,omitempty"`
+	Comment     *CommentContent     `json:"comment,omitempty"`
+	Wiki        *WikiContent        `json:"wiki,omitempty"`
+	File        *FileContent        `json:"file,omitempty"`
+	GitPush     *GitPushContent     `json:"gitPush,omitempty"`
+	PullRequest *PullRequestContent `json:"pullRequest,omitempty"`
+	Milestone   *MilestoneContent   `json:"milestone,omitempty"`
+	Membership  *MembershipContent  `json:"membership,omitempty"`
+}
+
+// ActivityChange は更新情報で変更された項目（状態・担当者など）と変更前後の値を表す
+type ActivityChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
+
+// IssueContent は課題の追加・更新・削除の内容を表す
+// まとめて更新した場合はLinkedIssueKeysに対象の課題キーが設定される
+type IssueContent struct {
+	IssueKey        string           `json:"issueKey,omitempty"`
+	Summary         string           `json:"summary"`
+	Description     string           `json:"description,omitempty"`
+	Comment         string           `json:"comment,omitempty"`
+	Changes         []ActivityChange `json:"changes,omitempty"`
+	LinkedIssueKeys []string         `json:"linkedIssueKeys,omitempty"`
+}
+
+// CommentContent は課題へのコメントの内容を表す
+type CommentContent struct {
+	IssueKey  string           `json:"issueKey"`
+	Summary   string           `json:"summary"`
+	CommentID string           `json:"commentId"`
+	Body      string           `json:"body"`
+	Changes   []ActivityChange `json:"changes,omitempty"`
+}
+
+// WikiContent はWikiの追加・更新・削除の内容を表す
+type WikiContent struct {
+	WikiID  string `json:"wikiId"`
+	Name    string `json:"name"`
+	Diff    string `json:"diff,omitempty"`
+	Version int    `json:"version,omitempty"`
+}
+
+// FileContent は共有ファイルの追加・更新・削除の内容を表す
+type FileContent struct {
+	FileID string `json:"fileId"`
+	Dir    string `json:"dir"`
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+}
+
+// GitPushContent はGitリポジトリの作成とプッシュの内容を表す
+type GitPushContent struct {
+	Repository    string        `json:"repository"`
+	Ref           string        `json:"ref,omitempty"`
+	ChangeType    string        `json:"changeType,omitempty"`
+	RevisionCount int           `json:"revisionCount"`
+	Revisions     []GitRevision `json:"revisions,omitempty"`
+}
+
+// GitRevision はプッシュに含まれるコミットを表す
+type GitRevision struct {
+	Rev     string `json:"rev"`
+	Comment string `json:"comment"`
+}
+
+// PullRequestContent はプルリクエストの追加・更新・コメント・削除の内容を表す
+type PullRequestContent struct {
+	Repository  string           `json:"repository"`
+	Number      int              `json:"number"`
+	Summary     string           `json:"summary"`
+	Description string           `json:"description,omitempty"`
+	Comment     string           `json:"comment,omitempty"`
+	IssueKey    string           `json:"issueKey,omitempty"` // プルリクエストに関連付けられた課題の課題キー
+	Changes     []ActivityChange `json:"changes,omitempty"`
+}
+
+// MilestoneContent はマイルストーンの追加・更新・削除の内容を表す
+type MilestoneContent struct {
+	MilestoneID   string           `json:"milestoneId"`
+	Name          string           `json:"name"`
+	Description   string           `json:"description,omitempty"`
+	StartDate     string           `json:"startDate,omitempty"`
+	ReferenceDate string           `json:"referenceDate,omitempty"`
+	Changes       []ActivityChange `json:"changes,omitempty"`
+}
+
+// MembershipContent はユーザー・グループのプロジェクトへの参加と脱退の内容を表す
+type MembershipContent struct {
+	Users   []User   `json:"users,omitempty"`
+	Groups  []string `json:"groups,omitempty"`
+	Comment string   `json:"comment,omitempty"`
+}
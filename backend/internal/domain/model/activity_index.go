@@ -0,0 +1,90 @@
+package model
+
+// SearchHit は全文検索でヒットしたBacklog更新情報を表す
+type SearchHit struct {
+	Item       *BacklogItem
+	Score      float64
+	Highlights []Highlight
+}
+
+// SearchHitPage はページングされた全文検索の結果を表す
+type SearchHitPage struct {
+	Hits       []*SearchHit
+	NextCursor string
+}
+
+// Highlight は検索キーワードに一致した箇所を含むフィールドの断片を表す
+type Highlight struct {
+	Field    string             `json:"field"`
+	Segments []HighlightSegment `json:"segments"`
+}
+
+// HighlightSegment はハイライト断片を一致箇所とそれ以外に分割した要素を表す
+type HighlightSegment struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match"`
+}
+
+// IngestState はユーザーの更新情報をインデックスに取り込んだ状況を表す
+// 取り込みは1回あたりのページ数に上限があるため、遡りきれなかった範囲を記録して次回以降に再開する
+type IngestState struct {
+	// Gaps は新しい更新情報の取り込みで前回の最新の更新情報まで遡りきれなかった範囲（新しい順）
+	Gaps []IngestGap
+	// ReachedOldest は最も古い更新情報まで取り込み済みか
+	ReachedOldest bool
+	// Truncated は件数の上限を超えて古い更新情報を削除したか。削除した場合はそれより古い更新情報を取り込まない
+	Truncated bool
+	// Version は取り込みを保存するたびに1ずつ増える番号。ほかのサーバーが先に保存したかの判定に使う
+	Version int
+}
+
+// IngestGap は取り込みが済んでいない更新情報IDの範囲を表す
+type IngestGap struct {
+	// MaxID はこの更新情報IDより古いものから取り込みを再開する
+	MaxID int
+	// MinID はこの更新情報ID以下に達したら範囲を取り込み済みとする
+	MinID int
+}
+
+// ActivityIndex はユーザーごとに取り込んだBacklog更新情報の全文検索インデックスのインターフェース
+// 各ユーザーのインデックスにはそのユーザーのアクセストークンで取得した更新情報だけを格納する
+type ActivityIndex interface {
+	// Add は更新情報をインデックスに追加し、取り込み状況を更新する。itemsが空でもユーザーのインデックスを作成する
+	Add(userID string, items []*BacklogItem, state IngestState) error
+	// Search は検索条件に一致する更新情報をスコア順（キーワードがない場合は新しい順）に返す
+	// 一致する更新情報を返し終えた時点で、インデックスより古い更新情報が残っている場合は
+	// 最も古いインデックス済みの更新情報から続きを検索するBacklog APIのカーソルをNextCursorに返す
+	Search(userID string, criteria SearchCriteria, page PageRequest) (*SearchHitPage, error)
+	// Get はユーザーのインデックスからIDを指定して更新情報を取得する
+	Get(userID string, itemID string) (*BacklogItem, bool)
+	// Ready はユーザーのインデックスが作成済みかを返す
+	Ready(userID string) bool
+	// LatestID はユーザーのインデックスに含まれる最新の更新情報IDを返す
+	LatestID(userID string) int
+	// OldestID はユーザーのインデックスに含まれる最も古い更新情報IDを返す
+	OldestID(userID string) int
+	// IngestState はユーザーの更新情報の取り込み状況を返す
+	IngestState(userID string) IngestState
+	// Users はインデックスが作成済みのユーザーIDを返す
+	Users() []string
+	// Remove はユーザーのインデックスを削除する
+	Remove(userID string)
+}
+
+// ActivityStore はインデックスに取り込んだ更新情報と取り込み状況を保存するリポジトリのインターフェース
+// 再起動したサーバーや同じユーザーを扱う別のサーバーが、Backlog APIから取り込み直さずにインデックスを復元するために使う
+type ActivityStore interface {
+	// SaveActivities は更新情報（同じIDは上書き）と取り込み状況を保存する
+	// 保存済みの取り込み状況のVersionがstate.Version-1と一致しない場合はErrIngestStateConflictを返す
+	SaveActivities(userID string, items []*BacklogItem, state IngestState) error
+	// LoadIngestState は保存済みの取り込み状況を返す。保存されていない場合はfalseを返す
+	LoadIngestState(userID string) (IngestState, bool, error)
+	// LoadActivities は保存済みの更新情報を返す
+	LoadActivities(userID string) ([]*BacklogItem, error)
+	// PruneActivities はoldestIDより古い更新情報を削除する
+	PruneActivities(userID string, oldestID int) error
+	// DeleteActivities はユーザーの更新情報と取り込み状況を削除する
+	DeleteActivities(userID string) error
+	// ActivityUsers は取り込み状況を保存しているユーザーIDを返す
+	ActivityUsers() ([]string, error)
+}
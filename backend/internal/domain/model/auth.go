@@ -13,6 +13,28 @@ type AuthToken struct {
 	UserID       string    `json:"userId"`
 }
 
+// Session はログインセッションを表すドメインモデル
+type Session struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExchangeCode はOAuthコールバック後にフロントエンドへ渡す一度きりの交換コード
+type ExchangeCode struct {
+	Code      string    `json:"code"`
+	UserID    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// OAuthState はOAuth認可リクエストごとのstateとPKCEのcode_verifier
+type OAuthState struct {
+	State        string    `json:"state"`
+	CodeVerifier string    `json:"codeVerifier"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
 // OAuthConfig はOAuth2.0認証の設定情報
 type OAuthConfig struct {
 	ClientID     string
@@ -25,8 +47,8 @@ type OAuthConfig struct {
 
 // AuthService は認証に関するドメインサービスのインターフェース
 type AuthService interface {
-	GetAuthorizationURL() string
-	ExchangeCodeForToken(code string) (*AuthToken, error)
+	GetAuthorizationURL(state string, codeVerifier string) string
+	ExchangeCodeForToken(code string, codeVerifier string) (*AuthToken, error)
 	RefreshToken(refreshToken string) (*AuthToken, error)
 	GetBacklogUser(accessToken string) (*User, error)
 }
@@ -38,4 +60,14 @@ type AuthRepository interface {
 	DeleteToken(userID string) error
 	GetToken(userID string) (*AuthToken, error)
 	GetAllTokens() ([]*AuthToken, error)
+	SaveSession(session *Session) error
+	GetSession(sessionID string) (*Session, error)
+	DeleteSession(sessionID string) error
+	DeleteSessionsByUserID(userID string) error
+	SaveExchangeCode(code *ExchangeCode) error
+	// ConsumeExchangeCode は交換コードを取得すると同時に削除する
+	ConsumeExchangeCode(code string) (*ExchangeCode, error)
+	SaveOAuthState(state *OAuthState) error
+	// ConsumeOAuthState はstateを取得すると同時に削除する
+	ConsumeOAuthState(state string) (*OAuthState, error)
 }
@@ -8,11 +8,26 @@ import (
 type BacklogItem struct {
 	ID             string    `json:"id"`
 	ProjectID      string    `json:"projectId"`
+	ProjectKey     string    `json:"projectKey"`
 	ProjectName    string    `json:"projectName"`
+	TypeID         int       `json:"typeId"`
 	Type           string    `json:"type"`
+	IssueKey       string    `json:"issueKey,omitempty"` // 課題に関する更新情報の場合の課題キー（例: PROJ-12）
 	ContentSummary string    `json:"contentSummary"`
 	CreatedUser    User      `json:"createdUser"`
 	Created        time.Time `json:"created"`
+	URL            string    `json:"url"`
+
+	// Contentは種別ごとの内容（対応する種別がない場合はnil）
+	Content *ActivityContent `json:"content,omitempty"`
+}
+
+// Issue はBacklogの課題の現在の状態を表す
+type Issue struct {
+	IssueKey string    `json:"issueKey"`
+	Summary  string    `json:"summary"`
+	Status   string    `json:"status"`
+	Updated  time.Time `json:"updated"`
 }
 
 // User はBacklogのユーザー情報を表す
@@ -24,10 +39,59 @@ type User struct {
 	MailAddress string `json:"mailAddress"`
 }
 
+// PageRequest はカーソルによるページングの指定を表す
+// Cursorは前のページのNextCursorをそのまま指定し、空の場合は最新から取得する
+type PageRequest struct {
+	Cursor string
+	Limit  int
+}
+
+const (
+	// DefaultPageLimit は1ページあたりの既定の件数
+	DefaultPageLimit = 20
+	// MaxPageLimit は1ページあたりの最大件数
+	MaxPageLimit = 100
+)
+
+// Size は1ページあたりの件数を既定値と上限の範囲に収めて返す
+func (p PageRequest) Size() int {
+	if p.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return p.Limit
+}
+
+// BacklogItemPage はページングされたBacklog更新情報を表す
+// NextCursorが空の場合はそれより古い更新情報が存在しない
+type BacklogItemPage struct {
+	Items      []*BacklogItem
+	NextCursor string
+}
+
 // BacklogItemService はBacklogItemに関するドメインサービスのインターフェース
+// Backlog APIは呼び出し元ユーザーのアクセストークンで呼び出し、そのユーザーが閲覧できる情報だけを返す
 type BacklogItemService interface {
-	SearchItems(keyword string) ([]*BacklogItem, error)
-	GetFavorites(userID string) ([]*BacklogItem, error)
-	AddFavorite(userID string, itemID string) error
-	RemoveFavorite(userID string, itemID string) error
+	// SearchItems は検索条件で更新情報を検索する。プロジェクトを1つだけ指定した場合はそのプロジェクトの更新情報だけを遡る
+	SearchItems(accessToken string, criteria SearchCriteria, page PageRequest) (*BacklogItemPage, error)
+	// GetItem はIDを指定して更新情報を取得する。存在しない場合はErrItemNotFoundを返す
+	GetItem(accessToken string, itemID string) (*BacklogItem, error)
+	// GetIssue は課題キーを指定して課題の現在の状態を取得する。存在しない場合はErrItemNotFoundを返す
+	GetIssue(accessToken string, issueKey string) (*Issue, error)
+	// GetUserItems はユーザーが作成した更新情報を新しい順に取得する
+	GetUserItems(accessToken string, userID string, page PageRequest) (*BacklogItemPage, error)
+	// SearchPersonalItems はユーザー自身の更新情報と担当・ウォッチしている課題の更新情報を新しい順に取得する
+	SearchPersonalItems(accessToken string, feed PersonalFeed, page PageRequest) (*BacklogItemPage, error)
+	// GetAssignedIssues はユーザーが担当している課題を更新日時の新しい順に取得する
+	GetAssignedIssues(accessToken string, userID string) ([]*Issue, error)
+	// GetWatchedIssues はユーザーがウォッチしている課題を更新日時の新しい順に取得する
+	GetWatchedIssues(accessToken string, userID string) ([]*Issue, error)
+	// GetProjects は呼び出し元ユーザーが参加しているプロジェクトを取得する
+	GetProjects(accessToken string) ([]*Project, error)
+	// GetProject はプロジェクトIDまたはプロジェクトキーを指定してプロジェクトを取得する。参加していない場合はErrProjectNotFoundを返す
+	GetProject(accessToken string, projectIDOrKey string) (*Project, error)
+	// GetRateLimit はアクセストークンのBacklog APIのレート制限の状態を返す。まだBacklog APIを呼び出していない場合はnilを返す
+	GetRateLimit(accessToken string) *RateLimit
 }
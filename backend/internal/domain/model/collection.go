@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+)
+
+// Collection はユーザーがお気に入りを整理するためのコレクション（フォルダ）を表すドメインモデル
+// UserIDはコレクションを作成したオーナーのBacklogユーザーID
+type Collection struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// CollectionRole は共有コレクションでのメンバーの権限
+type CollectionRole string
+
+const (
+	// CollectionRoleOwner はコレクションの編集・削除とメンバー管理ができる作成者の権限
+	CollectionRoleOwner CollectionRole = "owner"
+	// CollectionRoleEditor はコレクションへのお気に入りの追加・除外ができる権限
+	CollectionRoleEditor CollectionRole = "editor"
+	// CollectionRoleViewer はコレクションの閲覧のみができる権限
+	CollectionRoleViewer CollectionRole = "viewer"
+)
+
+// CanEdit はコレクションに入れるお気に入りを変更できる権限か判定
+func (r CollectionRole) CanEdit() bool {
+	return r == CollectionRoleOwner || r == CollectionRoleEditor
+}
+
+// CanManage はコレクション自体の変更とメンバー管理ができる権限か判定
+func (r CollectionRole) CanManage() bool {
+	return r == CollectionRoleOwner
+}
+
+// CollectionMember はコレクションを共有されたメンバーを表すドメインモデル
+// オーナーはCollection.UserIDで表し、メンバーとしては保存しない
+type CollectionMember struct {
+	CollectionID string         `json:"collectionId"`
+	UserID       string         `json:"userId"`
+	Role         CollectionRole `json:"role"`
+	AddedAt      time.Time      `json:"addedAt"`
+}
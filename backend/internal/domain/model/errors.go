@@ -0,0 +1,35 @@
+package model
+
+import (
+	"errors"
+)
+
+var (
+	// ErrTokenNotFound はユーザーのトークンが保存されていないエラー
+	ErrTokenNotFound = errors.New("token not found")
+	// ErrItemNotFound は更新情報が存在しない、または閲覧できないエラー
+	ErrItemNotFound = errors.New("backlog item not found")
+	// ErrFavoriteNotFound はお気に入りが存在しないエラー
+	ErrFavoriteNotFound = errors.New("favorite not found")
+	// ErrFavoriteAlreadyExists は同じ更新情報のお気に入りが既に存在するエラー
+	ErrFavoriteAlreadyExists = errors.New("favorite already exists")
+	// ErrProjectNotFound はプロジェクトが存在しない、または参加していないエラー
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrCollectionNotFound はコレクションが存在しない、または参照できないエラー
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrCollectionForbidden はコレクションでの権限が不足しているエラー
+	ErrCollectionForbidden = errors.New("insufficient collection permission")
+	// ErrIngestStateConflict は更新情報の取り込み状況をほかのサーバーが先に保存したエラー
+	ErrIngestStateConflict = errors.New("ingest state conflict")
+	// ErrInvalidCursor はページングのカーソルが不正なエラー
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrBacklogUnauthorized はBacklog APIがトークンを受け付けなかったエラー（失効・取り消し）
+	ErrBacklogUnauthorized = errors.New("backlog api: unauthorized")
+	// ErrBacklogForbidden はトークンは有効だが、対象のプロジェクトや課題を閲覧する権限がないエラー
+	ErrBacklogForbidden = errors.New("backlog api: forbidden")
+	// ErrBacklogRateLimited はBacklog APIのレート制限に達したエラー
+	ErrBacklogRateLimited = errors.New("backlog api: rate limited")
+	// ErrBacklogUnavailable はBacklog APIに接続できない、またはサーバーエラーを返したエラー
+	ErrBacklogUnavailable = errors.New("backlog api: upstream unavailable")
+)
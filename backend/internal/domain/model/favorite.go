@@ -6,16 +6,126 @@ import (
 
 // Favorite はユーザーのお気に入り情報を表すドメインモデル
 type Favorite struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"userId"`
-	ItemID    string    `json:"itemId"`
-	CreatedAt time.Time `json:"createdAt"`
+	ID        string            `json:"id"`
+	UserID    string            `json:"userId"`
+	ItemID    string            `json:"itemId"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Snapshot  *FavoriteSnapshot `json:"snapshot,omitempty"`
+
+	// CollectionIDは所属するコレクション（未分類の場合は空）、Tags・Noteはタグとメモ
+	// タグとメモは登録したユーザー本人だけのもので、共有コレクションに入れても他のメンバーには表示されない
+	CollectionID string   `json:"collectionId,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Note         string   `json:"note,omitempty"`
+}
+
+// FavoriteSnapshot はお気に入りに登録した更新情報の内容を表す
+// 更新情報が古くなったり削除されたりしても一覧に表示できるよう、登録時に保存し定期的に最新の課題の内容で更新する
+type FavoriteSnapshot struct {
+	ProjectID      string    `json:"projectId"`
+	ProjectKey     string    `json:"projectKey"`
+	ProjectName    string    `json:"projectName"`
+	TypeID         int       `json:"typeId"`
+	Type           string    `json:"type"`
+	IssueKey       string    `json:"issueKey,omitempty"`
+	IssueStatus    string    `json:"issueStatus,omitempty"`
+	ContentSummary string    `json:"contentSummary"`
+	CreatedUser    User      `json:"createdUser"`
+	Created        time.Time `json:"created"`
+	URL            string    `json:"url"`
+	RefreshedAt    time.Time `json:"refreshedAt"`
+}
+
+// NewFavoriteSnapshot は更新情報からお気に入りのスナップショットを作成
+func NewFavoriteSnapshot(item *BacklogItem, now time.Time) *FavoriteSnapshot {
+	return &FavoriteSnapshot{
+		ProjectID:      item.ProjectID,
+		ProjectKey:     item.ProjectKey,
+		ProjectName:    item.ProjectName,
+		TypeID:         item.TypeID,
+		Type:           item.Type,
+		IssueKey:       item.IssueKey,
+		ContentSummary: item.ContentSummary,
+		CreatedUser:    item.CreatedUser,
+		Created:        item.Created,
+		URL:            item.URL,
+		RefreshedAt:    now,
+	}
+}
+
+// ApplyIssue は課題の現在の状態をスナップショットに反映し、内容が変わったかを返す
+func (s *FavoriteSnapshot) ApplyIssue(issue *Issue, now time.Time) bool {
+	if s.ContentSummary == issue.Summary && s.IssueStatus == issue.Status {
+		return false
+	}
+
+	s.ContentSummary = issue.Summary
+	s.IssueStatus = issue.Status
+	s.RefreshedAt = now
+	return true
+}
+
+// Item はスナップショットから更新情報を復元
+func (s *FavoriteSnapshot) Item(itemID string) *BacklogItem {
+	return &BacklogItem{
+		ID:             itemID,
+		ProjectID:      s.ProjectID,
+		ProjectKey:     s.ProjectKey,
+		ProjectName:    s.ProjectName,
+		TypeID:         s.TypeID,
+		Type:           s.Type,
+		IssueKey:       s.IssueKey,
+		ContentSummary: s.ContentSummary,
+		CreatedUser:    s.CreatedUser,
+		Created:        s.Created,
+		URL:            s.URL,
+	}
+}
+
+// FavoritePage はページングされたお気に入りを表す
+// NextCursorが空の場合はそれより古いお気に入りが存在しない
+type FavoritePage struct {
+	Favorites  []*Favorite
+	NextCursor string
 }
 
 // FavoriteRepository はお気に入り情報の永続化を担当するリポジトリのインターフェース
 type FavoriteRepository interface {
+	// FindByUserID はユーザーのすべてのお気に入りを取得する
 	FindByUserID(userID string) ([]*Favorite, error)
+	// FindPageByUserID はユーザーのお気に入りを登録日時の新しい順にカーソル位置から1ページ分取得する
+	// カーソルが不正な場合はErrInvalidCursorを返す
+	FindPageByUserID(userID string, page PageRequest) (*FavoritePage, error)
+	// Save はお気に入りを保存する。同じ更新情報のお気に入りが既にある場合は上書きせずにErrFavoriteAlreadyExistsを返す
+	// 存在の確認と保存は1回の操作で行い、同時に保存しても1件だけが成功する
 	Save(favorite *Favorite) error
+	// Update はユーザーIDと更新情報IDが一致するお気に入りのスナップショットを更新する
+	Update(favorite *Favorite) error
+	// UpdateAnnotations はユーザーIDと更新情報IDが一致するお気に入りのコレクション・タグ・メモを更新する
+	// 一致するお気に入りがない場合はErrFavoriteNotFoundを返す
+	UpdateAnnotations(favorite *Favorite) error
+
+	// SaveCollection はコレクションを作成または更新する
+	SaveCollection(collection *Collection) error
+	// FindCollection はIDを指定してコレクションを取得する。存在しない場合はErrCollectionNotFoundを返す
+	FindCollection(collectionID string) (*Collection, error)
+	// FindCollectionsByUserID はユーザーが作成したコレクションを取得する
+	FindCollectionsByUserID(userID string) ([]*Collection, error)
+	// DeleteCollection はコレクションを削除する
+	DeleteCollection(collectionID string) error
+	// FindByCollectionID はコレクションに入っているすべてのメンバーのお気に入りを取得する
+	FindByCollectionID(collectionID string) ([]*Favorite, error)
+
+	// SaveCollectionMember はコレクションのメンバーを追加または権限を更新する
+	SaveCollectionMember(member *CollectionMember) error
+	// FindCollectionMembers はコレクションのメンバーを取得する（オーナーは含まない）
+	FindCollectionMembers(collectionID string) ([]*CollectionMember, error)
+	// FindCollectionsByMemberID はユーザーがメンバーとして共有されているコレクションを取得する
+	FindCollectionsByMemberID(userID string) ([]*Collection, error)
+	// DeleteCollectionMember はコレクションからメンバーを削除する
+	DeleteCollectionMember(collectionID string, userID string) error
+
+	// Delete はお気に入りを削除する。一致するお気に入りがない場合はErrFavoriteNotFoundを返す
 	Delete(userID string, itemID string) error
 	Exists(userID string, itemID string) (bool, error)
 }
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// EncodeOffsetCursor は並び順の決まった一覧での位置を不透明なカーソルに変換
+func EncodeOffsetCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+// IsOffsetCursor はカーソルがEncodeOffsetCursorで生成されたものか判定（空のカーソルはfalse）
+func IsOffsetCursor(cursor string) bool {
+	if cursor == "" {
+		return false
+	}
+	_, err := DecodeOffsetCursor(cursor)
+	return err == nil
+}
+
+// EncodeActivityCursor はBacklog APIで次のページの取得を開始する更新情報ID（maxId）を不透明なカーソルに変換
+func EncodeActivityCursor(maxID int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(maxID)))
+}
+
+// DecodeActivityCursor はカーソルを更新情報IDに変換。空のカーソルは0（最新から取得）を返す
+func DecodeActivityCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	maxID, err := strconv.Atoi(string(b))
+	if err != nil || maxID <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return maxID, nil
+}
+
+// DecodeOffsetCursor はカーソルを一覧での位置に変換。空のカーソルは先頭を表す
+func DecodeOffsetCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	value, ok := strings.CutPrefix(string(b), "offset:")
+	if !ok {
+		return 0, ErrInvalidCursor
+	}
+
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, ErrInvalidCursor
+	}
+	return offset, nil
+}
@@ -0,0 +1,17 @@
+package model
+
+import "slices"
+
+// PersonalFeed はユーザー自身の更新情報と、ユーザーが担当・ウォッチしている課題の更新情報をまとめたフィードの条件を表す
+type PersonalFeed struct {
+	UserID    string
+	IssueKeys []string // ユーザーが担当・ウォッチしている課題の課題キー
+}
+
+// Matches は更新情報がユーザー自身によるもの、またはユーザーが担当・ウォッチしている課題に関するものかを判定
+func (f PersonalFeed) Matches(item *BacklogItem) bool {
+	if item.CreatedUser.ID == f.UserID {
+		return true
+	}
+	return item.IssueKey != "" && slices.Contains(f.IssueKeys, item.IssueKey)
+}
@@ -0,0 +1,9 @@
+package model
+
+// Project はBacklogのプロジェクトを表すドメインモデル
+type Project struct {
+	ID         string `json:"id"`
+	ProjectKey string `json:"projectKey"`
+	Name       string `json:"name"`
+	Archived   bool   `json:"archived"`
+}
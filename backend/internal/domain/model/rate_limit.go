@@ -0,0 +1,34 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimit はBacklog APIのアクセストークンごとのレート制限の状態を表す
+// 直近のレスポンスのX-RateLimit-*ヘッダーの値で、UpdatedAtより後の呼び出しは反映されない
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RateLimitError はBacklog APIのレート制限に達したエラー
+// errors.IsでErrBacklogRateLimitedとして判定でき、errors.Asで制限が解除される日時を取り出せる
+type RateLimitError struct {
+	Reset time.Time // 制限が解除される日時（不明な場合はゼロ値）
+}
+
+// Error はエラーメッセージを返す
+func (e *RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return ErrBacklogRateLimited.Error()
+	}
+	return fmt.Sprintf("%s, reset at %s", ErrBacklogRateLimited, e.Reset.Format(time.RFC3339))
+}
+
+// Unwrap はErrBacklogRateLimitedを返す
+func (e *RateLimitError) Unwrap() error {
+	return ErrBacklogRateLimited
+}
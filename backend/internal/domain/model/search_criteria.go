@@ -0,0 +1,53 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// SearchCriteria はBacklog更新情報の検索条件を表す
+// 空のスライス・ゼロ値の日時はその条件で絞り込まないことを表す
+type SearchCriteria struct {
+	Keyword        string
+	ProjectIDs     []string
+	TypeIDs        []int
+	CreatedUserIDs []string
+	Since          time.Time // この日時以降に作成された更新情報（境界を含む）
+	Until          time.Time // この日時より前に作成された更新情報（境界を含まない）
+}
+
+// Matches は更新情報が検索条件をすべて満たすかを判定
+func (c SearchCriteria) Matches(item *BacklogItem) bool {
+	if len(c.ProjectIDs) > 0 && !slices.Contains(c.ProjectIDs, item.ProjectID) {
+		return false
+	}
+	if len(c.TypeIDs) > 0 && !slices.Contains(c.TypeIDs, item.TypeID) {
+		return false
+	}
+	if len(c.CreatedUserIDs) > 0 && !slices.Contains(c.CreatedUserIDs, item.CreatedUser.ID) {
+		return false
+	}
+	if !c.Since.IsZero() && item.Created.Before(c.Since) {
+		return false
+	}
+	if !c.Until.IsZero() && !item.Created.Before(c.Until) {
+		return false
+	}
+	return c.matchesKeyword(item)
+}
+
+// matchesKeyword はID・プロジェクト名・種別・概要・作成者名のいずれかにキーワードを含むかを大文字小文字を区別せずに判定
+func (c SearchCriteria) matchesKeyword(item *BacklogItem) bool {
+	if c.Keyword == "" {
+		return true
+	}
+
+	keyword := strings.ToLower(c.Keyword)
+	for _, field := range []string{item.ID, item.ProjectName, item.Type, item.ContentSummary, item.CreatedUser.Name} {
+		if strings.Contains(strings.ToLower(field), keyword) {
+			return true
+		}
+	}
+	return false
+}
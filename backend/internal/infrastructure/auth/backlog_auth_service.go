@@ -37,15 +37,15 @@ func NewBacklogAuthService(config model.OAuthConfig, spaceURL string) *BacklogAu
 	}
 }
 
-// GetAuthorizationURL は認可URLを取得
-func (s *BacklogAuthService) GetAuthorizationURL() string {
-	return s.oauthConfig.AuthCodeURL("state", oauth2.AccessTypeOffline)
+// GetAuthorizationURL はstateとPKCEのcode_challengeを含む認可URLを取得
+func (s *BacklogAuthService) GetAuthorizationURL(state string, codeVerifier string) string {
+	return s.oauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(codeVerifier))
 }
 
-// ExchangeCodeForToken は認可コードからトークンを取得
-func (s *BacklogAuthService) ExchangeCodeForToken(code string) (*model.AuthToken, error) {
+// ExchangeCodeForToken は認可コードとPKCEのcode_verifierからトークンを取得
+func (s *BacklogAuthService) ExchangeCodeForToken(code string, codeVerifier string) (*model.AuthToken, error) {
 	ctx := context.Background()
-	token, err := s.oauthConfig.Exchange(ctx, code)
+	token, err := s.oauthConfig.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange token: %w", err)
 	}
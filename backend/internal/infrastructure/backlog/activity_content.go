@@ -0,0 +1,210 @@
+package backlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// flexString は文字列・数値・nullのいずれでも受け取れる文字列
+// Backlog APIは変更前後の値やIDを項目によって文字列と数値のどちらでも返すため
+type flexString string
+
+// UnmarshalJSON は文字列はそのまま、それ以外の値はJSON表現を文字列として取り込む
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = ""
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = flexString(str)
+		return nil
+	}
+
+	*s = flexString(data)
+	return nil
+}
+
+// activityComment はアクティビティのコメント
+// 課題やプルリクエストではオブジェクト、プロジェクトへの参加・脱退では文字列で返される
+type activityComment struct {
+	ID      flexString `json:"id"`
+	Content string     `json:"content"`
+}
+
+// UnmarshalJSON は文字列のコメントを本文として取り込む
+func (c *activityComment) UnmarshalJSON(data []byte) error {
+	var content string
+	if err := json.Unmarshal(data, &content); err == nil {
+		c.Content = content
+		return nil
+	}
+
+	type plain activityComment
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// activityContent はBacklog APIのアクティビティのcontentを全種別分まとめて受け取る構造体
+// 種別によって使用する項目が異なり、使用しない項目は空のままとなる
+type activityContent struct {
+	ID          flexString      `json:"id"`
+	KeyID       int             `json:"key_id"`
+	Summary     string          `json:"summary"`
+	Description string          `json:"description"`
+	Comment     activityComment `json:"comment"`
+	Changes     []struct {
+		Field    string     `json:"field"`
+		OldValue flexString `json:"old_value"`
+		NewValue flexString `json:"new_value"`
+	} `json:"changes"`
+	Link []struct {
+		KeyID int `json:"key_id"`
+	} `json:"link"`
+
+	// Wiki・共有ファイル・マイルストーン
+	Name          string `json:"name"`
+	Diff          string `json:"diff"`
+	Version       int    `json:"version"`
+	Dir           string `json:"dir"`
+	Size          int64  `json:"size"`
+	StartDate     string `json:"start_date"`
+	ReferenceDate string `json:"reference_date"`
+
+	// Git・プルリクエスト
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+	Ref           string `json:"ref"`
+	ChangeType    string `json:"change_type"`
+	RevisionCount int    `json:"revision_count"`
+	Revisions     []struct {
+		Rev     string `json:"rev"`
+		Comment string `json:"comment"`
+	} `json:"revisions"`
+	Number int `json:"number"`
+	Issue  struct {
+		KeyID int `json:"key_id"`
+	} `json:"issue"`
+
+	// プロジェクトへの参加・脱退
+	Users []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"users"`
+	Groups []struct {
+		Name string `json:"name"`
+	} `json:"groups"`
+}
+
+// typed はcontentを種別に対応する内容に変換（内容を持たない種別の場合はnilを返す）
+// Subversionコミットは課題やリポジトリとの関連を持たないため変換しない
+func (c *activityContent) typed(typeID int, projectKey string) *model.ActivityContent {
+	switch typeID {
+	case 1, 2, 4, 14:
+		content := &model.IssueContent{
+			IssueKey:    issueKey(projectKey, c.KeyID),
+			Summary:     c.Summary,
+			Description: c.Description,
+			Comment:     c.Comment.Content,
+			Changes:     c.changes(),
+		}
+		for _, link := range c.Link {
+			if key := issueKey(projectKey, link.KeyID); key != "" {
+				content.LinkedIssueKeys = append(content.LinkedIssueKeys, key)
+			}
+		}
+		return &model.ActivityContent{Issue: content}
+	case 3, 17:
+		return &model.ActivityContent{Comment: &model.CommentContent{
+			IssueKey:  issueKey(projectKey, c.KeyID),
+			Summary:   c.Summary,
+			CommentID: string(c.Comment.ID),
+			Body:      c.Comment.Content,
+			Changes:   c.changes(),
+		}}
+	case 5, 6, 7:
+		return &model.ActivityContent{Wiki: &model.WikiContent{
+			WikiID:  string(c.ID),
+			Name:    c.Name,
+			Diff:    c.Diff,
+			Version: c.Version,
+		}}
+	case 8, 9, 10:
+		return &model.ActivityContent{File: &model.FileContent{
+			FileID: string(c.ID),
+			Dir:    c.Dir,
+			Name:   c.Name,
+			Size:   c.Size,
+		}}
+	case 12, 13:
+		content := &model.GitPushContent{
+			Repository:    c.Repository.Name,
+			Ref:           c.Ref,
+			ChangeType:    c.ChangeType,
+			RevisionCount: c.RevisionCount,
+		}
+		for _, revision := range c.Revisions {
+			content.Revisions = append(content.Revisions, model.GitRevision{Rev: revision.Rev, Comment: revision.Comment})
+		}
+		return &model.ActivityContent{GitPush: content}
+	case 18, 19, 20, 21:
+		return &model.ActivityContent{PullRequest: &model.PullRequestContent{
+			Repository:  c.Repository.Name,
+			Number:      c.Number,
+			Summary:     c.Summary,
+			Description: c.Description,
+			Comment:     c.Comment.Content,
+			IssueKey:    issueKey(projectKey, c.Issue.KeyID),
+			Changes:     c.changes(),
+		}}
+	case 22, 23, 24:
+		return &model.ActivityContent{Milestone: &model.MilestoneContent{
+			MilestoneID:   string(c.ID),
+			Name:          c.Name,
+			Description:   c.Description,
+			StartDate:     c.StartDate,
+			ReferenceDate: c.ReferenceDate,
+			Changes:       c.changes(),
+		}}
+	case 15, 16, 25, 26:
+		content := &model.MembershipContent{Comment: c.Comment.Content}
+		for _, user := range c.Users {
+			content.Users = append(content.Users, model.User{ID: strconv.Itoa(user.ID), Name: user.Name})
+		}
+		for _, group := range c.Groups {
+			content.Groups = append(content.Groups, group.Name)
+		}
+		return &model.ActivityContent{Membership: content}
+	default:
+		return nil
+	}
+}
+
+// changes は変更された項目のリストをドメインモデルに変換
+func (c *activityContent) changes() []model.ActivityChange {
+	if len(c.Changes) == 0 {
+		return nil
+	}
+
+	changes := make([]model.ActivityChange, len(c.Changes))
+	for i, change := range c.Changes {
+		changes[i] = model.ActivityChange{
+			Field:    change.Field,
+			OldValue: string(change.OldValue),
+			NewValue: string(change.NewValue),
+		}
+	}
+	return changes
+}
+
+// issueKey はプロジェクトキーと課題の番号から課題キーを組み立てる（どちらかがない場合は空文字列）
+func issueKey(projectKey string, keyID int) string {
+	if projectKey == "" || keyID <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d", projectKey, keyID)
+}
@@ -2,10 +2,13 @@ package backlog
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +21,8 @@ type BacklogClient struct {
 	clientID     string
 	clientSecret string
 	httpClient   *http.Client
+	rateLimits   *rateLimitTracker
+	retry        retryPolicy
 }
 
 // NewBacklogClient はBacklogClientのインスタンスを生成
@@ -29,37 +34,61 @@ func NewBacklogClient(spaceURL, clientID, clientSecret string) *BacklogClient {
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
+		rateLimits: newRateLimitTracker(),
+		retry:      defaultRetryPolicy(),
 	}
 }
 
+// ActivityQuery はアクティビティ取得APIのクエリパラメータ
+// 0または空の項目は送信せず、Backlog APIの既定値を使用する
+type ActivityQuery struct {
+	ProjectID       string // 指定した場合はプロジェクトの最近の更新APIを呼び出す
+	UserID          string // 指定した場合はユーザーの最近の活動APIを呼び出す（ProjectIDより優先）
+	ActivityTypeIDs []int
+	MinID           int
+	MaxID           int
+	Count           int
+	Order           string // "asc" または "desc"
+}
+
 // GetActivities はBacklogのアクティビティ（更新情報）を取得
-func (c *BacklogClient) GetActivities(token string, count int) ([]*model.BacklogItem, error) {
+func (c *BacklogClient) GetActivities(token string, query ActivityQuery) ([]*model.BacklogItem, error) {
 	apiURL := fmt.Sprintf("%s/api/v2/space/activities", c.spaceURL)
+	switch {
+	case query.UserID != "":
+		apiURL = fmt.Sprintf("%s/api/v2/users/%s/activities", c.spaceURL, url.PathEscape(query.UserID))
+	case query.ProjectID != "":
+		apiURL = fmt.Sprintf("%s/api/v2/projects/%s/activities", c.spaceURL, url.PathEscape(query.ProjectID))
+	}
 
 	// クエリパラメータの設定
 	params := url.Values{}
-	params.Add("count", fmt.Sprintf("%d", count))
-
-	// リクエスト作成
-	req, err := http.NewRequest("GET", apiURL+"?"+params.Encode(), nil)
-	if err != nil {
-		return nil, err
+	for _, typeID := range query.ActivityTypeIDs {
+		params.Add("activityTypeId[]", strconv.Itoa(typeID))
+	}
+	if query.MinID > 0 {
+		params.Add("minId", strconv.Itoa(query.MinID))
+	}
+	if query.MaxID > 0 {
+		params.Add("maxId", strconv.Itoa(query.MaxID))
+	}
+	if query.Count > 0 {
+		params.Add("count", strconv.Itoa(query.Count))
+	}
+	if query.Order != "" {
+		params.Add("order", query.Order)
 	}
 
-	// 認証ヘッダーの設定
-	req.Header.Add("Authorization", "Bearer "+token)
-
-	// リクエスト実行
-	resp, err := c.httpClient.Do(req)
+	// リクエスト実行（一時的な失敗は再試行する）
+	resp, err := c.get(token, apiURL+"?"+params.Encode())
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// レスポンスのステータスコードチェック
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get activities, status: %d, response: %s", resp.StatusCode, string(body))
+	if err := checkResponse(resp, "failed to get activities"); err != nil {
+		return nil, err
 	}
 
 	// レスポンスボディの読み込み
@@ -74,13 +103,12 @@ func (c *BacklogClient) GetActivities(token string, count int) ([]*model.Backlog
 	var activities []struct {
 		ID      int `json:"id"`
 		Project struct {
-			ID   int    `json:"id"`
-			Name string `json:"name"`
+			ID         int    `json:"id"`
+			ProjectKey string `json:"projectKey"`
+			Name       string `json:"name"`
 		} `json:"project"`
-		Type    int `json:"type"`
-		Content struct {
-			Summary string `json:"summary"`
-		} `json:"content"`
+		Type        int             `json:"type"`
+		Content     json.RawMessage `json:"content"`
 		CreatedUser struct {
 			ID          int    `json:"id"`
 			Name        string `json:"name"`
@@ -103,12 +131,19 @@ func (c *BacklogClient) GetActivities(token string, count int) ([]*model.Backlog
 		// typeの値を文字列に変換
 		typeStr := convertTypeToString(activity.Type)
 
+		// 想定外の形式のcontentは読み取れた項目だけを使用し、更新情報自体は返す
+		var content activityContent
+		contentErr := json.Unmarshal(activity.Content, &content)
+
 		item := &model.BacklogItem{
 			ID:             fmt.Sprintf("%d", activity.ID),
 			ProjectID:      fmt.Sprintf("%d", activity.Project.ID),
+			ProjectKey:     activity.Project.ProjectKey,
 			ProjectName:    activity.Project.Name,
+			TypeID:         activity.Type,
+			IssueKey:       issueKey(activity.Project.ProjectKey, content.KeyID),
 			Type:           typeStr,
-			ContentSummary: activity.Content.Summary,
+			ContentSummary: content.Summary,
 			CreatedUser: model.User{
 				ID:          fmt.Sprintf("%d", activity.CreatedUser.ID),
 				Name:        activity.CreatedUser.Name,
@@ -118,6 +153,10 @@ func (c *BacklogClient) GetActivities(token string, count int) ([]*model.Backlog
 			},
 			Created: createdTime,
 		}
+		if contentErr == nil {
+			item.Content = content.typed(activity.Type, activity.Project.ProjectKey)
+		}
+		item.URL = c.itemURL(item)
 
 		items = append(items, item)
 	}
@@ -125,32 +164,383 @@ func (c *BacklogClient) GetActivities(token string, count int) ([]*model.Backlog
 	return items, nil
 }
 
-// SearchActivities はキーワードでアクティビティを検索
-func (c *BacklogClient) SearchActivities(token, keyword string, count int) ([]*model.BacklogItem, error) {
-	// 全てのアクティビティを取得
-	activities, err := c.GetActivities(token, count)
+// itemURL は更新情報に対応するBacklogの画面のURLを組み立てる
+// 種別ごとの内容から課題・コメント・Wiki・共有ファイル・Gitリポジトリ・プルリクエスト・マイルストーンの画面を返し、
+// 対応する画面がない種別はプロジェクトのホーム画面を返す
+func (c *BacklogClient) itemURL(item *model.BacklogItem) string {
+	if path := contentPath(item.ProjectKey, item.Content); path != "" {
+		return c.spaceURL + path
+	}
+
+	switch {
+	case item.IssueKey != "":
+		return fmt.Sprintf("%s/view/%s", c.spaceURL, url.PathEscape(item.IssueKey))
+	case item.ProjectKey != "":
+		return fmt.Sprintf("%s/projects/%s", c.spaceURL, url.PathEscape(item.ProjectKey))
+	default:
+		return fmt.Sprintf("%s/dashboard", c.spaceURL)
+	}
+}
+
+// contentPath は種別ごとの内容に対応する画面のパスを返す（画面を特定できない場合は空文字列）
+func contentPath(projectKey string, content *model.ActivityContent) string {
+	switch {
+	case content == nil:
+		return ""
+	case content.Issue != nil && content.Issue.IssueKey != "":
+		return "/view/" + url.PathEscape(content.Issue.IssueKey)
+	case content.Comment != nil && content.Comment.IssueKey != "":
+		link := "/view/" + url.PathEscape(content.Comment.IssueKey)
+		if content.Comment.CommentID != "" {
+			link += "#comment-" + url.PathEscape(content.Comment.CommentID)
+		}
+		return link
+	case content.Wiki != nil && content.Wiki.WikiID != "":
+		return "/alias/wiki/" + url.PathEscape(content.Wiki.WikiID)
+	case projectKey == "":
+		// 以降の画面はプロジェクトキーを含むパスで表される
+		return ""
+	case content.File != nil && content.File.Name != "":
+		return "/file/" + url.PathEscape(projectKey) + escapePath(path.Join("/", content.File.Dir, content.File.Name))
+	case content.PullRequest != nil && content.PullRequest.Repository != "" && content.PullRequest.Number > 0:
+		return fmt.Sprintf("/git/%s/%s/pullRequests/%d", url.PathEscape(projectKey), url.PathEscape(content.PullRequest.Repository), content.PullRequest.Number)
+	case content.GitPush != nil && content.GitPush.Repository != "":
+		link := fmt.Sprintf("/git/%s/%s", url.PathEscape(projectKey), url.PathEscape(content.GitPush.Repository))
+		// ブランチへのプッシュはブランチのファイル一覧を表示する（削除されたブランチはリポジトリの画面）
+		if branch, ok := strings.CutPrefix(content.GitPush.Ref, "refs/heads/"); ok && content.GitPush.ChangeType != "delete" {
+			link += "/tree/" + escapePath(branch)
+		}
+		return link
+	case content.Milestone != nil && content.Milestone.MilestoneID != "":
+		return fmt.Sprintf("/find/%s?milestoneId=%s", url.PathEscape(projectKey), url.QueryEscape(content.Milestone.MilestoneID))
+	default:
+		return ""
+	}
+}
+
+// escapePath はスラッシュ区切りのパスを要素ごとにエスケープする
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// GetIssue は課題キーを指定して課題の現在の状態を取得
+func (c *BacklogClient) GetIssue(token, issueKey string) (*model.Issue, error) {
+	apiURL := fmt.Sprintf("%s/api/v2/issues/%s", c.spaceURL, url.PathEscape(issueKey))
+
+	resp, err := c.get(token, apiURL)
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	if keyword == "" {
-		return activities, nil
+	// 削除された課題は404、閲覧できないプロジェクトの課題は403を返す
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, model.ErrItemNotFound
+	}
+	if err := checkResponse(resp, "failed to get issue"); err != nil {
+		return nil, err
+	}
+
+	var issue backlogIssue
+	if err := json.NewDecoder(resp.Body).Decode(&issue); err != nil {
+		return nil, err
+	}
+	return issue.toModel(), nil
+}
+
+// backlogIssue はBacklog APIの課題のレスポンス
+type backlogIssue struct {
+	IssueKey string `json:"issueKey"`
+	Summary  string `json:"summary"`
+	Status   struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	Updated string `json:"updated"`
+}
+
+// toModel は課題のレスポンスをドメインモデルに変換
+func (i backlogIssue) toModel() *model.Issue {
+	updated, _ := time.Parse(time.RFC3339, i.Updated)
+	return &model.Issue{
+		IssueKey: i.IssueKey,
+		Summary:  i.Summary,
+		Status:   i.Status.Name,
+		Updated:  updated,
+	}
+}
+
+// issuesPerRequest は担当・ウォッチしている課題を取得する件数（APIの上限）
+const issuesPerRequest = 100
+
+// GetAssignedIssues はユーザーが担当している課題を更新日時の新しい順に最大issuesPerRequest件取得
+func (c *BacklogClient) GetAssignedIssues(token, userID string) ([]*model.Issue, error) {
+	params := url.Values{}
+	params.Add("assigneeId[]", userID)
+	params.Add("sort", "updated")
+	params.Add("order", "desc")
+	params.Add("count", strconv.Itoa(issuesPerRequest))
+
+	resp, err := c.get(token, fmt.Sprintf("%s/api/v2/issues?%s", c.spaceURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, "failed to get assigned issues"); err != nil {
+		return nil, err
+	}
+
+	var issues []backlogIssue
+	if err := json.NewDecoder(resp.Body).Decode(&issues); err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Issue, len(issues))
+	for i, issue := range issues {
+		result[i] = issue.toModel()
+	}
+	return result, nil
+}
+
+// GetWatchedIssues はユーザーがウォッチしている課題を課題の更新日時の新しい順に最大issuesPerRequest件取得
+func (c *BacklogClient) GetWatchedIssues(token, userID string) ([]*model.Issue, error) {
+	params := url.Values{}
+	params.Add("sort", "issueUpdated")
+	params.Add("order", "desc")
+	params.Add("count", strconv.Itoa(issuesPerRequest))
+
+	apiURL := fmt.Sprintf("%s/api/v2/users/%s/watchings?%s", c.spaceURL, url.PathEscape(userID), params.Encode())
+	resp, err := c.get(token, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, "failed to get watched issues"); err != nil {
+		return nil, err
+	}
+
+	var watchings []struct {
+		Issue backlogIssue `json:"issue"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&watchings); err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Issue, len(watchings))
+	for i, watching := range watchings {
+		result[i] = watching.Issue.toModel()
+	}
+	return result, nil
+}
+
+// backlogProject はBacklog APIのプロジェクトのレスポンス
+type backlogProject struct {
+	ID         int    `json:"id"`
+	ProjectKey string `json:"projectKey"`
+	Name       string `json:"name"`
+	Archived   bool   `json:"archived"`
+}
+
+// toModel はプロジェクトのレスポンスをドメインモデルに変換
+func (p backlogProject) toModel() *model.Project {
+	return &model.Project{
+		ID:         strconv.Itoa(p.ID),
+		ProjectKey: p.ProjectKey,
+		Name:       p.Name,
+		Archived:   p.Archived,
+	}
+}
+
+// GetProjects は呼び出し元ユーザーが参加しているプロジェクトの一覧を取得
+func (c *BacklogClient) GetProjects(token string) ([]*model.Project, error) {
+	resp, err := c.get(token, fmt.Sprintf("%s/api/v2/projects", c.spaceURL))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, "failed to get projects"); err != nil {
+		return nil, err
+	}
+
+	var projects []backlogProject
+	if err := json.NewDecoder(resp.Body).Decode(&projects); err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.Project, len(projects))
+	for i, project := range projects {
+		result[i] = project.toModel()
+	}
+	return result, nil
+}
+
+// GetProject はプロジェクトIDまたはプロジェクトキーを指定してプロジェクトを取得
+func (c *BacklogClient) GetProject(token, projectIDOrKey string) (*model.Project, error) {
+	apiURL := fmt.Sprintf("%s/api/v2/projects/%s", c.spaceURL, url.PathEscape(projectIDOrKey))
+
+	resp, err := c.get(token, apiURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 存在しないプロジェクトと参加していないプロジェクトは404、閲覧権限のないプロジェクトは403を返す
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, model.ErrProjectNotFound
+	}
+	if err := checkResponse(resp, "failed to get project"); err != nil {
+		return nil, err
+	}
+
+	var project backlogProject
+	if err := json.NewDecoder(resp.Body).Decode(&project); err != nil {
+		return nil, err
+	}
+	return project.toModel(), nil
+}
+
+// GetActivity はIDを指定してアクティビティを取得
+// Backlog APIにはアクティビティを1件取得するAPIがないため、minId・maxIdで対象のIDだけを含む範囲を指定して取得する
+func (c *BacklogClient) GetActivity(token string, id int) (*model.BacklogItem, error) {
+	activities, err := c.GetActivities(token, ActivityQuery{
+		MinID: id - 1,
+		MaxID: id + 1,
+		Count: activitiesPerRequest,
+	})
+	if errors.Is(err, model.ErrBacklogForbidden) {
+		return nil, model.ErrItemNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	var filtered []*model.BacklogItem
-	// キーワードでフィルタリング
 	for _, activity := range activities {
-		if strings.Contains(activity.ID, keyword) ||
-			strings.Contains(activity.ProjectName, keyword) ||
-			strings.Contains(activity.Type, keyword) ||
-			strings.Contains(activity.ContentSummary, keyword) ||
-			strings.Contains(activity.CreatedUser.Name, keyword) {
+		if activity.ID == strconv.Itoa(id) {
+			return activity, nil
+		}
+	}
+
+	return nil, model.ErrItemNotFound
+}
+
+// SearchActivities は検索条件でアクティビティを検索し、新しい順に1ページ分を返す
+// 種別と単一のプロジェクトはBacklog APIのパラメータで絞り込み、それ以外の条件は取得後に絞り込む
+// 条件に一致する更新情報がlimit件に達するか、maxRequestsPerPage回APIを呼び出すまで過去へ遡る
+func (c *BacklogClient) SearchActivities(token string, criteria model.SearchCriteria, page model.PageRequest) (*model.BacklogItemPage, error) {
+	query := ActivityQuery{ActivityTypeIDs: criteria.TypeIDs}
+	if len(criteria.ProjectIDs) == 1 {
+		query.ProjectID = criteria.ProjectIDs[0]
+	}
+
+	return c.scanActivities(token, query, page, criteria.Since, criteria.Matches)
+}
+
+// SearchUserActivities はユーザーの最近の活動APIでユーザーが作成したアクティビティを新しい順に1ページ分返す
+func (c *BacklogClient) SearchUserActivities(token, userID string, page model.PageRequest) (*model.BacklogItemPage, error) {
+	return c.scanActivities(token, ActivityQuery{UserID: userID}, page, time.Time{}, func(*model.BacklogItem) bool { return true })
+}
+
+// SearchPersonalActivities はスペースのアクティビティからユーザー自身と担当・ウォッチしている課題に関するものを新しい順に1ページ分返す
+// Backlog APIには複数の課題のアクティビティをまとめて取得するAPIがないため、取得後に絞り込む
+func (c *BacklogClient) SearchPersonalActivities(token string, feed model.PersonalFeed, page model.PageRequest) (*model.BacklogItemPage, error) {
+	return c.scanActivities(token, ActivityQuery{}, page, time.Time{}, feed.Matches)
+}
+
+// scanActivities はカーソル位置から過去に遡ってアクティビティを取得し、matchに一致するものを新しい順に1ページ分返す
+// 一致する更新情報がlimit件に達するか、sinceより前に達するか、maxRequestsPerPage回APIを呼び出すまで遡る
+func (c *BacklogClient) scanActivities(token string, query ActivityQuery, page model.PageRequest, since time.Time, match func(*model.BacklogItem) bool) (*model.BacklogItemPage, error) {
+	maxID, err := model.DecodeActivityCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Size()
+
+	query.Count = activitiesPerRequest
+	query.Order = "desc"
+
+	items := make([]*model.BacklogItem, 0, limit)
+	for request := 0; request < maxRequestsPerPage; request++ {
+		query.MaxID = maxID
+		activities, err := c.GetActivities(token, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, activity := range activities {
+			id, err := strconv.Atoi(activity.ID)
+			if err != nil {
+				continue
+			}
+			// maxIdと同じIDの更新情報は前のページで返却済み
+			if maxID > 0 && id >= maxID {
+				continue
+			}
+			maxID = id
+
+			// 新しい順に取得しているため、期間の開始より前に達したらそれ以上遡らない
+			if !since.IsZero() && activity.Created.Before(since) {
+				return &model.BacklogItemPage{Items: items}, nil
+			}
+
+			if !match(activity) {
+				continue
+			}
+			items = append(items, activity)
+			if len(items) == limit {
+				return &model.BacklogItemPage{Items: items, NextCursor: model.EncodeActivityCursor(id)}, nil
+			}
+		}
 
-			filtered = append(filtered, activity)
+		// 取得件数が要求件数に満たない場合は最も古い更新情報まで到達している
+		if len(activities) < activitiesPerRequest {
+			return &model.BacklogItemPage{Items: items}, nil
 		}
 	}
 
-	return filtered, nil
+	return &model.BacklogItemPage{Items: items, NextCursor: model.EncodeActivityCursor(maxID)}, nil
+}
+
+// filterItems は検索条件に一致する更新情報を抽出
+func filterItems(items []*model.BacklogItem, criteria model.SearchCriteria) []*model.BacklogItem {
+	var filtered []*model.BacklogItem
+	for _, item := range items {
+		if criteria.Matches(item) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered
+}
+
+// checkResponse はBacklog APIのステータスコードを種別ごとのエラーに変換
+func checkResponse(resp *http.Response, message string) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var kind error
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		kind = model.ErrBacklogUnauthorized
+	case resp.StatusCode == http.StatusForbidden:
+		kind = model.ErrBacklogForbidden
+	case resp.StatusCode == http.StatusTooManyRequests:
+		kind = &model.RateLimitError{Reset: rateLimitReset(resp.Header)}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		kind = model.ErrBacklogUnavailable
+	default:
+		return fmt.Errorf("%s, status: %d, response: %s", message, resp.StatusCode, string(body))
+	}
+
+	return fmt.Errorf("%w: %s, status: %d, response: %s", kind, message, resp.StatusCode, string(body))
 }
 
 // convertTypeToString はBacklog APIのtype値を文字列に変換
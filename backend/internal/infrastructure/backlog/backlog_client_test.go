@@ -0,0 +1,423 @@
+package backlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// newActivityServer はIDが1からtotalまでのアクティビティを新しい順に返すテスト用Backlog APIを起動
+// IDがnのアクティビティは基準日時のn時間後に作成され、作成者IDはnを3で割った余りとする
+func newActivityServer(t *testing.T, total int) (*httptest.Server, *[]*http.Request) {
+	t.Helper()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+
+		maxID := total + 1
+		if v := r.URL.Query().Get("maxId"); v != "" {
+			maxID, _ = strconv.Atoi(v)
+		}
+		minID, _ := strconv.Atoi(r.URL.Query().Get("minId"))
+		count, _ := strconv.Atoi(r.URL.Query().Get("count"))
+
+		activities := []map[string]interface{}{}
+		for id := min(maxID-1, total); id > minID && id >= 1 && len(activities) < count; id-- {
+			summary := "通常の更新"
+			if id%10 == 0 {
+				summary = "リリース作業"
+			}
+			activities = append(activities, map[string]interface{}{
+				"id":          id,
+				"project":     map[string]interface{}{"id": 10},
+				"type":        1,
+				"content":     map[string]interface{}{"summary": summary},
+				"createdUser": map[string]interface{}{"id": id % 3},
+				"created":     base.Add(time.Duration(id) * time.Hour).Format(time.RFC3339),
+			})
+		}
+		json.NewEncoder(w).Encode(activities)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+// カーソルを辿ると重複・欠落なく全ての更新情報を取得できることを確認する
+func TestBacklogClient_SearchActivitiesPaging(t *testing.T) {
+	server, _ := newActivityServer(t, 250)
+	client := NewBacklogClient(server.URL, "", "")
+
+	seen := make(map[string]bool)
+	page := model.PageRequest{Limit: 30}
+	for {
+		result, err := client.SearchActivities("token", model.SearchCriteria{}, page)
+		if err != nil {
+			t.Fatalf("Failed to search activities: %v", err)
+		}
+		for _, item := range result.Items {
+			if seen[item.ID] {
+				t.Fatalf("Duplicated item %s", item.ID)
+			}
+			seen[item.ID] = true
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		page.Cursor = result.NextCursor
+	}
+
+	if len(seen) != 250 {
+		t.Errorf("Expected 250 items, got %d", len(seen))
+	}
+}
+
+// キーワード検索では一致する更新情報が揃うまで過去に遡ることを確認する
+func TestBacklogClient_SearchActivitiesKeyword(t *testing.T) {
+	server, requests := newActivityServer(t, 1000)
+	client := NewBacklogClient(server.URL, "", "")
+
+	result, err := client.SearchActivities("token", model.SearchCriteria{Keyword: "リリース"}, model.PageRequest{Limit: 15})
+	if err != nil {
+		t.Fatalf("Failed to search activities: %v", err)
+	}
+
+	if len(result.Items) != 15 {
+		t.Fatalf("Expected 15 items, got %d", len(result.Items))
+	}
+	if result.Items[14].ID != "860" {
+		t.Errorf("Expected last item 860, got %s", result.Items[14].ID)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("Expected 2 requests, got %d", len(*requests))
+	}
+
+	// 上限回数まで遡っても揃わない場合は途中までの結果とカーソルを返す
+	result, err = client.SearchActivities("token", model.SearchCriteria{Keyword: "存在しない"}, model.PageRequest{Cursor: result.NextCursor})
+	if err != nil {
+		t.Fatalf("Failed to search activities: %v", err)
+	}
+	if len(result.Items) != 0 || result.NextCursor != model.EncodeActivityCursor(360) {
+		t.Errorf("Expected empty page with cursor at 360, got %d items and %q", len(result.Items), result.NextCursor)
+	}
+}
+
+// 種別とプロジェクトはAPIのパラメータで絞り込み、期間の開始に達したら遡るのをやめることを確認する
+func TestBacklogClient_SearchActivitiesCriteria(t *testing.T) {
+	server, requests := newActivityServer(t, 1000)
+	client := NewBacklogClient(server.URL, "", "")
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	criteria := model.SearchCriteria{
+		ProjectIDs:     []string{"10"},
+		TypeIDs:        []int{1, 2},
+		CreatedUserIDs: []string{"0"},
+		Since:          base.Add(750 * time.Hour),
+		Until:          base.Add(980 * time.Hour),
+	}
+
+	result, err := client.SearchActivities("token", criteria, model.PageRequest{Limit: 100})
+	if err != nil {
+		t.Fatalf("Failed to search activities: %v", err)
+	}
+
+	// ID 750〜979のうち3の倍数
+	if len(result.Items) != 77 {
+		t.Errorf("Expected 77 items, got %d", len(result.Items))
+	}
+	if result.NextCursor != "" {
+		t.Errorf("Expected no next cursor, got %q", result.NextCursor)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("Expected 3 requests, got %d", len(*requests))
+	}
+
+	req := (*requests)[0]
+	if req.URL.Path != "/api/v2/projects/10/activities" {
+		t.Errorf("Expected project activities API, got %s", req.URL.Path)
+	}
+	if types := req.URL.Query()["activityTypeId[]"]; len(types) != 2 || types[0] != "1" || types[1] != "2" {
+		t.Errorf("Expected activityTypeId[] to be pushed down, got %v", types)
+	}
+}
+
+func TestBacklogClient_GetActivity(t *testing.T) {
+	server, requests := newActivityServer(t, 1000)
+	client := NewBacklogClient(server.URL, "", "")
+
+	item, err := client.GetActivity("token", 42)
+	if err != nil {
+		t.Fatalf("Failed to get activity: %v", err)
+	}
+	if item.ID != "42" {
+		t.Errorf("Expected activity 42, got %s", item.ID)
+	}
+
+	query := (*requests)[0].URL.Query()
+	if query.Get("minId") != "41" || query.Get("maxId") != "43" {
+		t.Errorf("Expected window around 42, got minId=%s maxId=%s", query.Get("minId"), query.Get("maxId"))
+	}
+
+	if _, err := client.GetActivity("token", 2000); err != model.ErrItemNotFound {
+		t.Errorf("Expected ErrItemNotFound, got %v", err)
+	}
+}
+
+func TestBacklogClient_InvalidCursor(t *testing.T) {
+	client := NewBacklogClient("http://localhost", "", "")
+
+	for _, cursor := range []string{"!!!", model.EncodeActivityCursor(0), fmt.Sprintf("%x", "abc")} {
+		if _, err := client.SearchActivities("token", model.SearchCriteria{}, model.PageRequest{Cursor: cursor}); err != model.ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor for %q, got %v", cursor, err)
+		}
+	}
+}
+
+// アクティビティのcontentを種別ごとの内容に変換することを確認する
+func TestBacklogClient_GetActivitiesContent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id": 8, "project": {"id": 10, "projectKey": "PROJ"}, "type": 15,
+			 "content": {"users": [{"id": 5, "name": "佐藤花子"}], "comment": "ようこそ"}},
+			{"id": 7, "project": {"id": 10, "projectKey": "PROJ"}, "type": 11,
+			 "content": {"rev": 100, "comment": "fix"}},
+			{"id": 6, "project": {"id": 10, "projectKey": "PROJ"}, "type": 18,
+			 "content": {"id": 3, "number": 12, "summary": "ログイン修正", "repository": {"id": 1, "name": "app"}, "issue": {"id": 1, "key_id": 4}}},
+			{"id": 5, "project": {"id": 10, "projectKey": "PROJ"}, "type": 12,
+			 "content": {"repository": {"id": 1, "name": "app"}, "ref": "refs/heads/main", "change_type": "update", "revision_count": 2,
+			             "revisions": [{"rev": "abc", "comment": "first"}, {"rev": "def", "comment": "second"}]}},
+			{"id": 4, "project": {"id": 10, "projectKey": "PROJ"}, "type": 8,
+			 "content": {"id": 9, "dir": "/docs/", "name": "spec.pdf", "size": 3000000000}},
+			{"id": 3, "project": {"id": 10, "projectKey": "PROJ"}, "type": 6,
+			 "content": {"id": 2, "name": "Home", "diff": "+追記", "version": 3}},
+			{"id": 2, "project": {"id": 10, "projectKey": "PROJ"}, "type": 3,
+			 "content": {"id": 1, "key_id": 4, "summary": "ログイン機能", "comment": {"id": 77, "content": "確認しました"}}},
+			{"id": 1, "project": {"id": 10, "projectKey": "PROJ"}, "type": 2,
+			 "content": {"id": 1, "key_id": 4, "summary": "ログイン機能",
+			             "changes": [{"field": "status", "old_value": "1", "new_value": "2", "type": "standard"}, {"field": "assigner", "old_value": null, "new_value": 5}]}}
+		]`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewBacklogClient(server.URL, "", "")
+
+	items, err := client.GetActivities("token", ActivityQuery{})
+	if err != nil {
+		t.Fatalf("Failed to get activities: %v", err)
+	}
+	if len(items) != 8 {
+		t.Fatalf("Expected 8 items, got %d", len(items))
+	}
+
+	membership := items[0].Content.Membership
+	if membership == nil || len(membership.Users) != 1 || membership.Users[0].ID != "5" || membership.Comment != "ようこそ" {
+		t.Errorf("Unexpected membership content: %+v", membership)
+	}
+	if items[1].Content != nil {
+		t.Errorf("Expected no content for subversion commit, got %+v", items[1].Content)
+	}
+	pullRequest := items[2].Content.PullRequest
+	if pullRequest == nil || pullRequest.Number != 12 || pullRequest.Repository != "app" || pullRequest.IssueKey != "PROJ-4" {
+		t.Errorf("Unexpected pull request content: %+v", pullRequest)
+	}
+	gitPush := items[3].Content.GitPush
+	if gitPush == nil || gitPush.Ref != "refs/heads/main" || gitPush.RevisionCount != 2 || len(gitPush.Revisions) != 2 || gitPush.Revisions[1].Rev != "def" {
+		t.Errorf("Unexpected git push content: %+v", gitPush)
+	}
+	file := items[4].Content.File
+	if file == nil || file.FileID != "9" || file.Name != "spec.pdf" || file.Size != 3000000000 {
+		t.Errorf("Unexpected file content: %+v", file)
+	}
+	wiki := items[5].Content.Wiki
+	if wiki == nil || wiki.WikiID != "2" || wiki.Name != "Home" || wiki.Version != 3 {
+		t.Errorf("Unexpected wiki content: %+v", wiki)
+	}
+	comment := items[6].Content.Comment
+	if comment == nil || comment.IssueKey != "PROJ-4" || comment.CommentID != "77" || comment.Body != "確認しました" {
+		t.Errorf("Unexpected comment content: %+v", comment)
+	}
+
+	// 変更前後の値は文字列・数値・nullのいずれも文字列として扱う
+	issue := items[7].Content.Issue
+	if issue == nil || issue.IssueKey != "PROJ-4" || len(issue.Changes) != 2 {
+		t.Fatalf("Unexpected issue content: %+v", issue)
+	}
+	if issue.Changes[0] != (model.ActivityChange{Field: "status", OldValue: "1", NewValue: "2"}) ||
+		issue.Changes[1] != (model.ActivityChange{Field: "assigner", OldValue: "", NewValue: "5"}) {
+		t.Errorf("Unexpected changes: %+v", issue.Changes)
+	}
+	if items[7].IssueKey != "PROJ-4" || items[7].ContentSummary != "ログイン機能" {
+		t.Errorf("Unexpected issue key or summary: %q %q", items[7].IssueKey, items[7].ContentSummary)
+	}
+}
+
+// 種別ごとの内容からBacklogの対応する画面のURLを組み立てることを確認する
+func TestBacklogClient_ItemURL(t *testing.T) {
+	client := NewBacklogClient("https://example.backlog.jp", "", "")
+
+	testCases := []struct {
+		name        string
+		content     *model.ActivityContent
+		expectedURL string
+	}{
+		{"課題", &model.ActivityContent{Issue: &model.IssueContent{IssueKey: "PROJ-4"}}, "/view/PROJ-4"},
+		{"コメント", &model.ActivityContent{Comment: &model.CommentContent{IssueKey: "PROJ-4", CommentID: "77"}}, "/view/PROJ-4#comment-77"},
+		{"Wiki", &model.ActivityContent{Wiki: &model.WikiContent{WikiID: "2", Name: "Home"}}, "/alias/wiki/2"},
+		{"共有ファイル", &model.ActivityContent{File: &model.FileContent{Dir: "/設計/", Name: "spec 1.pdf"}}, "/file/PROJ/%E8%A8%AD%E8%A8%88/spec%201.pdf"},
+		{"ブランチへのプッシュ", &model.ActivityContent{GitPush: &model.GitPushContent{Repository: "app", Ref: "refs/heads/feature/login", ChangeType: "update"}}, "/git/PROJ/app/tree/feature/login"},
+		{"ブランチの削除", &model.ActivityContent{GitPush: &model.GitPushContent{Repository: "app", Ref: "refs/heads/old", ChangeType: "delete"}}, "/git/PROJ/app"},
+		{"プルリクエスト", &model.ActivityContent{PullRequest: &model.PullRequestContent{Repository: "app", Number: 12}}, "/git/PROJ/app/pullRequests/12"},
+		{"マイルストーン", &model.ActivityContent{Milestone: &model.MilestoneContent{MilestoneID: "5"}}, "/find/PROJ?milestoneId=5"},
+		{"プロジェクトへの参加", &model.ActivityContent{Membership: &model.MembershipContent{}}, "/projects/PROJ"},
+		{"内容なし", nil, "/projects/PROJ"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			url := client.itemURL(&model.BacklogItem{ProjectKey: "PROJ", Content: tc.content})
+			if url != "https://example.backlog.jp"+tc.expectedURL {
+				t.Errorf("Expected %s, got %s", tc.expectedURL, url)
+			}
+		})
+	}
+
+	// プロジェクトが分からない場合はダッシュボードを返す
+	if url := client.itemURL(&model.BacklogItem{}); url != "https://example.backlog.jp/dashboard" {
+		t.Errorf("Expected dashboard URL, got %s", url)
+	}
+}
+
+func TestBacklogClient_GetProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/projects":
+			w.Write([]byte(`[{"id": 10, "projectKey": "PROJ", "name": "プロジェクトA", "archived": false}, {"id": 11, "projectKey": "OLD", "name": "旧プロジェクト", "archived": true}]`))
+		case "/api/v2/projects/PROJ":
+			w.Write([]byte(`{"id": 10, "projectKey": "PROJ", "name": "プロジェクトA", "archived": false}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": [{"message": "No project."}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := NewBacklogClient(server.URL, "", "")
+
+	projects, err := client.GetProjects("token")
+	if err != nil {
+		t.Fatalf("Failed to get projects: %v", err)
+	}
+	if len(projects) != 2 || projects[0].ID != "10" || projects[1].ProjectKey != "OLD" || !projects[1].Archived {
+		t.Errorf("Unexpected projects: %+v %+v", projects[0], projects[1])
+	}
+
+	project, err := client.GetProject("token", "PROJ")
+	if err != nil {
+		t.Fatalf("Failed to get project: %v", err)
+	}
+	if project.ID != "10" || project.Name != "プロジェクトA" {
+		t.Errorf("Unexpected project: %+v", project)
+	}
+
+	if _, err := client.GetProject("token", "UNKNOWN"); err != model.ErrProjectNotFound {
+		t.Errorf("Expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+// ユーザーのアクティビティはユーザー別のAPIから取得し、個人フィードは作成者で絞り込むことを確認する
+func TestBacklogClient_SearchPersonalActivities(t *testing.T) {
+	server, requests := newActivityServer(t, 30)
+	client := NewBacklogClient(server.URL, "", "")
+
+	if _, err := client.SearchUserActivities("token", "1", model.PageRequest{Limit: 5}); err != nil {
+		t.Fatalf("Failed to search user activities: %v", err)
+	}
+	if path := (*requests)[0].URL.Path; path != "/api/v2/users/1/activities" {
+		t.Errorf("Unexpected path: %s", path)
+	}
+
+	result, err := client.SearchPersonalActivities("token", model.PersonalFeed{UserID: "1"}, model.PageRequest{Limit: 5})
+	if err != nil {
+		t.Fatalf("Failed to search personal activities: %v", err)
+	}
+	if len(result.Items) != 5 || result.NextCursor == "" {
+		t.Fatalf("Expected 5 items with next cursor, got %d %q", len(result.Items), result.NextCursor)
+	}
+	for _, item := range result.Items {
+		if item.CreatedUser.ID != "1" {
+			t.Errorf("Unexpected item %s created by %s", item.ID, item.CreatedUser.ID)
+		}
+	}
+}
+
+// 担当している課題とウォッチしている課題を取得できることを確認する
+func TestBacklogClient_GetAssignedAndWatchedIssues(t *testing.T) {
+	var assigneeIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/issues":
+			assigneeIDs = r.URL.Query()["assigneeId[]"]
+			w.Write([]byte(`[{"id": 1, "issueKey": "PROJ-1", "summary": "担当課題"}]`))
+		case "/api/v2/users/1/watchings":
+			w.Write([]byte(`[{"id": 100, "issue": {"id": 2, "issueKey": "PROJ-2", "summary": "ウォッチ課題"}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	client := NewBacklogClient(server.URL, "", "")
+
+	assigned, err := client.GetAssignedIssues("token", "1")
+	if err != nil {
+		t.Fatalf("Failed to get assigned issues: %v", err)
+	}
+	if len(assigned) != 1 || assigned[0].IssueKey != "PROJ-1" || len(assigneeIDs) != 1 || assigneeIDs[0] != "1" {
+		t.Errorf("Unexpected assigned issues: %+v (assigneeId %v)", assigned, assigneeIDs)
+	}
+
+	watched, err := client.GetWatchedIssues("token", "1")
+	if err != nil {
+		t.Fatalf("Failed to get watched issues: %v", err)
+	}
+	if len(watched) != 1 || watched[0].IssueKey != "PROJ-2" {
+		t.Errorf("Unexpected watched issues: %+v", watched)
+	}
+}
+
+// 権限のないプロジェクトや課題への403はトークンの失効として扱わず、401だけをErrBacklogUnauthorizedにすることを確認する
+func TestBacklogClient_ForbiddenIsNotUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer expired" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"errors": [{"message": "Authenticate error."}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"errors": [{"message": "No permission."}]}`))
+	}))
+	t.Cleanup(server.Close)
+	client := NewBacklogClient(server.URL, "", "")
+
+	if _, err := client.GetIssue("token", "PRIV-1"); !errors.Is(err, model.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound for a forbidden issue, got %v", err)
+	}
+	if _, err := client.GetActivity("token", 10); !errors.Is(err, model.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound for a forbidden activity, got %v", err)
+	}
+	if _, err := client.GetProject("token", "PRIV"); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("Expected ErrProjectNotFound for a forbidden project, got %v", err)
+	}
+	if _, err := client.GetProjects("token"); !errors.Is(err, model.ErrBacklogForbidden) || errors.Is(err, model.ErrBacklogUnauthorized) {
+		t.Errorf("Expected ErrBacklogForbidden, got %v", err)
+	}
+	if _, err := client.GetProjects("expired"); !errors.Is(err, model.ErrBacklogUnauthorized) {
+		t.Errorf("Expected ErrBacklogUnauthorized, got %v", err)
+	}
+}
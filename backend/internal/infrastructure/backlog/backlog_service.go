@@ -2,6 +2,7 @@ package backlog
 
 import (
 	"log"
+	"strconv"
 	"time"
 
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
@@ -9,83 +10,192 @@ import (
 
 // BacklogItemService はBacklogItemServiceのインフラ層実装
 type BacklogItemService struct {
-	client         *BacklogClient
-	authRepository model.AuthRepository
+	client   *BacklogClient
+	demoMode bool
 }
 
 // NewBacklogItemService はBacklogItemServiceのインスタンスを生成
-func NewBacklogItemService(client *BacklogClient, authRepository model.AuthRepository) *BacklogItemService {
+// demoModeが有効な場合はBacklog APIを呼び出さずにモックデータを返す
+func NewBacklogItemService(client *BacklogClient, demoMode bool) *BacklogItemService {
 	return &BacklogItemService{
-		client:         client,
-		authRepository: authRepository,
+		client:   client,
+		demoMode: demoMode,
 	}
 }
 
-// SearchItems はキーワードでBacklog更新情報を検索
-func (s *BacklogItemService) SearchItems(keyword string) ([]*model.BacklogItem, error) {
-	// アクセストークンの取得（認証リポジトリからランダムなユーザーのトークンを取得）
-	tokens, err := s.authRepository.GetAllTokens()
-	if err != nil || len(tokens) == 0 {
-		// トークンがない場合はモックデータで代用
-		return s.mockBacklogItems(), nil
+// SearchItems は呼び出し元ユーザーのアクセストークンでBacklog更新情報を検索
+func (s *BacklogItemService) SearchItems(accessToken string, criteria model.SearchCriteria, page model.PageRequest) (*model.BacklogItemPage, error) {
+	if s.demoMode {
+		return paginateItems(filterItems(s.mockBacklogItems(), criteria), page)
 	}
 
-	// 最初のトークンを使用
-	token := tokens[0]
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
 
-	// 最新の100件のアクティビティを取得
-	items, err := s.client.SearchActivities(token.AccessToken, keyword, 100)
-	log.Println("SearchActivities items:", len(items))
+	// カーソル位置から過去に遡って1ページ分のアクティビティを取得
+	result, err := s.client.SearchActivities(accessToken, criteria, page)
 	if err != nil {
 		log.Println("SearchActivities err:", err)
-		// APIエラーの場合はモックデータで代用
-		return s.mockBacklogItems(), nil
+		return nil, err
 	}
 
-	return items, nil
+	return result, nil
 }
 
-// GetFavorites はユーザーのお気に入りBacklog更新情報を取得
-func (s *BacklogItemService) GetFavorites(userID string) ([]*model.BacklogItem, error) {
-	// ユーザーIDからトークンを取得
-	token, err := s.authRepository.GetToken(userID)
-	if err != nil {
-		// トークンがない場合はモックデータで代用
-		items := s.mockBacklogItems()
-		return items[:2], nil
+// GetItem は呼び出し元ユーザーのアクセストークンでIDを指定してBacklog更新情報を取得
+func (s *BacklogItemService) GetItem(accessToken string, itemID string) (*model.BacklogItem, error) {
+	if s.demoMode {
+		for _, item := range s.mockBacklogItems() {
+			if item.ID == itemID {
+				return item, nil
+			}
+		}
+		return nil, model.ErrItemNotFound
 	}
 
-	// Backlog APIを呼び出して全アクティビティを取得
-	items, err := s.client.GetActivities(token.AccessToken, 50)
-	log.Println("GetActivities items:", items)
-	if err != nil {
-		log.Println("GetActivities err:", err)
-		// APIエラーの場合はモックデータで代用
-		mockItems := s.mockBacklogItems()
-		return mockItems[:2], nil
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	id, err := strconv.Atoi(itemID)
+	if err != nil || id <= 0 {
+		return nil, model.ErrItemNotFound
+	}
+
+	return s.client.GetActivity(accessToken, id)
+}
+
+// GetIssue は呼び出し元ユーザーのアクセストークンで課題の現在の状態を取得
+func (s *BacklogItemService) GetIssue(accessToken string, issueKey string) (*model.Issue, error) {
+	// デモ用のモックデータは課題を参照しない
+	if s.demoMode {
+		return nil, model.ErrItemNotFound
+	}
+
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.GetIssue(accessToken, issueKey)
+}
+
+// GetRateLimit はアクセストークンのBacklog APIのレート制限の状態を取得
+func (s *BacklogItemService) GetRateLimit(accessToken string) *model.RateLimit {
+	// デモモードではBacklog APIを呼び出さない
+	if s.demoMode {
+		return nil
+	}
+
+	return s.client.RateLimit(accessToken)
+}
+
+// GetUserItems は呼び出し元ユーザーのアクセストークンでユーザーが作成したBacklog更新情報を取得
+func (s *BacklogItemService) GetUserItems(accessToken string, userID string, page model.PageRequest) (*model.BacklogItemPage, error) {
+	if s.demoMode {
+		return paginateItems(filterItems(s.mockBacklogItems(), model.SearchCriteria{CreatedUserIDs: []string{userID}}), page)
+	}
+
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.SearchUserActivities(accessToken, userID, page)
+}
+
+// SearchPersonalItems は呼び出し元ユーザーのアクセストークンでユーザー自身と担当・ウォッチしている課題のBacklog更新情報を取得
+func (s *BacklogItemService) SearchPersonalItems(accessToken string, feed model.PersonalFeed, page model.PageRequest) (*model.BacklogItemPage, error) {
+	if s.demoMode {
+		var items []*model.BacklogItem
+		for _, item := range s.mockBacklogItems() {
+			if feed.Matches(item) {
+				items = append(items, item)
+			}
+		}
+		return paginateItems(items, page)
 	}
 
-	// if len(items) > 3 {
-	// 	return items[:3], nil
-	// }
-	return items, nil
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.SearchPersonalActivities(accessToken, feed, page)
 }
 
-// AddFavorite はBacklog更新情報をお気に入りに追加
-func (s *BacklogItemService) AddFavorite(userID string, itemID string) error {
-	// お気に入り追加の実装
-	// データベースに保存する
-	return nil
+// GetAssignedIssues は呼び出し元ユーザーのアクセストークンでユーザーが担当している課題を取得
+func (s *BacklogItemService) GetAssignedIssues(accessToken string, userID string) ([]*model.Issue, error) {
+	// デモ用のモックデータは課題を参照しない
+	if s.demoMode {
+		return nil, nil
+	}
+
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.GetAssignedIssues(accessToken, userID)
 }
 
-// RemoveFavorite はBacklog更新情報をお気に入りから削除
-func (s *BacklogItemService) RemoveFavorite(userID string, itemID string) error {
-	// お気に入り削除の実装
-	// データベースから削除する
-	return nil
+// GetWatchedIssues は呼び出し元ユーザーのアクセストークンでユーザーがウォッチしている課題を取得
+func (s *BacklogItemService) GetWatchedIssues(accessToken string, userID string) ([]*model.Issue, error) {
+	// デモ用のモックデータは課題を参照しない
+	if s.demoMode {
+		return nil, nil
+	}
+
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.GetWatchedIssues(accessToken, userID)
+}
+
+// GetProjects は呼び出し元ユーザーのアクセストークンで参加しているプロジェクトを取得
+func (s *BacklogItemService) GetProjects(accessToken string) ([]*model.Project, error) {
+	if s.demoMode {
+		return mockProjects(s.mockBacklogItems()), nil
+	}
+
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.GetProjects(accessToken)
+}
+
+// GetProject は呼び出し元ユーザーのアクセストークンでプロジェクトIDまたはプロジェクトキーを指定してプロジェクトを取得
+func (s *BacklogItemService) GetProject(accessToken string, projectIDOrKey string) (*model.Project, error) {
+	if s.demoMode {
+		for _, project := range mockProjects(s.mockBacklogItems()) {
+			if project.ID == projectIDOrKey || project.ProjectKey == projectIDOrKey {
+				return project, nil
+			}
+		}
+		return nil, model.ErrProjectNotFound
+	}
+
+	if accessToken == "" {
+		return nil, model.ErrBacklogUnauthorized
+	}
+
+	return s.client.GetProject(accessToken, projectIDOrKey)
+}
+
+// mockProjects はデモモード用のモック更新情報に含まれるプロジェクトを重複なく返す
+func mockProjects(items []*model.BacklogItem) []*model.Project {
+	var projects []*model.Project
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if seen[item.ProjectID] {
+			continue
+		}
+		seen[item.ProjectID] = true
+		projects = append(projects, &model.Project{ID: item.ProjectID, ProjectKey: item.ProjectKey, Name: item.ProjectName})
+	}
+	return projects
 }
 
-// mockBacklogItems はモックのBacklog更新情報を生成
+// mockBacklogItems はデモモード用のモックBacklog更新情報を生成
 func (s *BacklogItemService) mockBacklogItems() []*model.BacklogItem {
 	// 現在時刻を基準に日付を設定
 	now := time.Now()
@@ -95,6 +205,7 @@ func (s *BacklogItemService) mockBacklogItems() []*model.BacklogItem {
 			ID:             "1",
 			ProjectID:      "1",
 			ProjectName:    "プロジェクトA",
+			TypeID:         1,
 			Type:           "課題",
 			ContentSummary: "ログイン機能の実装",
 			CreatedUser: model.User{
@@ -110,6 +221,7 @@ func (s *BacklogItemService) mockBacklogItems() []*model.BacklogItem {
 			ID:             "2",
 			ProjectID:      "1",
 			ProjectName:    "プロジェクトA",
+			TypeID:         1,
 			Type:           "課題",
 			ContentSummary: "検索機能の追加",
 			CreatedUser: model.User{
@@ -125,6 +237,7 @@ func (s *BacklogItemService) mockBacklogItems() []*model.BacklogItem {
 			ID:             "3",
 			ProjectID:      "2",
 			ProjectName:    "プロジェクトB",
+			TypeID:         5,
 			Type:           "Wiki",
 			ContentSummary: "設計ドキュメント",
 			CreatedUser: model.User{
@@ -140,6 +253,7 @@ func (s *BacklogItemService) mockBacklogItems() []*model.BacklogItem {
 			ID:             "4",
 			ProjectID:      "2",
 			ProjectName:    "プロジェクトB",
+			TypeID:         12,
 			Type:           "Git",
 			ContentSummary: "バグ修正のコミット",
 			CreatedUser: model.User{
@@ -155,6 +269,7 @@ func (s *BacklogItemService) mockBacklogItems() []*model.BacklogItem {
 			ID:             "5",
 			ProjectID:      "3",
 			ProjectName:    "プロジェクトC",
+			TypeID:         1,
 			Type:           "課題",
 			ContentSummary: "UIデザインの改善",
 			CreatedUser: model.User{
@@ -0,0 +1,45 @@
+package backlog
+
+import (
+	"sort"
+	"strconv"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+const (
+	// activitiesPerRequest はBacklog APIの1回の呼び出しで取得するアクティビティ数（APIの上限）
+	activitiesPerRequest = 100
+	// maxRequestsPerPage は1ページを返すまでにBacklog APIを呼び出す最大回数
+	maxRequestsPerPage = 5
+)
+
+// paginateItems は取得済みの更新情報をIDの新しい順に並べ、カーソル以降の1ページ分を返す
+func paginateItems(items []*model.BacklogItem, page model.PageRequest) (*model.BacklogItemPage, error) {
+	maxID, err := model.DecodeActivityCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Size()
+
+	sorted := make([]*model.BacklogItem, 0, len(items))
+	for _, item := range items {
+		id, err := strconv.Atoi(item.ID)
+		if err != nil || (maxID > 0 && id >= maxID) {
+			continue
+		}
+		sorted = append(sorted, item)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, _ := strconv.Atoi(sorted[i].ID)
+		b, _ := strconv.Atoi(sorted[j].ID)
+		return a > b
+	})
+
+	if len(sorted) <= limit {
+		return &model.BacklogItemPage{Items: sorted}, nil
+	}
+
+	last, _ := strconv.Atoi(sorted[limit-1].ID)
+	return &model.BacklogItemPage{Items: sorted[:limit], NextCursor: model.EncodeActivityCursor(last)}, nil
+}
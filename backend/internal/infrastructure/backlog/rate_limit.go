@@ -0,0 +1,180 @@
+package backlog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// retryPolicy はGETリクエストを再試行する回数と待機時間の方針
+type retryPolicy struct {
+	maxAttempts int           // 最初の試行を含む最大試行回数
+	baseDelay   time.Duration // 1回目の再試行の待機時間（試行ごとに倍にする）
+	maxDelay    time.Duration // 待機時間の上限（レート制限の解除がこれより先の場合は再試行しない）
+	sleep       func(time.Duration)
+}
+
+// defaultRetryPolicy はBacklogClientの既定の再試行方針
+func defaultRetryPolicy() retryPolicy {
+	return retryPolicy{
+		maxAttempts: 3,
+		baseDelay:   500 * time.Millisecond,
+		maxDelay:    5 * time.Second,
+		sleep:       time.Sleep,
+	}
+}
+
+// delay はattempt回目の試行結果から再試行までの待機時間を返す（再試行しない場合はfalse）
+// 接続エラーと5xxは指数バックオフ、429はX-RateLimit-Resetの日時まで待機する
+func (p retryPolicy) delay(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.maxAttempts {
+		return 0, false
+	}
+
+	switch {
+	case err != nil, resp.StatusCode >= http.StatusInternalServerError:
+		return p.backoff(attempt), true
+	case resp.StatusCode == http.StatusTooManyRequests:
+		reset := rateLimitReset(resp.Header)
+		if reset.IsZero() {
+			return p.backoff(attempt), true
+		}
+		return p.untilReset(reset)
+	default:
+		return 0, false
+	}
+}
+
+// backoff はattempt回目の再試行までの待機時間を指数バックオフにジッターを加えて返す
+// 同時に失敗したリクエストが一斉に再試行しないよう、待機時間の後半をランダムにする
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := min(p.baseDelay<<(attempt-1), p.maxDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// untilReset はレート制限が解除されるまでの待機時間を返す（maxDelayより先の場合はfalse）
+func (p retryPolicy) untilReset(reset time.Time) (time.Duration, bool) {
+	wait := time.Until(reset)
+	if wait > p.maxDelay {
+		return 0, false
+	}
+	return max(wait, 0) + rand.N(p.baseDelay/2+1), true
+}
+
+// get は認証ヘッダーを付けてGETリクエストを送信し、レスポンスを返す
+// GETは冪等なため、接続エラー・5xx・429の場合は待機して再試行する（最後の試行のレスポンスを返す）
+// 直前のレスポンスで残り回数が0になっている場合は、解除まで待機するかRateLimitErrorを返す
+func (c *BacklogClient) get(token, apiURL string) (*http.Response, error) {
+	if limit := c.rateLimits.get(token); limit != nil && limit.Remaining == 0 && limit.Reset.After(time.Now()) {
+		wait, ok := c.retry.untilReset(limit.Reset)
+		if !ok {
+			return nil, &model.RateLimitError{Reset: limit.Reset}
+		}
+		c.retry.sleep(wait)
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequest("GET", apiURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Authorization", "Bearer "+token)
+
+		resp, err := c.httpClient.Do(req)
+		if err == nil {
+			c.rateLimits.record(token, resp.Header)
+		}
+
+		wait, retry := c.retry.delay(attempt, resp, err)
+		if !retry {
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", model.ErrBacklogUnavailable, err)
+			}
+			return resp, nil
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		c.retry.sleep(wait)
+	}
+}
+
+// RateLimit はアクセストークンの直近のレート制限の状態を返す（まだレスポンスを受け取っていない場合はnil）
+func (c *BacklogClient) RateLimit(token string) *model.RateLimit {
+	return c.rateLimits.get(token)
+}
+
+// rateLimitTracker はBacklog APIのレスポンスヘッダーからアクセストークンごとのレート制限の状態を記録する
+// アクセストークンをそのまま保持しないよう、ハッシュ値をキーにする
+type rateLimitTracker struct {
+	mu     sync.Mutex
+	limits map[string]model.RateLimit
+}
+
+// newRateLimitTracker はrateLimitTrackerのインスタンスを生成
+func newRateLimitTracker() *rateLimitTracker {
+	return &rateLimitTracker{limits: make(map[string]model.RateLimit)}
+}
+
+// record はレスポンスヘッダーのレート制限の状態を記録する（ヘッダーがない場合は何もしない）
+// 解除日時を過ぎた他のトークンの状態は、使われなくなったトークンの分が残り続けないよう削除する
+func (t *rateLimitTracker) record(token string, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	now := time.Now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, state := range t.limits {
+		if state.Reset.Before(now) {
+			delete(t.limits, key)
+		}
+	}
+	t.limits[tokenKey(token)] = model.RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     rateLimitReset(header),
+		UpdatedAt: now,
+	}
+}
+
+// get はアクセストークンのレート制限の状態を返す（記録がない場合はnil）
+func (t *rateLimitTracker) get(token string) *model.RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.limits[tokenKey(token)]
+	if !ok {
+		return nil
+	}
+	return &state
+}
+
+// tokenKey はアクセストークンのハッシュ値を返す
+func tokenKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// rateLimitReset はX-RateLimit-Reset（UNIX時間の秒）から制限が解除される日時を返す（ない場合はゼロ値）
+func rateLimitReset(header http.Header) time.Time {
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(reset, 0)
+}
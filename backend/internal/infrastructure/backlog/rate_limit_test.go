@@ -0,0 +1,130 @@
+package backlog
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// newRetryTestClient は待機せずに待機時間を記録するBacklogClientを生成
+func newRetryTestClient(spaceURL string) (*BacklogClient, *[]time.Duration) {
+	var waits []time.Duration
+	client := NewBacklogClient(spaceURL, "", "")
+	client.retry.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+	}
+	return client, &waits
+}
+
+// 一時的なサーバーエラーは再試行し、レスポンスヘッダーのレート制限の状態を記録することを確認する
+func TestBacklogClient_RetryServerError(t *testing.T) {
+	requests := 0
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Limit", "600")
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(600-requests))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+	client, waits := newRetryTestClient(server.URL)
+
+	if client.RateLimit("token") != nil {
+		t.Fatal("Expected no rate limit before requests")
+	}
+	if _, err := client.GetProjects("token"); err != nil {
+		t.Fatalf("Failed to get projects: %v", err)
+	}
+	if requests != 3 || len(*waits) != 2 {
+		t.Errorf("Expected 3 requests and 2 waits, got %d %v", requests, *waits)
+	}
+
+	limit := client.RateLimit("token")
+	if limit == nil || limit.Limit != 600 || limit.Remaining != 597 || !limit.Reset.Equal(reset) {
+		t.Errorf("Unexpected rate limit: %+v", limit)
+	}
+	if client.RateLimit("other") != nil {
+		t.Error("Expected no rate limit for other token")
+	}
+}
+
+// 最大試行回数まで失敗した場合は最後のレスポンスのエラーを返すことを確認する
+func TestBacklogClient_RetryExhausted(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+	client, _ := newRetryTestClient(server.URL)
+
+	_, err := client.GetProjects("token")
+	if !errors.Is(err, model.ErrBacklogUnavailable) {
+		t.Errorf("Expected ErrBacklogUnavailable, got %v", err)
+	}
+	if requests != client.retry.maxAttempts {
+		t.Errorf("Expected %d requests, got %d", client.retry.maxAttempts, requests)
+	}
+}
+
+func TestBacklogClient_RateLimited(t *testing.T) {
+	testCases := []struct {
+		name             string
+		resetAfter       time.Duration
+		expectedRequests int
+		expectError      bool
+	}{
+		{"解除が近い場合は解除まで待って再試行する", time.Second, 2, false},
+		{"解除が先の場合は再試行せずにエラーを返す", time.Minute, 1, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			reset := time.Now().Add(tc.resetAfter).Truncate(time.Second)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == 1 {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.Write([]byte(`[]`))
+			}))
+			t.Cleanup(server.Close)
+			client, _ := newRetryTestClient(server.URL)
+
+			_, err := client.GetProjects("token")
+			if requests != tc.expectedRequests {
+				t.Errorf("Expected %d requests, got %d", tc.expectedRequests, requests)
+			}
+			if !tc.expectError {
+				if err != nil {
+					t.Errorf("Unexpected error: %v", err)
+				}
+				return
+			}
+
+			var rateLimitErr *model.RateLimitError
+			if !errors.Is(err, model.ErrBacklogRateLimited) || !errors.As(err, &rateLimitErr) || !rateLimitErr.Reset.Equal(reset) {
+				t.Fatalf("Expected RateLimitError with reset %v, got %v", reset, err)
+			}
+
+			// 残り回数が0の間はBacklog APIを呼び出さずにエラーを返す
+			if _, err := client.GetProjects("token"); !errors.As(err, &rateLimitErr) || requests != 1 {
+				t.Errorf("Expected RateLimitError without request, got %v (%d requests)", err, requests)
+			}
+		})
+	}
+}
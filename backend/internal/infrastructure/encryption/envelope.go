@@ -0,0 +1,133 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	// envelopeVersion は暗号文フォーマットのバージョン
+	envelopeVersion byte = 1
+	// keySize はマスターキーとデータキーのバイト長（AES-256）
+	keySize = 32
+)
+
+// ErrInvalidCiphertext は復号できない暗号文エラー
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Envelope はエンベロープ暗号化の実装
+// レコードごとにランダムなデータキーで平文を暗号化し、データキー自体をマスターキーで暗号化して暗号文に同梱する
+type Envelope struct {
+	masterKey cipher.AEAD
+}
+
+// NewEnvelope はマスターキーからEnvelopeのインスタンスを生成
+func NewEnvelope(masterKey []byte) (*Envelope, error) {
+	if len(masterKey) != keySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", keySize, len(masterKey))
+	}
+
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{masterKey: aead}, nil
+}
+
+// ParseKey はBase64エンコードされたマスターキーをデコード
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode master key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal は平文を暗号化
+// associatedDataには保存先の行を識別する値（ユーザーIDなど）を指定し、Openで同じ値を指定した場合だけ復号できる
+// これにより、あるユーザーの暗号文を別のユーザーの行にコピーしても復号できない
+// フォーマット: version(1) | 暗号化データキー(nonce+key+tag) | 暗号化データ(nonce+data+tag)
+func (e *Envelope) Seal(plaintext, associatedData []byte) ([]byte, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedKey, err := seal(e.masterKey, dataKey, associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedData, err := seal(dataAEAD, plaintext, associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 1+len(encryptedKey)+len(encryptedData))
+	out = append(out, envelopeVersion)
+	out = append(out, encryptedKey...)
+	out = append(out, encryptedData...)
+	return out, nil
+}
+
+// Open は暗号文を復号（associatedDataが暗号化時と異なる場合はErrInvalidCiphertextを返す）
+func (e *Envelope) Open(ciphertext, associatedData []byte) ([]byte, error) {
+	encryptedKeySize := e.masterKey.NonceSize() + keySize + e.masterKey.Overhead()
+	if len(ciphertext) < 1+encryptedKeySize || ciphertext[0] != envelopeVersion {
+		return nil, ErrInvalidCiphertext
+	}
+
+	dataKey, err := open(e.masterKey, ciphertext[1:1+encryptedKeySize], associatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dataAEAD, ciphertext[1+encryptedKeySize:], associatedData)
+}
+
+// newAEAD は鍵からAES-GCMのAEADを生成
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal はランダムなnonceを先頭に付与して暗号化
+func seal(aead cipher.AEAD, plaintext, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+// open は先頭のnonceを使って復号
+func open(aead cipher.AEAD, ciphertext, associatedData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrInvalidCiphertext
+	}
+
+	nonce, data := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data, associatedData)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,72 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+)
+
+func newTestEnvelope(t *testing.T) *Envelope {
+	t.Helper()
+
+	key := make([]byte, keySize)
+	rand.Read(key)
+
+	envelope, err := NewEnvelope(key)
+	if err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+	return envelope
+}
+
+func TestEnvelope_SealAndOpen(t *testing.T) {
+	envelope := newTestEnvelope(t)
+	plaintext := []byte(`{"accessToken":"secret"}`)
+
+	ciphertext, err := envelope.Seal(plaintext, []byte("user1"))
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+	if bytes.Contains(ciphertext, []byte("secret")) {
+		t.Error("Ciphertext must not contain plaintext")
+	}
+
+	opened, err := envelope.Open(ciphertext, []byte("user1"))
+	if err != nil {
+		t.Fatalf("Failed to open: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Expected %s, got %s", plaintext, opened)
+	}
+}
+
+func TestEnvelope_OpenRejectsTamperedOrForeignCiphertext(t *testing.T) {
+	envelope := newTestEnvelope(t)
+
+	ciphertext, err := envelope.Seal([]byte("token"), []byte("user1"))
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, err := envelope.Open(tampered, []byte("user1")); err != ErrInvalidCiphertext {
+		t.Errorf("Expected ErrInvalidCiphertext for tampered ciphertext, got %v", err)
+	}
+
+	// 別のマスターキーでは復号できない
+	if _, err := newTestEnvelope(t).Open(ciphertext, []byte("user1")); err != ErrInvalidCiphertext {
+		t.Errorf("Expected ErrInvalidCiphertext for foreign key, got %v", err)
+	}
+
+	// 別の行の識別子では復号できない（他のユーザーの行にコピーされた暗号文）
+	if _, err := envelope.Open(ciphertext, []byte("user2")); err != ErrInvalidCiphertext {
+		t.Errorf("Expected ErrInvalidCiphertext for other identity, got %v", err)
+	}
+}
+
+func TestNewEnvelope_RejectsShortKey(t *testing.T) {
+	if _, err := NewEnvelope([]byte("short")); err == nil {
+		t.Error("Expected error for short master key, but got nil")
+	}
+}
@@ -0,0 +1,283 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+const (
+	// ActivityTableName は全文検索インデックスに取り込んだ更新情報を保存するDynamoDBのテーブル名
+	ActivityTableName = "ActivityDocuments"
+	// IngestStateTableName は更新情報の取り込み状況を保存するDynamoDBのテーブル名
+	IngestStateTableName = "ActivityIngestStates"
+
+	// batchWriteSize はBatchWriteItemで1回に書き込める最大件数
+	batchWriteSize = 25
+	// batchRetryDelay はBatchWriteItemで処理されなかった書き込みを再実行するまでの待ち時間
+	batchRetryDelay = 100 * time.Millisecond
+)
+
+// ActivityItem はDynamoDBに保存するための更新情報アイテム構造体
+// 更新情報は種別ごとに内容が異なるため、JSONのまま保存する
+type ActivityItem struct {
+	UserID     string `dynamodbav:"userId"`
+	ActivityID int    `dynamodbav:"activityId"`
+	Data       string `dynamodbav:"data"`
+}
+
+// IngestStateItem はDynamoDBに保存するための取り込み状況アイテム構造体
+type IngestStateItem struct {
+	UserID  string `dynamodbav:"userId"`
+	Data    string `dynamodbav:"data"`
+	Version int    `dynamodbav:"version"`
+}
+
+// ActivityStore はDynamoDBを使った更新情報ストアの実装
+type ActivityStore struct {
+	client *dynamodb.Client
+	tables TableNames
+}
+
+// NewActivityStore はActivityStoreのインスタンスを生成
+func NewActivityStore(client *dynamodb.Client, tables TableNames) *ActivityStore {
+	return &ActivityStore{
+		client: client,
+		tables: tables,
+	}
+}
+
+// SaveActivities は更新情報を保存してから取り込み状況を保存
+// 取り込み状況は保存済みのバージョンが1つ前の場合だけ更新し、それ以外はErrIngestStateConflictを返す
+// 先に保存した更新情報は同じユーザーのトークンで取得したものなので、競合した場合も残しておく
+func (s *ActivityStore) SaveActivities(userID string, items []*model.BacklogItem, state model.IngestState) error {
+	requests := make([]types.WriteRequest, 0, len(items))
+	for _, item := range items {
+		id, err := strconv.Atoi(item.ID)
+		if err != nil {
+			continue
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal activity: %w", err)
+		}
+		av, err := attributevalue.MarshalMap(ActivityItem{UserID: userID, ActivityID: id, Data: string(data)})
+		if err != nil {
+			return fmt.Errorf("failed to marshal activity: %w", err)
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+	if err := s.batchWrite(s.tables.Activities, requests); err != nil {
+		return fmt.Errorf("failed to save activities: %w", err)
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingest state: %w", err)
+	}
+	av, err := attributevalue.MarshalMap(IngestStateItem{UserID: userID, Data: string(data), Version: state.Version})
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingest state: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		TableName:           aws.String(s.tables.IngestStates),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(userId)"),
+	}
+	if state.Version > 1 {
+		input.ConditionExpression = aws.String("version = :expected")
+		input.ExpressionAttributeValues = map[string]types.AttributeValue{
+			":expected": &types.AttributeValueMemberN{Value: strconv.Itoa(state.Version - 1)},
+		}
+	}
+
+	_, err = s.client.PutItem(context.TODO(), input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return model.ErrIngestStateConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save ingest state: %w", err)
+	}
+
+	return nil
+}
+
+// LoadIngestState は保存済みの取り込み状況を取得
+func (s *ActivityStore) LoadIngestState(userID string) (model.IngestState, bool, error) {
+	var state model.IngestState
+
+	output, err := s.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(s.tables.IngestStates),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return state, false, fmt.Errorf("failed to get ingest state: %w", err)
+	}
+	if output.Item == nil {
+		return state, false, nil
+	}
+
+	var item IngestStateItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return state, false, fmt.Errorf("failed to unmarshal ingest state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(item.Data), &state); err != nil {
+		return state, false, fmt.Errorf("failed to unmarshal ingest state: %w", err)
+	}
+	return state, true, nil
+}
+
+// LoadActivities は保存済みの更新情報を新しい順に取得
+func (s *ActivityStore) LoadActivities(userID string) ([]*model.BacklogItem, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.tables.Activities),
+		KeyConditionExpression: aws.String("userId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	}
+
+	items := make([]*model.BacklogItem, 0)
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query activities: %w", err)
+		}
+
+		var activities []ActivityItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &activities); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal activities: %w", err)
+		}
+		for _, activity := range activities {
+			var item model.BacklogItem
+			if err := json.Unmarshal([]byte(activity.Data), &item); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal activity: %w", err)
+			}
+			items = append(items, &item)
+		}
+	}
+
+	return items, nil
+}
+
+// PruneActivities はoldestIDより古い更新情報を削除
+func (s *ActivityStore) PruneActivities(userID string, oldestID int) error {
+	return s.deleteActivities(&dynamodb.QueryInput{
+		TableName:              aws.String(s.tables.Activities),
+		KeyConditionExpression: aws.String("userId = :userId AND activityId < :oldestId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId":   &types.AttributeValueMemberS{Value: userID},
+			":oldestId": &types.AttributeValueMemberN{Value: strconv.Itoa(oldestID)},
+		},
+	})
+}
+
+// DeleteActivities はユーザーの更新情報と取り込み状況を削除
+func (s *ActivityStore) DeleteActivities(userID string) error {
+	err := s.deleteActivities(&dynamodb.QueryInput{
+		TableName:              aws.String(s.tables.Activities),
+		KeyConditionExpression: aws.String("userId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tables.IngestStates),
+		Key: map[string]types.AttributeValue{
+			"userId": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete ingest state: %w", err)
+	}
+
+	return nil
+}
+
+// ActivityUsers は取り込み状況を保存しているユーザーIDを取得
+func (s *ActivityStore) ActivityUsers() ([]string, error) {
+	input := &dynamodb.ScanInput{
+		TableName:            aws.String(s.tables.IngestStates),
+		ProjectionExpression: aws.String("userId"),
+	}
+
+	users := make([]string, 0)
+	paginator := dynamodb.NewScanPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ingest states: %w", err)
+		}
+
+		var items []IngestStateItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ingest states: %w", err)
+		}
+		for _, item := range items {
+			users = append(users, item.UserID)
+		}
+	}
+
+	return users, nil
+}
+
+// deleteActivities は検索条件に一致する更新情報を削除
+func (s *ActivityStore) deleteActivities(input *dynamodb.QueryInput) error {
+	input.ProjectionExpression = aws.String("userId, activityId")
+
+	var requests []types.WriteRequest
+	paginator := dynamodb.NewQueryPaginator(s.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return fmt.Errorf("failed to query activities: %w", err)
+		}
+		for _, key := range output.Items {
+			requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+		}
+	}
+
+	if err := s.batchWrite(s.tables.Activities, requests); err != nil {
+		return fmt.Errorf("failed to delete activities: %w", err)
+	}
+	return nil
+}
+
+// batchWrite は書き込みをBatchWriteItemの上限ごとに分けて実行し、処理されなかった書き込みは再実行する
+func (s *ActivityStore) batchWrite(tableName string, requests []types.WriteRequest) error {
+	for len(requests) > 0 {
+		n := min(len(requests), batchWriteSize)
+		output, err := s.client.BatchWriteItem(context.TODO(), &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{tableName: requests[:n]},
+		})
+		if err != nil {
+			return err
+		}
+		unprocessed := output.UnprocessedItems[tableName]
+		if len(unprocessed) > 0 {
+			time.Sleep(batchRetryDelay)
+		}
+		requests = append(unprocessed, requests[n:]...)
+	}
+	return nil
+}
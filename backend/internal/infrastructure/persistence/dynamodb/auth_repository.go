@@ -0,0 +1,388 @@
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/encryption"
+)
+
+const (
+	// AuthTableName は認証情報を保存するDynamoDBのテーブル名
+	AuthTableName = "Auth"
+	// IndexNameSessionUserID はユーザーIDによるセッション検索用のグローバルセカンダリインデックス名
+	IndexNameSessionUserID = "SessionUserID-index"
+	// AuthTTLAttribute は期限切れ項目を自動削除するための既定のTTL属性名（Config.TTLAttributeで変更できる）
+	AuthTTLAttribute = "ttl"
+
+	tokenKeyPrefix   = "TOKEN#"
+	sessionKeyPrefix = "SESSION#"
+	codeKeyPrefix    = "CODE#"
+	stateKeyPrefix   = "STATE#"
+)
+
+// AuthItem はDynamoDBに保存するための認証情報アイテム構造体
+// トークン・セッション・交換コード・OAuth stateを1つのテーブルにキーの接頭辞で区別して保存する
+// TTLは設定した属性名で保存するため、保存時に追加する
+type AuthItem struct {
+	PK            string `dynamodbav:"pk"`
+	UserID        string `dynamodbav:"userId,omitempty"`
+	SessionUserID string `dynamodbav:"sessionUserId,omitempty"`
+	Data          []byte `dynamodbav:"data,omitempty"`
+	CreatedAt     int64  `dynamodbav:"createdAt,omitempty"`
+	ExpiresAt     int64  `dynamodbav:"expiresAt,omitempty"`
+	TTL           int64  `dynamodbav:"-"`
+}
+
+// AuthRepository はDynamoDBを使った認証リポジトリの実装
+// アクセストークン・リフレッシュトークン・code_verifierはエンベロープ暗号化して保存する
+type AuthRepository struct {
+	client       *dynamodb.Client
+	envelope     *encryption.Envelope
+	table        string
+	ttlAttribute string
+}
+
+// NewAuthRepository はAuthRepositoryのインスタンスを生成
+// ttlAttributeはテーブルのTTLに設定した属性名（空の場合はAuthTTLAttribute）
+func NewAuthRepository(client *dynamodb.Client, envelope *encryption.Envelope, tables TableNames, ttlAttribute string) *AuthRepository {
+	if ttlAttribute == "" {
+		ttlAttribute = AuthTTLAttribute
+	}
+	return &AuthRepository{
+		client:       client,
+		envelope:     envelope,
+		table:        tables.Auth,
+		ttlAttribute: ttlAttribute,
+	}
+}
+
+// SaveToken はトークンを暗号化して保存
+func (r *AuthRepository) SaveToken(token *model.AuthToken) error {
+	pk := tokenKeyPrefix + token.UserID
+	data, err := r.seal(pk, token)
+	if err != nil {
+		return err
+	}
+
+	return r.put(AuthItem{
+		PK:     pk,
+		UserID: token.UserID,
+		Data:   data,
+	})
+}
+
+// GetTokenByUserID はユーザーIDからトークンを取得
+func (r *AuthRepository) GetTokenByUserID(userID string) (*model.AuthToken, error) {
+	pk := tokenKeyPrefix + userID
+	item, err := r.get(pk)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, model.ErrTokenNotFound
+	}
+
+	// 別のユーザーの行からコピーされたなど、この行のものとして復号できないトークンは存在しないものとして扱う
+	var token model.AuthToken
+	if err := r.open(pk, item.Data, &token); errors.Is(err, encryption.ErrInvalidCiphertext) {
+		return nil, model.ErrTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeleteToken はトークンを削除
+func (r *AuthRepository) DeleteToken(userID string) error {
+	_, err := r.delete(tokenKeyPrefix + userID)
+	return err
+}
+
+// GetToken はユーザーIDからトークンを取得（GetTokenByUserIDのエイリアス）
+func (r *AuthRepository) GetToken(userID string) (*model.AuthToken, error) {
+	return r.GetTokenByUserID(userID)
+}
+
+// GetAllTokens は全トークンを取得
+func (r *AuthRepository) GetAllTokens() ([]*model.AuthToken, error) {
+	input := &dynamodb.ScanInput{
+		TableName:        aws.String(r.table),
+		FilterExpression: aws.String("begins_with(pk, :prefix)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":prefix": &types.AttributeValueMemberS{Value: tokenKeyPrefix},
+		},
+	}
+
+	tokens := make([]*model.AuthToken, 0)
+	paginator := dynamodb.NewScanPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tokens: %w", err)
+		}
+
+		var items []AuthItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tokens: %w", err)
+		}
+
+		for _, item := range items {
+			// 復号できないトークンは再ログインするまで使えないため対象外にする
+			var token model.AuthToken
+			if err := r.open(item.PK, item.Data, &token); errors.Is(err, encryption.ErrInvalidCiphertext) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, &token)
+		}
+	}
+
+	return tokens, nil
+}
+
+// SaveSession はセッションを保存
+func (r *AuthRepository) SaveSession(session *model.Session) error {
+	return r.put(AuthItem{
+		PK:            sessionKeyPrefix + session.ID,
+		UserID:        session.UserID,
+		SessionUserID: session.UserID,
+		CreatedAt:     session.CreatedAt.Unix(),
+		ExpiresAt:     session.ExpiresAt.Unix(),
+		TTL:           session.ExpiresAt.Unix(),
+	})
+}
+
+// GetSession はセッションIDからセッションを取得
+func (r *AuthRepository) GetSession(sessionID string) (*model.Session, error) {
+	item, err := r.get(sessionKeyPrefix + sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("session not found")
+	}
+
+	return &model.Session{
+		ID:        sessionID,
+		UserID:    item.UserID,
+		CreatedAt: time.Unix(item.CreatedAt, 0),
+		ExpiresAt: time.Unix(item.ExpiresAt, 0),
+	}, nil
+}
+
+// DeleteSession はセッションを削除
+func (r *AuthRepository) DeleteSession(sessionID string) error {
+	_, err := r.delete(sessionKeyPrefix + sessionID)
+	return err
+}
+
+// DeleteSessionsByUserID はユーザーの全セッションを削除
+func (r *AuthRepository) DeleteSessionsByUserID(userID string) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		IndexName:              aws.String(IndexNameSessionUserID),
+		KeyConditionExpression: aws.String("sessionUserId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userID},
+		},
+		ProjectionExpression: aws.String("pk"),
+	}
+
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return fmt.Errorf("failed to query sessions: %w", err)
+		}
+
+		for _, av := range output.Items {
+			var item AuthItem
+			if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+				return fmt.Errorf("failed to unmarshal session: %w", err)
+			}
+			if _, err := r.delete(item.PK); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SaveExchangeCode は交換コードを保存
+func (r *AuthRepository) SaveExchangeCode(code *model.ExchangeCode) error {
+	return r.put(AuthItem{
+		PK:        codeKeyPrefix + code.Code,
+		UserID:    code.UserID,
+		ExpiresAt: code.ExpiresAt.Unix(),
+		TTL:       code.ExpiresAt.Unix(),
+	})
+}
+
+// ConsumeExchangeCode は交換コードを削除し、削除前の内容を返す
+func (r *AuthRepository) ConsumeExchangeCode(code string) (*model.ExchangeCode, error) {
+	item, err := r.delete(codeKeyPrefix + code)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("exchange code not found")
+	}
+
+	return &model.ExchangeCode{
+		Code:      code,
+		UserID:    item.UserID,
+		ExpiresAt: time.Unix(item.ExpiresAt, 0),
+	}, nil
+}
+
+// SaveOAuthState はOAuthのstateを保存（code_verifierは暗号化）
+func (r *AuthRepository) SaveOAuthState(state *model.OAuthState) error {
+	pk := stateKeyPrefix + state.State
+	data, err := r.envelope.Seal([]byte(state.CodeVerifier), []byte(pk))
+	if err != nil {
+		return err
+	}
+
+	return r.put(AuthItem{
+		PK:        pk,
+		Data:      data,
+		ExpiresAt: state.ExpiresAt.Unix(),
+		TTL:       state.ExpiresAt.Unix(),
+	})
+}
+
+// ConsumeOAuthState はOAuthのstateを削除し、削除前の内容を返す
+func (r *AuthRepository) ConsumeOAuthState(state string) (*model.OAuthState, error) {
+	pk := stateKeyPrefix + state
+	item, err := r.delete(pk)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, errors.New("oauth state not found")
+	}
+
+	codeVerifier, err := r.envelope.Open(item.Data, []byte(pk))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OAuthState{
+		State:        state,
+		CodeVerifier: string(codeVerifier),
+		ExpiresAt:    time.Unix(item.ExpiresAt, 0),
+	}, nil
+}
+
+// put は項目を保存する内部メソッド
+func (r *AuthRepository) put(item AuthItem) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal auth item: %w", err)
+	}
+	if item.TTL != 0 {
+		av[r.ttlAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(item.TTL, 10)}
+	}
+
+	_, err = r.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.table),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save auth item %s: %w", keyType(item.PK), err)
+	}
+
+	return nil
+}
+
+// get は項目を取得する内部メソッド（存在しない場合はnilを返す）
+func (r *AuthRepository) get(pk string) (*AuthItem, error) {
+	output, err := r.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName:      aws.String(r.table),
+		Key:            authKey(pk),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get auth item %s: %w", keyType(pk), err)
+	}
+
+	if output.Item == nil {
+		return nil, nil
+	}
+
+	var item AuthItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal auth item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// delete は項目を削除し、削除前の項目を返す内部メソッド（存在しない場合はnilを返す）
+// DeleteItemの戻り値で取得と削除を1回の操作で行うため、交換コードやstateの再利用を防げる
+func (r *AuthRepository) delete(pk string) (*AuthItem, error) {
+	output, err := r.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName:    aws.String(r.table),
+		Key:          authKey(pk),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete auth item %s: %w", keyType(pk), err)
+	}
+
+	if output.Attributes == nil {
+		return nil, nil
+	}
+
+	var item AuthItem
+	if err := attributevalue.UnmarshalMap(output.Attributes, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal auth item: %w", err)
+	}
+
+	return &item, nil
+}
+
+// seal は値をJSONにしてエンベロープ暗号化（保存先のパーティションキーを関連データにする）
+func (r *AuthRepository) seal(pk string, v interface{}) ([]byte, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return r.envelope.Seal(plaintext, []byte(pk))
+}
+
+// open はエンベロープ暗号化された値を復号してJSONデコード（パーティションキーが暗号化時と異なる場合は失敗する）
+func (r *AuthRepository) open(pk string, data []byte, v interface{}) error {
+	plaintext, err := r.envelope.Open(data, []byte(pk))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// authKey はパーティションキーの属性値を生成
+func authKey(pk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: pk},
+	}
+}
+
+// keyType はエラーメッセージ用にキーの種別だけを返す（秘密の値をログに残さないため）
+func keyType(pk string) string {
+	prefix, _, _ := strings.Cut(pk, "#")
+	return strconv.Quote(strings.ToLower(prefix))
+}
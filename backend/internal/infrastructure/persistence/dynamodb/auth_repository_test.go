@@ -0,0 +1,148 @@
+package dynamodb
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/encryption"
+)
+
+// newLocalClient はDynamoDB Local用のクライアントを生成
+// DYNAMODB_ENDPOINT（例: http://localhost:8000）が設定されていない場合はテストをスキップする
+func newLocalClient(t *testing.T) *dynamodb.Client {
+	t.Helper()
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("DYNAMODB_ENDPOINT is not set; skipping DynamoDB Local test")
+	}
+
+	return dynamodb.New(dynamodb.Options{
+		Region:       "ap-northeast-1",
+		BaseEndpoint: aws.String(endpoint),
+		Credentials:  credentials.NewStaticCredentialsProvider("local", "local", ""),
+	})
+}
+
+// testConfig はDynamoDB Localのテスト用テーブルを作成する設定
+func testConfig() Config {
+	cfg := DefaultConfig("ap-northeast-1")
+	cfg.Endpoint = os.Getenv("DYNAMODB_ENDPOINT")
+	cfg.TablePrefix = "test-"
+	cfg.BillingMode = types.BillingModePayPerRequest
+	return cfg
+}
+
+func newTestAuthRepository(t *testing.T) *AuthRepository {
+	t.Helper()
+
+	client := newLocalClient(t)
+	if err := CreateAuthTable(client, testConfig()); err != nil {
+		t.Fatalf("Failed to create auth table: %v", err)
+	}
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	envelope, err := encryption.NewEnvelope(key)
+	if err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+
+	return NewAuthRepository(client, envelope, testConfig().Tables(), testConfig().TTLAttribute)
+}
+
+func TestAuthRepository_TokenRoundTrip(t *testing.T) {
+	repo := newTestAuthRepository(t)
+	userID := "test-user-" + time.Now().Format("150405.000000")
+
+	token := &model.AuthToken{
+		AccessToken:  "access",
+		TokenType:    "Bearer",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Hour).Truncate(time.Second),
+		UserID:       userID,
+	}
+	if err := repo.SaveToken(token); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	got, err := repo.GetTokenByUserID(userID)
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if got.AccessToken != "access" || got.RefreshToken != "refresh" || !got.ExpiresAt.Equal(token.ExpiresAt) {
+		t.Errorf("Unexpected token: %+v", got)
+	}
+
+	if err := repo.DeleteToken(userID); err != nil {
+		t.Fatalf("Failed to delete token: %v", err)
+	}
+	if _, err := repo.GetTokenByUserID(userID); err == nil {
+		t.Error("Expected error after deletion, but got nil")
+	}
+}
+
+func TestAuthRepository_SessionsAndOneTimeValues(t *testing.T) {
+	repo := newTestAuthRepository(t)
+	userID := "test-user-" + time.Now().Format("150405.000000")
+	expiresAt := time.Now().Add(time.Hour)
+
+	for _, id := range []string{userID + "-a", userID + "-b"} {
+		if err := repo.SaveSession(&model.Session{ID: id, UserID: userID, CreatedAt: time.Now(), ExpiresAt: expiresAt}); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+	if err := repo.DeleteSessionsByUserID(userID); err != nil {
+		t.Fatalf("Failed to delete sessions: %v", err)
+	}
+	if _, err := repo.GetSession(userID + "-a"); err == nil {
+		t.Error("Expected session to be deleted")
+	}
+
+	if err := repo.SaveOAuthState(&model.OAuthState{State: userID, CodeVerifier: "verifier", ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	state, err := repo.ConsumeOAuthState(userID)
+	if err != nil || state.CodeVerifier != "verifier" {
+		t.Fatalf("Failed to consume state: %v %+v", err, state)
+	}
+	if _, err := repo.ConsumeOAuthState(userID); err == nil {
+		t.Error("Expected consumed state to be unavailable")
+	}
+}
+
+// TTLは設定した属性名で保存されることを確認する
+func TestAuthRepository_TTLAttribute(t *testing.T) {
+	repo := newTestAuthRepository(t)
+	repo.ttlAttribute = "expiresAtTtl"
+	sessionID := "test-session-" + time.Now().Format("150405.000000")
+	expiresAt := time.Now().Add(time.Hour)
+
+	if err := repo.SaveSession(&model.Session{ID: sessionID, UserID: "user", CreatedAt: time.Now(), ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	output, err := repo.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(repo.table),
+		Key:       authKey(sessionKeyPrefix + sessionID),
+	})
+	if err != nil {
+		t.Fatalf("Failed to get session item: %v", err)
+	}
+	ttl, ok := output.Item["expiresAtTtl"].(*types.AttributeValueMemberN)
+	if !ok || ttl.Value != strconv.FormatInt(expiresAt.Unix(), 10) {
+		t.Errorf("Expected TTL in the configured attribute, got %+v", output.Item)
+	}
+	if _, exists := output.Item[AuthTTLAttribute]; exists {
+		t.Errorf("Expected no %q attribute, got %+v", AuthTTLAttribute, output.Item)
+	}
+}
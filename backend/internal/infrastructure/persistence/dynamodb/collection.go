@@ -0,0 +1,175 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+const (
+	// CollectionTableName はお気に入りコレクションを保存するDynamoDBのテーブル名
+	CollectionTableName = "FavoriteCollections"
+	// IndexNameUserID はコレクションテーブルとコレクションメンバーテーブルをユーザーIDで検索するためのグローバルセカンダリインデックス名
+	IndexNameUserID = "UserID-index"
+)
+
+// CollectionItem はDynamoDBに保存するためのコレクション構造体
+type CollectionItem struct {
+	ID          string    `dynamodbav:"id"`
+	UserID      string    `dynamodbav:"userId"`
+	Name        string    `dynamodbav:"name"`
+	Description string    `dynamodbav:"description,omitempty"`
+	CreatedAt   time.Time `dynamodbav:"createdAt"`
+	UpdatedAt   time.Time `dynamodbav:"updatedAt"`
+}
+
+// newCollectionItem はドメインモデルをDynamoDB項目に変換
+func newCollectionItem(collection *model.Collection) CollectionItem {
+	return CollectionItem{
+		ID:          collection.ID,
+		UserID:      collection.UserID,
+		Name:        collection.Name,
+		Description: collection.Description,
+		CreatedAt:   collection.CreatedAt,
+		UpdatedAt:   collection.UpdatedAt,
+	}
+}
+
+// toModel はDynamoDB項目をドメインモデルに変換
+func (item CollectionItem) toModel() *model.Collection {
+	return &model.Collection{
+		ID:          item.ID,
+		UserID:      item.UserID,
+		Name:        item.Name,
+		Description: item.Description,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
+}
+
+// SaveCollection はコレクションを保存
+func (r *FavoriteRepository) SaveCollection(collection *model.Collection) error {
+	av, err := attributevalue.MarshalMap(newCollectionItem(collection))
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection: %w", err)
+	}
+
+	_, err = r.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tables.Collections),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save collection: %w", err)
+	}
+
+	return nil
+}
+
+// FindCollection はIDからコレクションを取得
+func (r *FavoriteRepository) FindCollection(collectionID string) (*model.Collection, error) {
+	output, err := r.client.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(r.tables.Collections),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: collectionID},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if output.Item == nil {
+		return nil, model.ErrCollectionNotFound
+	}
+
+	var item CollectionItem
+	if err := attributevalue.UnmarshalMap(output.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal collection: %w", err)
+	}
+
+	return item.toModel(), nil
+}
+
+// FindCollectionsByUserID はユーザーIDからコレクションを検索
+func (r *FavoriteRepository) FindCollectionsByUserID(userID string) ([]*model.Collection, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tables.Collections),
+		IndexName:              aws.String(IndexNameUserID),
+		KeyConditionExpression: aws.String("userId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userID},
+		},
+	}
+
+	collections := make([]*model.Collection, 0)
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query collections: %w", err)
+		}
+
+		var items []CollectionItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal collections: %w", err)
+		}
+
+		for _, item := range items {
+			collections = append(collections, item.toModel())
+		}
+	}
+
+	return collections, nil
+}
+
+// DeleteCollection はコレクションを削除
+func (r *FavoriteRepository) DeleteCollection(collectionID string) error {
+	_, err := r.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tables.Collections),
+		Key: map[string]types.AttributeValue{
+			"id": &types.AttributeValueMemberS{Value: collectionID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// FindByCollectionID はコレクションに入っているお気に入りを検索
+func (r *FavoriteRepository) FindByCollectionID(collectionID string) ([]*model.Favorite, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tables.Favorites),
+		IndexName:              aws.String(IndexNameCollectionID),
+		KeyConditionExpression: aws.String("collectionId = :collectionId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":collectionId": &types.AttributeValueMemberS{Value: collectionID},
+		},
+	}
+
+	favorites := make([]*model.Favorite, 0)
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query favorites by collection: %w", err)
+		}
+
+		var items []FavoriteItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal favorites: %w", err)
+		}
+
+		for _, item := range items {
+			favorites = append(favorites, item.toModel())
+		}
+	}
+
+	return favorites, nil
+}
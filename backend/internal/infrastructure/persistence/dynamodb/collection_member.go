@@ -0,0 +1,148 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// CollectionMemberTableName は共有コレクションのメンバーを保存するDynamoDBのテーブル名
+const CollectionMemberTableName = "FavoriteCollectionMembers"
+
+// CollectionMemberItem はDynamoDBに保存するためのコレクションメンバー構造体
+// パーティションキーがcollectionId、ソートキーがuserId
+type CollectionMemberItem struct {
+	CollectionID string    `dynamodbav:"collectionId"`
+	UserID       string    `dynamodbav:"userId"`
+	Role         string    `dynamodbav:"role"`
+	AddedAt      time.Time `dynamodbav:"addedAt"`
+}
+
+// toModel はDynamoDB項目をドメインモデルに変換
+func (item CollectionMemberItem) toModel() *model.CollectionMember {
+	return &model.CollectionMember{
+		CollectionID: item.CollectionID,
+		UserID:       item.UserID,
+		Role:         model.CollectionRole(item.Role),
+		AddedAt:      item.AddedAt,
+	}
+}
+
+// SaveCollectionMember はコレクションのメンバーを保存
+func (r *FavoriteRepository) SaveCollectionMember(member *model.CollectionMember) error {
+	av, err := attributevalue.MarshalMap(CollectionMemberItem{
+		CollectionID: member.CollectionID,
+		UserID:       member.UserID,
+		Role:         string(member.Role),
+		AddedAt:      member.AddedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection member: %w", err)
+	}
+
+	_, err = r.client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(r.tables.CollectionMembers),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save collection member: %w", err)
+	}
+
+	return nil
+}
+
+// FindCollectionMembers はコレクションのメンバーを検索
+func (r *FavoriteRepository) FindCollectionMembers(collectionID string) ([]*model.CollectionMember, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tables.CollectionMembers),
+		KeyConditionExpression: aws.String("collectionId = :collectionId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":collectionId": &types.AttributeValueMemberS{Value: collectionID},
+		},
+	}
+
+	members := make([]*model.CollectionMember, 0)
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query collection members: %w", err)
+		}
+
+		var items []CollectionMemberItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &items); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal collection members: %w", err)
+		}
+
+		for _, item := range items {
+			members = append(members, item.toModel())
+		}
+	}
+
+	return members, nil
+}
+
+// FindCollectionsByMemberID はユーザーが共有されているコレクションを検索
+func (r *FavoriteRepository) FindCollectionsByMemberID(userID string) ([]*model.Collection, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.tables.CollectionMembers),
+		IndexName:              aws.String(IndexNameUserID),
+		KeyConditionExpression: aws.String("userId = :userId"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":userId": &types.AttributeValueMemberS{Value: userID},
+		},
+	}
+
+	var items []CollectionMemberItem
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query collection memberships: %w", err)
+		}
+
+		var pageItems []CollectionMemberItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &pageItems); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal collection memberships: %w", err)
+		}
+		items = append(items, pageItems...)
+	}
+
+	collections := make([]*model.Collection, 0, len(items))
+	for _, item := range items {
+		collection, err := r.FindCollection(item.CollectionID)
+		if errors.Is(err, model.ErrCollectionNotFound) {
+			// コレクションの削除中に残ったメンバー情報は無視する
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, collection)
+	}
+
+	return collections, nil
+}
+
+// DeleteCollectionMember はコレクションのメンバーを削除
+func (r *FavoriteRepository) DeleteCollectionMember(collectionID string, userID string) error {
+	_, err := r.client.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.tables.CollectionMembers),
+		Key: map[string]types.AttributeValue{
+			"collectionId": &types.AttributeValueMemberS{Value: collectionID},
+			"userId":       &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete collection member: %w", err)
+	}
+
+	return nil
+}
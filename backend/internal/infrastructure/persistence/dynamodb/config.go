@@ -0,0 +1,75 @@
+package dynamodb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Config はDynamoDBの接続先とテーブル作成の設定
+type Config struct {
+	// Region はDynamoDBのリージョン
+	Region string
+	// Endpoint はDynamoDB Localなど既定以外の接続先（例: http://localhost:8000）。空の場合はAWSの既定の接続先を使用する
+	Endpoint string
+	// TablePrefix はすべてのテーブル名の先頭に付ける接頭辞（例: "dev-"）
+	TablePrefix string
+	// BillingMode はテーブル作成時の課金モード（PAY_PER_REQUESTまたはPROVISIONED）
+	BillingMode types.BillingMode
+	// ReadCapacityUnits・WriteCapacityUnits はPROVISIONEDの場合のテーブルとインデックスの容量
+	ReadCapacityUnits  int64
+	WriteCapacityUnits int64
+	// EnableTTL は期限切れの認証情報をTTL属性で自動削除するか
+	EnableTTL bool
+	// TTLAttribute は認証情報テーブルで期限切れ項目を自動削除するためのTTL属性名
+	TTLAttribute string
+}
+
+// DefaultConfig は既定の設定（プロビジョンド 5/5、TTL属性ttlで有効）を返す
+func DefaultConfig(region string) Config {
+	return Config{
+		Region:             region,
+		BillingMode:        types.BillingModeProvisioned,
+		ReadCapacityUnits:  5,
+		WriteCapacityUnits: 5,
+		EnableTTL:          true,
+		TTLAttribute:       AuthTTLAttribute,
+	}
+}
+
+// ParseBillingMode は課金モードの設定値を検証して変換する。空の場合はPROVISIONEDとする
+func ParseBillingMode(value string) (types.BillingMode, error) {
+	switch strings.ToUpper(value) {
+	case "", string(types.BillingModeProvisioned):
+		return types.BillingModeProvisioned, nil
+	case string(types.BillingModePayPerRequest):
+		return types.BillingModePayPerRequest, nil
+	default:
+		return "", fmt.Errorf("unknown billing mode %q (expected %s or %s)", value, types.BillingModePayPerRequest, types.BillingModeProvisioned)
+	}
+}
+
+// Tables は接頭辞を付けた各テーブル名を返す
+func (c Config) Tables() TableNames {
+	return TableNames{
+		Favorites:         c.TablePrefix + FavoriteTableName,
+		LegacyFavorites:   c.TablePrefix + LegacyFavoriteTableName,
+		Collections:       c.TablePrefix + CollectionTableName,
+		CollectionMembers: c.TablePrefix + CollectionMemberTableName,
+		Auth:              c.TablePrefix + AuthTableName,
+		Activities:        c.TablePrefix + ActivityTableName,
+		IngestStates:      c.TablePrefix + IngestStateTableName,
+	}
+}
+
+// TableNames はリポジトリが使用するテーブル名
+type TableNames struct {
+	Favorites         string
+	LegacyFavorites   string
+	Collections       string
+	CollectionMembers string
+	Auth              string
+	Activities        string
+	IngestStates      string
+}
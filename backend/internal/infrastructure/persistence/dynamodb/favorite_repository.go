@@ -2,7 +2,11 @@ package dynamodb
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,97 +17,307 @@ import (
 )
 
 const (
-	// FavoriteTableName はDynamoDBのテーブル名
-	FavoriteTableName = "Favorites"
-	// IndexNameUserID はユーザーIDによる検索用のグローバルセカンダリインデックス名
-	IndexNameUserID = "UserID-index"
+	// FavoriteTableName はお気に入りを保存するDynamoDBのテーブル名
+	// パーティションキーがuserId、ソートキーがitemIdで、同じ更新情報のお気に入りは1件しか保存されない
+	FavoriteTableName = "UserFavorites"
+	// LegacyFavoriteTableName はランダムなIDをキーにしていた移行前のお気に入りテーブル名
+	LegacyFavoriteTableName = "Favorites"
+	// IndexNameCreatedAt はユーザーのお気に入りを登録日時順に取得するためのローカルセカンダリインデックス名
+	IndexNameCreatedAt = "CreatedAt-index"
+	// IndexNameCollectionID はコレクションIDによる検索用のグローバルセカンダリインデックス名
+	// コレクションに入っているお気に入りだけが含まれる
+	IndexNameCollectionID = "CollectionID-index"
 )
 
 // FavoriteItem はDynamoDBに保存するためのお気に入りアイテム構造体
 type FavoriteItem struct {
-	ID        string    `dynamodbav:"id"`
-	UserID    string    `dynamodbav:"userId"`
-	ItemID    string    `dynamodbav:"itemId"`
-	CreatedAt time.Time `dynamodbav:"createdAt"`
+	ID        string                `dynamodbav:"id"`
+	UserID    string                `dynamodbav:"userId"`
+	ItemID    string                `dynamodbav:"itemId"`
+	CreatedAt time.Time             `dynamodbav:"createdAt"`
+	Snapshot  *FavoriteSnapshotItem `dynamodbav:"snapshot,omitempty"`
+
+	// CreatedAtKey は登録日時順に並べるための固定長のUTC文字列（createdAtは末尾の0が省略され文字列順にならない）
+	CreatedAtKey string `dynamodbav:"createdAtKey"`
+
+	CollectionID string   `dynamodbav:"collectionId,omitempty"`
+	Tags         []string `dynamodbav:"tags,omitempty"`
+	Note         string   `dynamodbav:"note,omitempty"`
+}
+
+// FavoriteSnapshotItem はお気に入りのスナップショットをDynamoDBのマップ属性として保存するための構造体
+type FavoriteSnapshotItem struct {
+	ProjectID      string    `dynamodbav:"projectId"`
+	ProjectKey     string    `dynamodbav:"projectKey"`
+	ProjectName    string    `dynamodbav:"projectName"`
+	TypeID         int       `dynamodbav:"typeId"`
+	Type           string    `dynamodbav:"type"`
+	IssueKey       string    `dynamodbav:"issueKey,omitempty"`
+	IssueStatus    string    `dynamodbav:"issueStatus,omitempty"`
+	ContentSummary string    `dynamodbav:"contentSummary"`
+	CreatedUser    UserItem  `dynamodbav:"createdUser"`
+	Created        time.Time `dynamodbav:"created"`
+	URL            string    `dynamodbav:"url"`
+	RefreshedAt    time.Time `dynamodbav:"refreshedAt"`
+}
+
+// UserItem はBacklogのユーザー情報をDynamoDBのマップ属性として保存するための構造体
+type UserItem struct {
+	ID          string `dynamodbav:"id"`
+	Name        string `dynamodbav:"name"`
+	RoleType    int    `dynamodbav:"roleType"`
+	Lang        string `dynamodbav:"lang,omitempty"`
+	MailAddress string `dynamodbav:"mailAddress,omitempty"`
+}
+
+// newFavoriteItem はドメインモデルをDynamoDB項目に変換
+func newFavoriteItem(favorite *model.Favorite) FavoriteItem {
+	item := FavoriteItem{
+		ID:        favorite.ID,
+		UserID:    favorite.UserID,
+		ItemID:    favorite.ItemID,
+		CreatedAt: favorite.CreatedAt,
+
+		CreatedAtKey: createdAtKey(favorite.CreatedAt),
+
+		CollectionID: favorite.CollectionID,
+		Tags:         favorite.Tags,
+		Note:         favorite.Note,
+	}
+
+	if snapshot := favorite.Snapshot; snapshot != nil {
+		item.Snapshot = &FavoriteSnapshotItem{
+			ProjectID:      snapshot.ProjectID,
+			ProjectKey:     snapshot.ProjectKey,
+			ProjectName:    snapshot.ProjectName,
+			TypeID:         snapshot.TypeID,
+			Type:           snapshot.Type,
+			IssueKey:       snapshot.IssueKey,
+			IssueStatus:    snapshot.IssueStatus,
+			ContentSummary: snapshot.ContentSummary,
+			CreatedUser: UserItem{
+				ID:          snapshot.CreatedUser.ID,
+				Name:        snapshot.CreatedUser.Name,
+				RoleType:    snapshot.CreatedUser.RoleType,
+				Lang:        snapshot.CreatedUser.Lang,
+				MailAddress: snapshot.CreatedUser.MailAddress,
+			},
+			Created:     snapshot.Created,
+			URL:         snapshot.URL,
+			RefreshedAt: snapshot.RefreshedAt,
+		}
+	}
+
+	return item
+}
+
+// toModel はDynamoDB項目をドメインモデルに変換
+func (item FavoriteItem) toModel() *model.Favorite {
+	favorite := &model.Favorite{
+		ID:        item.ID,
+		UserID:    item.UserID,
+		ItemID:    item.ItemID,
+		CreatedAt: item.CreatedAt,
+
+		CollectionID: item.CollectionID,
+		Tags:         item.Tags,
+		Note:         item.Note,
+	}
+
+	if snapshot := item.Snapshot; snapshot != nil {
+		favorite.Snapshot = &model.FavoriteSnapshot{
+			ProjectID:      snapshot.ProjectID,
+			ProjectKey:     snapshot.ProjectKey,
+			ProjectName:    snapshot.ProjectName,
+			TypeID:         snapshot.TypeID,
+			Type:           snapshot.Type,
+			IssueKey:       snapshot.IssueKey,
+			IssueStatus:    snapshot.IssueStatus,
+			ContentSummary: snapshot.ContentSummary,
+			CreatedUser: model.User{
+				ID:          snapshot.CreatedUser.ID,
+				Name:        snapshot.CreatedUser.Name,
+				RoleType:    snapshot.CreatedUser.RoleType,
+				Lang:        snapshot.CreatedUser.Lang,
+				MailAddress: snapshot.CreatedUser.MailAddress,
+			},
+			Created:     snapshot.Created,
+			URL:         snapshot.URL,
+			RefreshedAt: snapshot.RefreshedAt,
+		}
+	}
+
+	return favorite
+}
+
+// createdAtKeyLayout はcreatedAtKeyの形式。文字列順が時刻順と一致するよう小数部を固定長にする
+const createdAtKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// createdAtKey は登録日時をcreatedAtKeyの形式に変換
+func createdAtKey(t time.Time) string {
+	return t.UTC().Format(createdAtKeyLayout)
+}
+
+// favoriteKey はユーザーIDと更新情報IDからお気に入りの主キーを作成
+func favoriteKey(userID string, itemID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"userId": &types.AttributeValueMemberS{Value: userID},
+		"itemId": &types.AttributeValueMemberS{Value: itemID},
+	}
 }
 
 // FavoriteRepository はDynamoDBを使ったお気に入りリポジトリの実装
 type FavoriteRepository struct {
 	client *dynamodb.Client
+	tables TableNames
 }
 
 // NewFavoriteRepository はFavoriteRepositoryのインスタンスを生成
-func NewFavoriteRepository(client *dynamodb.Client) *FavoriteRepository {
+func NewFavoriteRepository(client *dynamodb.Client, tables TableNames) *FavoriteRepository {
 	return &FavoriteRepository{
 		client: client,
+		tables: tables,
 	}
 }
 
-// FindByUserID はユーザーIDからお気に入りを検索
+// FindByUserID はユーザーIDからお気に入りを登録日時の新しい順に検索
 func (r *FavoriteRepository) FindByUserID(userID string) ([]*model.Favorite, error) {
-	// GSIを使用してユーザーIDでクエリ
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String(FavoriteTableName),
-		IndexName:              aws.String(IndexNameUserID),
+		TableName:              aws.String(r.tables.Favorites),
+		IndexName:              aws.String(IndexNameCreatedAt),
 		KeyConditionExpression: aws.String("userId = :userId"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":userId": &types.AttributeValueMemberS{Value: userID},
 		},
+		ScanIndexForward: aws.Bool(false),
 	}
 
-	output, err := r.client.Query(context.TODO(), input)
+	favorites := make([]*model.Favorite, 0)
+	paginator := dynamodb.NewQueryPaginator(r.client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return nil, fmt.Errorf("failed to query favorites: %w", err)
+		}
+
+		var favoriteItems []FavoriteItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &favoriteItems); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal favorites: %w", err)
+		}
+
+		for _, item := range favoriteItems {
+			favorites = append(favorites, item.toModel())
+		}
+	}
+
+	return favorites, nil
+}
+
+// favoriteCursor はお気に入りのページングのカーソルに含める、前のページの最後のお気に入りのキー
+type favoriteCursor struct {
+	ItemID       string `json:"itemId"`
+	CreatedAtKey string `json:"createdAtKey"`
+}
+
+// encodeFavoriteCursor はお気に入りのキーを不透明なカーソルに変換
+func encodeFavoriteCursor(item FavoriteItem) string {
+	b, _ := json.Marshal(favoriteCursor{ItemID: item.ItemID, CreatedAtKey: item.CreatedAtKey})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeFavoriteCursor はカーソルをCreatedAt-indexのExclusiveStartKeyに変換。空のカーソルは先頭を表す
+func decodeFavoriteCursor(userID string, cursor string) (map[string]types.AttributeValue, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query favorites: %w", err)
+		return nil, model.ErrInvalidCursor
 	}
 
-	if output.Count == 0 {
-		return []*model.Favorite{}, nil
+	var c favoriteCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ItemID == "" || c.CreatedAtKey == "" {
+		return nil, model.ErrInvalidCursor
 	}
 
-	// DynamoDB項目をドメインモデルに変換
-	var favoriteItems []FavoriteItem
-	err = attributevalue.UnmarshalListOfMaps(output.Items, &favoriteItems)
+	key := favoriteKey(userID, c.ItemID)
+	key["createdAtKey"] = &types.AttributeValueMemberS{Value: c.CreatedAtKey}
+	return key, nil
+}
+
+// FindPageByUserID はユーザーIDからお気に入りを登録日時の新しい順に1ページ分検索
+// 1回のQueryの読み込み上限（1MB）に達した場合も、1ページ分が揃うまで続きを読み込む
+func (r *FavoriteRepository) FindPageByUserID(userID string, page model.PageRequest) (*model.FavoritePage, error) {
+	startKey, err := decodeFavoriteCursor(userID, page.Cursor)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal favorites: %w", err)
+		return nil, err
 	}
 
-	// ドメインモデルに変換
-	favorites := make([]*model.Favorite, len(favoriteItems))
-	for i, item := range favoriteItems {
-		favorites[i] = &model.Favorite{
-			ID:        item.ID,
-			UserID:    item.UserID,
-			ItemID:    item.ItemID,
-			CreatedAt: item.CreatedAt,
+	// 次のページの有無を判定するため1件多く読み込む
+	size := page.Size()
+	favoriteItems := make([]FavoriteItem, 0, size+1)
+	for len(favoriteItems) <= size {
+		output, err := r.client.Query(context.TODO(), &dynamodb.QueryInput{
+			TableName:              aws.String(r.tables.Favorites),
+			IndexName:              aws.String(IndexNameCreatedAt),
+			KeyConditionExpression: aws.String("userId = :userId"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":userId": &types.AttributeValueMemberS{Value: userID},
+			},
+			ScanIndexForward:  aws.Bool(false),
+			Limit:             aws.Int32(int32(size + 1 - len(favoriteItems))),
+			ExclusiveStartKey: startKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query favorites: %w", err)
+		}
+
+		var pageItems []FavoriteItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &pageItems); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal favorites: %w", err)
 		}
+		favoriteItems = append(favoriteItems, pageItems...)
+
+		if len(output.LastEvaluatedKey) == 0 {
+			break
+		}
+		startKey = output.LastEvaluatedKey
 	}
 
-	return favorites, nil
+	result := &model.FavoritePage{}
+	if len(favoriteItems) > size {
+		favoriteItems = favoriteItems[:size]
+		result.NextCursor = encodeFavoriteCursor(favoriteItems[size-1])
+	}
+
+	result.Favorites = make([]*model.Favorite, len(favoriteItems))
+	for i, item := range favoriteItems {
+		result.Favorites[i] = item.toModel()
+	}
+
+	return result, nil
 }
 
 // Save はお気に入りを保存
+// 同じ更新情報のお気に入りが既にある場合は上書きせずにErrFavoriteAlreadyExistsを返す
 func (r *FavoriteRepository) Save(favorite *model.Favorite) error {
-	// ドメインモデルをDynamoDB項目に変換
-	item := FavoriteItem{
-		ID:        favorite.ID,
-		UserID:    favorite.UserID,
-		ItemID:    favorite.ItemID,
-		CreatedAt: favorite.CreatedAt,
-	}
-
-	// 項目をマーシャリング
-	av, err := attributevalue.MarshalMap(item)
+	av, err := attributevalue.MarshalMap(newFavoriteItem(favorite))
 	if err != nil {
 		return fmt.Errorf("failed to marshal favorite: %w", err)
 	}
 
-	// DynamoDBに保存
 	input := &dynamodb.PutItemInput{
-		TableName: aws.String(FavoriteTableName),
-		Item:      av,
+		TableName:           aws.String(r.tables.Favorites),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(itemId)"),
 	}
 
 	_, err = r.client.PutItem(context.TODO(), input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return model.ErrFavoriteAlreadyExists
+	}
 	if err != nil {
 		return fmt.Errorf("failed to save favorite: %w", err)
 	}
@@ -111,87 +325,132 @@ func (r *FavoriteRepository) Save(favorite *model.Favorite) error {
 	return nil
 }
 
-// Delete はお気に入りを削除
-func (r *FavoriteRepository) Delete(userID string, itemID string) error {
-	// 最初に一致するアイテムを探してIDを取得
-	existingItems, err := r.findByUserIDAndItemID(userID, itemID)
+// Update はお気に入りのスナップショットを更新
+func (r *FavoriteRepository) Update(favorite *model.Favorite) error {
+	av, err := attributevalue.Marshal(newFavoriteItem(favorite).Snapshot)
 	if err != nil {
-		return fmt.Errorf("failed to find favorite for deletion: %w", err)
+		return fmt.Errorf("failed to marshal favorite snapshot: %w", err)
 	}
 
-	if len(existingItems) == 0 {
-		// 削除するお気に入りが見つからない場合はエラーを返さずに成功とする
-		return nil
-	}
-
-	// 一致する最初のアイテムを削除（通常は1つだけのはず）
-	input := &dynamodb.DeleteItemInput{
-		TableName: aws.String(FavoriteTableName),
-		Key: map[string]types.AttributeValue{
-			"id": &types.AttributeValueMemberS{Value: existingItems[0].ID},
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tables.Favorites),
+		Key:                 favoriteKey(favorite.UserID, favorite.ItemID),
+		UpdateExpression:    aws.String("SET snapshot = :snapshot"),
+		ConditionExpression: aws.String("attribute_exists(itemId)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":snapshot": av,
 		},
 	}
 
-	_, err = r.client.DeleteItem(context.TODO(), input)
+	_, err = r.client.UpdateItem(context.TODO(), input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		// 更新中に削除されたお気に入りは再作成しない
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete favorite: %w", err)
+		return fmt.Errorf("failed to update favorite: %w", err)
 	}
 
 	return nil
 }
 
-// Exists はお気に入りが存在するかチェック
-func (r *FavoriteRepository) Exists(userID string, itemID string) (bool, error) {
-	existingItems, err := r.findByUserIDAndItemID(userID, itemID)
+// UpdateAnnotations はお気に入りのコレクション・タグ・メモを更新
+func (r *FavoriteRepository) UpdateAnnotations(favorite *model.Favorite) error {
+	// 空の値は属性ごと削除し、未分類・タグなし・メモなしの状態に戻す
+	var set, remove []string
+	values := make(map[string]types.AttributeValue)
+	if favorite.CollectionID != "" {
+		set = append(set, "collectionId = :collectionId")
+		values[":collectionId"] = &types.AttributeValueMemberS{Value: favorite.CollectionID}
+	} else {
+		remove = append(remove, "collectionId")
+	}
+	if len(favorite.Tags) > 0 {
+		set = append(set, "tags = :tags")
+		values[":tags"] = &types.AttributeValueMemberL{Value: stringList(favorite.Tags)}
+	} else {
+		remove = append(remove, "tags")
+	}
+	if favorite.Note != "" {
+		set = append(set, "note = :note")
+		values[":note"] = &types.AttributeValueMemberS{Value: favorite.Note}
+	} else {
+		remove = append(remove, "note")
+	}
+
+	var expression []string
+	if len(set) > 0 {
+		expression = append(expression, "SET "+strings.Join(set, ", "))
+	}
+	if len(remove) > 0 {
+		expression = append(expression, "REMOVE "+strings.Join(remove, ", "))
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:           aws.String(r.tables.Favorites),
+		Key:                 favoriteKey(favorite.UserID, favorite.ItemID),
+		UpdateExpression:    aws.String(strings.Join(expression, " ")),
+		ConditionExpression: aws.String("attribute_exists(itemId)"),
+	}
+	if len(values) > 0 {
+		input.ExpressionAttributeValues = values
+	}
+
+	_, err := r.client.UpdateItem(context.TODO(), input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return model.ErrFavoriteNotFound
+	}
 	if err != nil {
-		return false, err
+		return fmt.Errorf("failed to update favorite annotations: %w", err)
 	}
 
-	return len(existingItems) > 0, nil
+	return nil
 }
 
-// findByUserIDAndItemID はユーザーIDとアイテムIDの両方に一致するお気に入りを検索する内部メソッド
-func (r *FavoriteRepository) findByUserIDAndItemID(userID string, itemID string) ([]*model.Favorite, error) {
-	// ユーザーIDでクエリを実行
-	input := &dynamodb.QueryInput{
-		TableName:              aws.String(FavoriteTableName),
-		IndexName:              aws.String(IndexNameUserID),
-		KeyConditionExpression: aws.String("userId = :userId"),
-		FilterExpression:       aws.String("itemId = :itemId"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":userId": &types.AttributeValueMemberS{Value: userID},
-			":itemId": &types.AttributeValueMemberS{Value: itemID},
-		},
+// Delete はお気に入りを削除
+// 削除するお気に入りがない場合は条件付き削除が失敗し、ErrFavoriteNotFoundを返す
+func (r *FavoriteRepository) Delete(userID string, itemID string) error {
+	input := &dynamodb.DeleteItemInput{
+		TableName:           aws.String(r.tables.Favorites),
+		Key:                 favoriteKey(userID, itemID),
+		ConditionExpression: aws.String("attribute_exists(itemId)"),
 	}
 
-	// クエリ実行
-	output, err := r.client.Query(context.TODO(), input)
+	_, err := r.client.DeleteItem(context.TODO(), input)
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return model.ErrFavoriteNotFound
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to query favorites: %w", err)
+		return fmt.Errorf("failed to delete favorite: %w", err)
 	}
 
-	// 結果がない場合は空のスライスを返す
-	if output.Count == 0 {
-		return []*model.Favorite{}, nil
+	return nil
+}
+
+// Exists はお気に入りが存在するかチェック
+func (r *FavoriteRepository) Exists(userID string, itemID string) (bool, error) {
+	input := &dynamodb.GetItemInput{
+		TableName:            aws.String(r.tables.Favorites),
+		Key:                  favoriteKey(userID, itemID),
+		ProjectionExpression: aws.String("itemId"),
 	}
 
-	// DynamoDB項目をドメインモデルに変換
-	var favoriteItems []FavoriteItem
-	err = attributevalue.UnmarshalListOfMaps(output.Items, &favoriteItems)
+	output, err := r.client.GetItem(context.TODO(), input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal favorites: %w", err)
+		return false, fmt.Errorf("failed to get favorite: %w", err)
 	}
 
-	// ドメインモデルに変換
-	favorites := make([]*model.Favorite, len(favoriteItems))
-	for i, item := range favoriteItems {
-		favorites[i] = &model.Favorite{
-			ID:        item.ID,
-			UserID:    item.UserID,
-			ItemID:    item.ItemID,
-			CreatedAt: item.CreatedAt,
-		}
-	}
+	return output.Item != nil, nil
+}
 
-	return favorites, nil
+// stringList は文字列のスライスをDynamoDBのリスト属性に変換
+func stringList(values []string) []types.AttributeValue {
+	list := make([]types.AttributeValue, len(values))
+	for i, value := range values {
+		list[i] = &types.AttributeValueMemberS{Value: value}
+	}
+	return list
 }
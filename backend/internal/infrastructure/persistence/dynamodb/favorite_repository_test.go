@@ -0,0 +1,171 @@
+package dynamodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+func TestFavoriteRepository_CompositeKey(t *testing.T) {
+	client := newLocalClient(t)
+	if err := CreateFavoriteTable(client, testConfig()); err != nil {
+		t.Fatalf("Failed to create favorite table: %v", err)
+	}
+	repo := NewFavoriteRepository(client, testConfig().Tables())
+	userID := "test-user-" + time.Now().Format("150405.000000")
+
+	now := time.Now()
+	for i, itemID := range []string{"1", "2", "3"} {
+		favorite := &model.Favorite{ID: itemID, UserID: userID, ItemID: itemID, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := repo.Save(favorite); err != nil {
+			t.Fatalf("Failed to save favorite: %v", err)
+		}
+	}
+
+	// 同じ更新情報を再度保存すると重複エラーになり、登録日時も上書きされない
+	if err := repo.Save(&model.Favorite{ID: "dup", UserID: userID, ItemID: "1", CreatedAt: now.Add(time.Hour)}); err != model.ErrFavoriteAlreadyExists {
+		t.Fatalf("Expected ErrFavoriteAlreadyExists, got %v", err)
+	}
+
+	favorites, err := repo.FindByUserID(userID)
+	if err != nil {
+		t.Fatalf("Failed to find favorites: %v", err)
+	}
+	if len(favorites) != 3 || favorites[0].ItemID != "3" || favorites[2].ItemID != "1" {
+		t.Fatalf("Expected 3 favorites in newest-first order, got %+v", favorites)
+	}
+
+	// 1件ずつページングしても漏れなく取得できる
+	var paged []string
+	page := model.PageRequest{Limit: 1}
+	for {
+		result, err := repo.FindPageByUserID(userID, page)
+		if err != nil {
+			t.Fatalf("Failed to find favorite page: %v", err)
+		}
+		for _, favorite := range result.Favorites {
+			paged = append(paged, favorite.ItemID)
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		page.Cursor = result.NextCursor
+	}
+	if len(paged) != 3 || paged[0] != "3" || paged[2] != "1" {
+		t.Errorf("Expected 3 favorites across pages, got %v", paged)
+	}
+	if _, err := repo.FindPageByUserID(userID, model.PageRequest{Cursor: "invalid"}); err != model.ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+
+	if err := repo.Delete(userID, "2"); err != nil {
+		t.Fatalf("Failed to delete favorite: %v", err)
+	}
+	if exists, _ := repo.Exists(userID, "2"); exists {
+		t.Error("Expected favorite to be deleted")
+	}
+	if err := repo.UpdateAnnotations(&model.Favorite{UserID: userID, ItemID: "2", Note: "memo"}); err != model.ErrFavoriteNotFound {
+		t.Errorf("Expected ErrFavoriteNotFound, got %v", err)
+	}
+}
+
+func TestMigrateLegacyFavorites(t *testing.T) {
+	client := newLocalClient(t)
+	if err := CreateFavoriteTable(client, testConfig()); err != nil {
+		t.Fatalf("Failed to create favorite table: %v", err)
+	}
+	createLegacyFavoriteTable(t, client)
+
+	userID := "legacy-user-" + time.Now().Format("150405.000000")
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, legacy := range []FavoriteItem{
+		{ID: "a", UserID: userID, ItemID: "1", CreatedAt: created},
+		{ID: "b", UserID: userID, ItemID: "1", CreatedAt: created.Add(time.Minute)},
+		{ID: "c", UserID: userID, ItemID: "2", CreatedAt: created, Note: "memo"},
+	} {
+		av, _ := attributevalue.MarshalMap(legacy)
+		if _, err := client.PutItem(context.TODO(), &dynamodb.PutItemInput{TableName: aws.String(testConfig().Tables().LegacyFavorites), Item: av}); err != nil {
+			t.Fatalf("Failed to put legacy favorite: %v", err)
+		}
+	}
+
+	if _, err := MigrateLegacyFavorites(client, testConfig().Tables(), false); err != nil {
+		t.Fatalf("Failed to migrate favorites: %v", err)
+	}
+	// 繰り返し実行しても項目は増えない
+	if _, err := MigrateLegacyFavorites(client, testConfig().Tables(), false); err != nil {
+		t.Fatalf("Failed to migrate favorites again: %v", err)
+	}
+
+	favorites, err := NewFavoriteRepository(client, testConfig().Tables()).FindByUserID(userID)
+	if err != nil {
+		t.Fatalf("Failed to find favorites: %v", err)
+	}
+	if len(favorites) != 2 {
+		t.Fatalf("Expected duplicates to be merged into 2 favorites, got %+v", favorites)
+	}
+}
+
+// createLegacyFavoriteTable は移行前のレイアウト（idをパーティションキーとするテーブル）を作成
+func createLegacyFavoriteTable(t *testing.T, client *dynamodb.Client) {
+	t.Helper()
+
+	_, err := client.DescribeTable(context.TODO(), &dynamodb.DescribeTableInput{TableName: aws.String(testConfig().Tables().LegacyFavorites)})
+	if err == nil {
+		return
+	}
+
+	_, err = client.CreateTable(context.TODO(), &dynamodb.CreateTableInput{
+		TableName:            aws.String(testConfig().Tables().LegacyFavorites),
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeS}},
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash}},
+		BillingMode:          types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create legacy favorite table: %v", err)
+	}
+}
+
+// createdAtKeyの文字列順が登録日時の順序と一致することを確認する
+func TestCreatedAtKey_Ordering(t *testing.T) {
+	base := time.Date(2024, 4, 1, 9, 0, 5, 100_000_000, time.FixedZone("JST", 9*60*60))
+	later := base.Add(20 * time.Millisecond)
+
+	if !(createdAtKey(base) < createdAtKey(later)) {
+		t.Errorf("Expected %s < %s", createdAtKey(base), createdAtKey(later))
+	}
+	if createdAtKey(base) != "2024-04-01T00:00:05.100000000Z" {
+		t.Errorf("Unexpected key: %s", createdAtKey(base))
+	}
+}
+
+// スナップショットの作成者の全てのフィールドがDynamoDBの項目を経由しても失われないことを確認する
+func TestFavoriteItem_SnapshotCreatedUser(t *testing.T) {
+	user := model.User{ID: "100", Name: "山田太郎", RoleType: 2, Lang: "ja", MailAddress: "yamada@example.com"}
+	favorite := &model.Favorite{
+		ID:        "fav-1",
+		UserID:    "user1",
+		ItemID:    "1",
+		CreatedAt: time.Now(),
+		Snapshot:  &model.FavoriteSnapshot{ContentSummary: "ログイン機能の実装", CreatedUser: user},
+	}
+
+	av, err := attributevalue.MarshalMap(newFavoriteItem(favorite))
+	if err != nil {
+		t.Fatalf("Failed to marshal favorite: %v", err)
+	}
+	var item FavoriteItem
+	if err := attributevalue.UnmarshalMap(av, &item); err != nil {
+		t.Fatalf("Failed to unmarshal favorite: %v", err)
+	}
+
+	if got := item.toModel().Snapshot.CreatedUser; got != user {
+		t.Errorf("Expected created user %+v, got %+v", user, got)
+	}
+}
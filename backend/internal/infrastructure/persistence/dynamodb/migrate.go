@@ -0,0 +1,88 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// MigrationResult はお気に入りテーブルの移行結果
+type MigrationResult struct {
+	// Scanned は移行前のテーブルから読み込んだ項目数
+	Scanned int
+	// Copied は新しいテーブルにコピーした項目数
+	Copied int
+	// Skipped は新しいテーブルに既に存在したためコピーしなかった項目数
+	Skipped int
+}
+
+// MigrateLegacyFavorites は移行前のお気に入りテーブル（tables.LegacyFavorites）の全項目を
+// ユーザーIDと更新情報IDをキーとするお気に入りテーブル（tables.Favorites）にコピーする
+// 既に存在する項目は上書きしないため繰り返し実行でき、同じ更新情報の重複したお気に入りは最初にコピーした1件だけが残る
+// dryRunがtrueの場合は読み込みのみ行い、書き込まない
+func MigrateLegacyFavorites(client *dynamodb.Client, tables TableNames, dryRun bool) (*MigrationResult, error) {
+	result := &MigrationResult{}
+
+	paginator := dynamodb.NewScanPaginator(client, &dynamodb.ScanInput{
+		TableName: aws.String(tables.LegacyFavorites),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.TODO())
+		if err != nil {
+			return result, fmt.Errorf("failed to scan legacy favorites: %w", err)
+		}
+
+		var legacyItems []FavoriteItem
+		if err := attributevalue.UnmarshalListOfMaps(output.Items, &legacyItems); err != nil {
+			return result, fmt.Errorf("failed to unmarshal legacy favorites: %w", err)
+		}
+
+		for _, legacy := range legacyItems {
+			result.Scanned++
+			if dryRun {
+				continue
+			}
+
+			copied, err := copyFavorite(client, tables, legacy)
+			if err != nil {
+				return result, err
+			}
+			if copied {
+				result.Copied++
+			} else {
+				result.Skipped++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// copyFavorite は移行前のお気に入り項目を新しいテーブルの形式に変換して保存
+// 新しいテーブルに既に存在する場合はfalseを返す
+func copyFavorite(client *dynamodb.Client, tables TableNames, legacy FavoriteItem) (bool, error) {
+	av, err := attributevalue.MarshalMap(newFavoriteItem(legacy.toModel()))
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal favorite: %w", err)
+	}
+
+	_, err = client.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName:           aws.String(tables.Favorites),
+		Item:                av,
+		ConditionExpression: aws.String("attribute_not_exists(itemId)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to copy favorite %s: %w", legacy.ID, err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,35 @@
+package dynamodb
+
+import (
+	"testing"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/repotest"
+)
+
+// DynamoDB Localに対して共通テストを実行する（DYNAMODB_ENDPOINTが設定されていない場合はスキップ）
+func TestFavoriteRepository_Conformance(t *testing.T) {
+	repotest.RunFavoriteRepositoryTests(t, func(t *testing.T) model.FavoriteRepository {
+		client := newLocalClient(t)
+		if err := CreateTables(client, testConfig()); err != nil {
+			t.Fatalf("Failed to create tables: %v", err)
+		}
+		return NewFavoriteRepository(client, testConfig().Tables())
+	})
+}
+
+func TestAuthRepository_Conformance(t *testing.T) {
+	repotest.RunAuthRepositoryTests(t, func(t *testing.T) model.AuthRepository {
+		return newTestAuthRepository(t)
+	})
+}
+
+func TestActivityStore_Conformance(t *testing.T) {
+	repotest.RunActivityStoreTests(t, func(t *testing.T) model.ActivityStore {
+		client := newLocalClient(t)
+		if err := CreateActivityTables(client, testConfig()); err != nil {
+			t.Fatalf("Failed to create activity tables: %v", err)
+		}
+		return NewActivityStore(client, testConfig().Tables())
+	})
+}
@@ -2,8 +2,11 @@ package dynamodb
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -13,28 +16,36 @@ import (
 )
 
 // NewDynamoDBClient はDynamoDBクライアントのインスタンスを生成
-func NewDynamoDBClient(region string) (*dynamodb.Client, error) {
+// cfg.Endpointを指定した場合はDynamoDB Localなどその接続先を使用する
+func NewDynamoDBClient(cfg Config) (*dynamodb.Client, error) {
 	// 環境変数から認証情報を取得
 	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
 	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
 	sessionToken := os.Getenv("AWS_SESSION_TOKEN") // 必須ではない。
 
-	var cfg aws.Config
+	var awsCfg aws.Config
 	var err error
 
 	// 認証情報が環境変数で提供されている場合は、それを使用
 	if accessKey != "" && secretKey != "" {
 		// 静的な認証情報を使用
 		credProvider := credentials.NewStaticCredentialsProvider(accessKey, secretKey, sessionToken)
-		cfg, err = config.LoadDefaultConfig(context.TODO(),
-			config.WithRegion(region),
+		awsCfg, err = config.LoadDefaultConfig(context.TODO(),
+			config.WithRegion(cfg.Region),
 			config.WithCredentialsProvider(credProvider),
 		)
+	} else if cfg.Endpoint != "" {
+		// DynamoDB Localは認証情報を検証しないため、ダミーの認証情報を使用
+		log.Printf("AWS認証情報が見つからないため、%s にダミーの認証情報で接続します。", cfg.Endpoint)
+		awsCfg, err = config.LoadDefaultConfig(context.TODO(),
+			config.WithRegion(cfg.Region),
+			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider("local", "local", "")),
+		)
 	} else {
 		// 認証情報が提供されない場合は、デフォルトの認証情報プロバイダーチェーンを使用
 		log.Println("AWS認証情報が見つからないため、デフォルトの認証情報プロバイダーチェーンを使用します。")
-		cfg, err = config.LoadDefaultConfig(context.TODO(),
-			config.WithRegion(region),
+		awsCfg, err = config.LoadDefaultConfig(context.TODO(),
+			config.WithRegion(cfg.Region),
 		)
 	}
 
@@ -43,29 +54,213 @@ func NewDynamoDBClient(region string) (*dynamodb.Client, error) {
 	}
 
 	// DynamoDBクライアント生成
-	client := dynamodb.NewFromConfig(cfg)
+	client := dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	})
 	return client, nil
 }
 
-// CreateFavoriteTable はお気に入りテーブルを作成
-func CreateFavoriteTable(client *dynamodb.Client) error {
-	// テーブルが既に存在するか確認
-	existing, err := client.ListTables(context.TODO(), &dynamodb.ListTablesInput{})
+// tableActiveTimeout はテーブルがACTIVEになるまで待つ最大時間
+const tableActiveTimeout = 5 * time.Minute
+
+// CreateTables はアプリケーションが使用する全テーブルを作成し、ACTIVEになるまで待つ
+func CreateTables(client *dynamodb.Client, cfg Config) error {
+	for _, create := range []func(*dynamodb.Client, Config) error{
+		CreateFavoriteTable,
+		CreateCollectionTable,
+		CreateCollectionMemberTable,
+		CreateAuthTable,
+		CreateActivityTables,
+	} {
+		if err := create(client, cfg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createTable はテーブルが存在しない場合に設定の課金モードで作成し、ACTIVEになるまで待つ
+// 既に存在する場合も作成中の可能性があるため、ACTIVEになるまで待つ
+func createTable(client *dynamodb.Client, cfg Config, input *dynamodb.CreateTableInput) error {
+	tableName := aws.ToString(input.TableName)
+
+	exists, err := tableExists(client, tableName)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		log.Printf("Table %s already exists\n", tableName)
+	} else {
+		applyBillingMode(cfg, input)
+		if _, err := client.CreateTable(context.TODO(), input); err != nil {
+			return err
+		}
+		log.Printf("Created table %s\n", tableName)
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(client)
+	err = waiter.Wait(context.TODO(), &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	}, tableActiveTimeout)
+	if err != nil {
+		return fmt.Errorf("table %s did not become active: %w", tableName, err)
+	}
+	return nil
+}
+
+// tableExists はテーブルが存在するかを返す
+func tableExists(client *dynamodb.Client, tableName string) (bool, error) {
+	_, err := client.DescribeTable(context.TODO(), &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		var notFound *types.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// applyBillingMode はテーブルとグローバルセカンダリインデックスに設定の課金モードと容量を設定
+// オンデマンド（PAY_PER_REQUEST）の場合は容量を指定できないため削除する
+func applyBillingMode(cfg Config, input *dynamodb.CreateTableInput) {
+	input.BillingMode = cfg.BillingMode
+
+	var throughput *types.ProvisionedThroughput
+	if cfg.BillingMode != types.BillingModePayPerRequest {
+		input.BillingMode = types.BillingModeProvisioned
+		throughput = &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(cfg.ReadCapacityUnits),
+			WriteCapacityUnits: aws.Int64(cfg.WriteCapacityUnits),
+		}
+	}
+
+	input.ProvisionedThroughput = throughput
+	for i := range input.GlobalSecondaryIndexes {
+		input.GlobalSecondaryIndexes[i].ProvisionedThroughput = throughput
+	}
+}
+
+// enableTTL はテーブルのTTLが無効な場合に指定した属性で有効にする
+// 別の属性で有効になっている場合は、期限切れの項目が削除されないままにならないようエラーを返す
+func enableTTL(client *dynamodb.Client, tableName string, attributeName string) error {
+	output, err := client.DescribeTimeToLive(context.TODO(), &dynamodb.DescribeTimeToLiveInput{
+		TableName: aws.String(tableName),
+	})
 	if err != nil {
 		return err
 	}
 
-	// テーブルが既に存在する場合は作成をスキップ
-	for _, tableName := range existing.TableNames {
-		if tableName == FavoriteTableName {
-			log.Printf("Table %s already exists\n", FavoriteTableName)
+	if description := output.TimeToLiveDescription; description != nil {
+		switch description.TimeToLiveStatus {
+		case types.TimeToLiveStatusEnabled, types.TimeToLiveStatusEnabling:
+			if current := aws.ToString(description.AttributeName); current != attributeName {
+				return fmt.Errorf("TTL on %s is already enabled on attribute %q; disable it before switching to %q", tableName, current, attributeName)
+			}
 			return nil
 		}
 	}
 
+	_, err = client.UpdateTimeToLive(context.TODO(), &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(tableName),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(attributeName),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Enabled TTL on %s.%s\n", tableName, attributeName)
+	return nil
+}
+
+// CreateFavoriteTable はお気に入りテーブルを作成
+func CreateFavoriteTable(client *dynamodb.Client, cfg Config) error {
 	// テーブル作成リクエスト
 	input := &dynamodb.CreateTableInput{
-		TableName: aws.String(FavoriteTableName),
+		TableName: aws.String(cfg.Tables().Favorites),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("userId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("itemId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("createdAtKey"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("collectionId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("userId"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("itemId"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+		LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+			{
+				IndexName: aws.String(IndexNameCreatedAt),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("userId"),
+						KeyType:       types.KeyTypeHash,
+					},
+					{
+						AttributeName: aws.String("createdAtKey"),
+						KeyType:       types.KeyTypeRange,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeAll,
+				},
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			favoriteCollectionIndex(),
+		},
+	}
+
+	return createTable(client, cfg, input)
+}
+
+// favoriteCollectionIndex はお気に入りをコレクションIDで検索するためのインデックス定義
+func favoriteCollectionIndex() types.GlobalSecondaryIndex {
+	return types.GlobalSecondaryIndex{
+		IndexName: aws.String(IndexNameCollectionID),
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("collectionId"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		Projection: &types.Projection{
+			ProjectionType: types.ProjectionTypeAll,
+		},
+	}
+}
+
+// CreateCollectionTable はお気に入りコレクションテーブルを作成
+func CreateCollectionTable(client *dynamodb.Client, cfg Config) error {
+	// テーブル作成リクエスト
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(cfg.Tables().Collections),
 		AttributeDefinitions: []types.AttributeDefinition{
 			{
 				AttributeName: aws.String("id"),
@@ -94,25 +289,148 @@ func CreateFavoriteTable(client *dynamodb.Client) error {
 				Projection: &types.Projection{
 					ProjectionType: types.ProjectionTypeAll,
 				},
-				ProvisionedThroughput: &types.ProvisionedThroughput{
-					ReadCapacityUnits:  aws.Int64(5),
-					WriteCapacityUnits: aws.Int64(5),
+			},
+		},
+	}
+
+	return createTable(client, cfg, input)
+}
+
+// CreateCollectionMemberTable は共有コレクションのメンバーテーブルを作成
+func CreateCollectionMemberTable(client *dynamodb.Client, cfg Config) error {
+	// テーブル作成リクエスト
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(cfg.Tables().CollectionMembers),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("collectionId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("userId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("collectionId"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("userId"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(IndexNameUserID),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("userId"),
+						KeyType:       types.KeyTypeHash,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeKeysOnly,
 				},
 			},
 		},
-		BillingMode: types.BillingModeProvisioned,
-		ProvisionedThroughput: &types.ProvisionedThroughput{
-			ReadCapacityUnits:  aws.Int64(5),
-			WriteCapacityUnits: aws.Int64(5),
+	}
+
+	return createTable(client, cfg, input)
+}
+
+// CreateAuthTable は認証情報テーブルを作成
+func CreateAuthTable(client *dynamodb.Client, cfg Config) error {
+	// テーブル作成リクエスト
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(cfg.Tables().Auth),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("pk"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("sessionUserId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("pk"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String(IndexNameSessionUserID),
+				KeySchema: []types.KeySchemaElement{
+					{
+						AttributeName: aws.String("sessionUserId"),
+						KeyType:       types.KeyTypeHash,
+					},
+				},
+				Projection: &types.Projection{
+					ProjectionType: types.ProjectionTypeKeysOnly,
+				},
+			},
 		},
 	}
 
-	// テーブル作成
-	_, err = client.CreateTable(context.TODO(), input)
+	if err := createTable(client, cfg, input); err != nil {
+		return err
+	}
+
+	// 期限切れのセッション・交換コード・stateを自動削除
+	if !cfg.EnableTTL {
+		return nil
+	}
+	return enableTTL(client, cfg.Tables().Auth, cfg.TTLAttribute)
+}
+
+// CreateActivityTables は全文検索インデックスの更新情報テーブルと取り込み状況テーブルを作成
+func CreateActivityTables(client *dynamodb.Client, cfg Config) error {
+	// 更新情報はユーザーごとに更新情報IDの順で取得・削除する
+	err := createTable(client, cfg, &dynamodb.CreateTableInput{
+		TableName: aws.String(cfg.Tables().Activities),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("userId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+			{
+				AttributeName: aws.String("activityId"),
+				AttributeType: types.ScalarAttributeTypeN,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("userId"),
+				KeyType:       types.KeyTypeHash,
+			},
+			{
+				AttributeName: aws.String("activityId"),
+				KeyType:       types.KeyTypeRange,
+			},
+		},
+	})
 	if err != nil {
 		return err
 	}
 
-	log.Printf("Created table %s\n", FavoriteTableName)
-	return nil
+	return createTable(client, cfg, &dynamodb.CreateTableInput{
+		TableName: aws.String(cfg.Tables().IngestStates),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{
+				AttributeName: aws.String("userId"),
+				AttributeType: types.ScalarAttributeTypeS,
+			},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String("userId"),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+	})
 }
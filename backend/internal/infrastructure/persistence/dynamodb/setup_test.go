@@ -0,0 +1,72 @@
+package dynamodb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestConfig_Tables(t *testing.T) {
+	cfg := DefaultConfig("ap-northeast-1")
+	cfg.TablePrefix = "dev-"
+
+	tables := cfg.Tables()
+	if tables.Favorites != "dev-"+FavoriteTableName || tables.Auth != "dev-"+AuthTableName || tables.CollectionMembers != "dev-"+CollectionMemberTableName {
+		t.Errorf("Unexpected table names: %+v", tables)
+	}
+
+	if DefaultConfig("ap-northeast-1").Tables().Favorites != FavoriteTableName {
+		t.Error("Expected table names without prefix by default")
+	}
+	if DefaultConfig("ap-northeast-1").TTLAttribute != AuthTTLAttribute {
+		t.Errorf("Expected default TTL attribute %q", AuthTTLAttribute)
+	}
+}
+
+func TestParseBillingMode(t *testing.T) {
+	for value, expected := range map[string]types.BillingMode{
+		"":                types.BillingModeProvisioned,
+		"provisioned":     types.BillingModeProvisioned,
+		"PAY_PER_REQUEST": types.BillingModePayPerRequest,
+	} {
+		mode, err := ParseBillingMode(value)
+		if err != nil || mode != expected {
+			t.Errorf("ParseBillingMode(%q) = %q, %v; want %q", value, mode, err, expected)
+		}
+	}
+
+	if _, err := ParseBillingMode("ON_DEMAND"); err == nil {
+		t.Error("Expected error for unknown billing mode")
+	}
+}
+
+func TestApplyBillingMode(t *testing.T) {
+	newInput := func() *dynamodb.CreateTableInput {
+		return &dynamodb.CreateTableInput{
+			TableName:              aws.String("test"),
+			GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{favoriteCollectionIndex()},
+		}
+	}
+
+	cfg := DefaultConfig("ap-northeast-1")
+	cfg.ReadCapacityUnits = 10
+	cfg.WriteCapacityUnits = 3
+	input := newInput()
+	applyBillingMode(cfg, input)
+	if input.BillingMode != types.BillingModeProvisioned || aws.ToInt64(input.ProvisionedThroughput.ReadCapacityUnits) != 10 {
+		t.Errorf("Unexpected provisioned table: %+v", input)
+	}
+	if aws.ToInt64(input.GlobalSecondaryIndexes[0].ProvisionedThroughput.WriteCapacityUnits) != 3 {
+		t.Error("Expected index to use configured capacity")
+	}
+
+	// オンデマンドの場合はテーブルとインデックスに容量を指定しない
+	cfg.BillingMode = types.BillingModePayPerRequest
+	input = newInput()
+	applyBillingMode(cfg, input)
+	if input.BillingMode != types.BillingModePayPerRequest || input.ProvisionedThroughput != nil || input.GlobalSecondaryIndexes[0].ProvisionedThroughput != nil {
+		t.Errorf("Unexpected on-demand table: %+v", input)
+	}
+}
@@ -0,0 +1,116 @@
+package memory
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// ActivityStore はインメモリの更新情報ストアの実装
+type ActivityStore struct {
+	activities map[string]map[int]*model.BacklogItem
+	states     map[string]model.IngestState
+	mu         sync.RWMutex
+}
+
+// NewActivityStore はActivityStoreのインスタンスを生成
+func NewActivityStore() *ActivityStore {
+	return &ActivityStore{
+		activities: make(map[string]map[int]*model.BacklogItem),
+		states:     make(map[string]model.IngestState),
+	}
+}
+
+// SaveActivities は更新情報と取り込み状況を保存
+func (s *ActivityStore) SaveActivities(userID string, items []*model.BacklogItem, state model.IngestState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.states[userID].Version != state.Version-1 {
+		return model.ErrIngestStateConflict
+	}
+
+	activities, ok := s.activities[userID]
+	if !ok {
+		activities = make(map[int]*model.BacklogItem)
+		s.activities[userID] = activities
+	}
+	for _, item := range items {
+		id, err := strconv.Atoi(item.ID)
+		if err != nil {
+			continue
+		}
+		copied := *item
+		activities[id] = &copied
+	}
+
+	state.Gaps = append([]model.IngestGap(nil), state.Gaps...)
+	s.states[userID] = state
+	return nil
+}
+
+// LoadIngestState は保存済みの取り込み状況を取得
+func (s *ActivityStore) LoadIngestState(userID string) (model.IngestState, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state, ok := s.states[userID]
+	state.Gaps = append([]model.IngestGap(nil), state.Gaps...)
+	return state, ok, nil
+}
+
+// LoadActivities は保存済みの更新情報を新しい順に取得
+func (s *ActivityStore) LoadActivities(userID string) ([]*model.BacklogItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := make([]int, 0, len(s.activities[userID]))
+	for id := range s.activities[userID] {
+		ids = append(ids, id)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+	items := make([]*model.BacklogItem, 0, len(ids))
+	for _, id := range ids {
+		copied := *s.activities[userID][id]
+		items = append(items, &copied)
+	}
+	return items, nil
+}
+
+// PruneActivities はoldestIDより古い更新情報を削除
+func (s *ActivityStore) PruneActivities(userID string, oldestID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.activities[userID] {
+		if id < oldestID {
+			delete(s.activities[userID], id)
+		}
+	}
+	return nil
+}
+
+// DeleteActivities はユーザーの更新情報と取り込み状況を削除
+func (s *ActivityStore) DeleteActivities(userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.activities, userID)
+	delete(s.states, userID)
+	return nil
+}
+
+// ActivityUsers は取り込み状況を保存しているユーザーIDを取得
+func (s *ActivityStore) ActivityUsers() ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	users := make([]string, 0, len(s.states))
+	for userID := range s.states {
+		users = append(users, userID)
+	}
+	return users, nil
+}
@@ -3,20 +3,27 @@ package memory
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
 )
 
 // AuthRepository はインメモリ認証リポジトリの実装
 type AuthRepository struct {
-	tokens map[string]*model.AuthToken
-	mu     sync.RWMutex
+	tokens   map[string]*model.AuthToken
+	sessions map[string]*model.Session
+	codes    map[string]*model.ExchangeCode
+	states   map[string]*model.OAuthState
+	mu       sync.RWMutex
 }
 
 // NewAuthRepository はAuthRepositoryのインスタンスを生成
 func NewAuthRepository() *AuthRepository {
 	return &AuthRepository{
-		tokens: make(map[string]*model.AuthToken),
+		tokens:   make(map[string]*model.AuthToken),
+		sessions: make(map[string]*model.Session),
+		codes:    make(map[string]*model.ExchangeCode),
+		states:   make(map[string]*model.OAuthState),
 	}
 }
 
@@ -36,7 +43,7 @@ func (r *AuthRepository) GetTokenByUserID(userID string) (*model.AuthToken, erro
 
 	token, exists := r.tokens[userID]
 	if !exists {
-		return nil, errors.New("token not found")
+		return nil, model.ErrTokenNotFound
 	}
 
 	return token, nil
@@ -68,3 +75,117 @@ func (r *AuthRepository) GetAllTokens() ([]*model.AuthToken, error) {
 
 	return tokens, nil
 }
+
+// SaveSession はセッションを保存
+func (r *AuthRepository) SaveSession(session *model.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 期限切れのセッションを削除
+	now := time.Now()
+	for id, s := range r.sessions {
+		if s.ExpiresAt.Before(now) {
+			delete(r.sessions, id)
+		}
+	}
+
+	r.sessions[session.ID] = session
+	return nil
+}
+
+// GetSession はセッションIDからセッションを取得
+func (r *AuthRepository) GetSession(sessionID string) (*model.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	session, exists := r.sessions[sessionID]
+	if !exists {
+		return nil, errors.New("session not found")
+	}
+
+	return session, nil
+}
+
+// DeleteSession はセッションを削除
+func (r *AuthRepository) DeleteSession(sessionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.sessions, sessionID)
+	return nil
+}
+
+// DeleteSessionsByUserID はユーザーの全セッションを削除
+func (r *AuthRepository) DeleteSessionsByUserID(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+		}
+	}
+	return nil
+}
+
+// SaveExchangeCode は交換コードを保存
+func (r *AuthRepository) SaveExchangeCode(code *model.ExchangeCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 使われずに期限切れになった交換コードが溜まり続けないよう削除
+	now := time.Now()
+	for key, c := range r.codes {
+		if c.ExpiresAt.Before(now) {
+			delete(r.codes, key)
+		}
+	}
+
+	r.codes[code.Code] = code
+	return nil
+}
+
+// ConsumeExchangeCode は交換コードを取得し、再利用できないよう削除
+func (r *AuthRepository) ConsumeExchangeCode(code string) (*model.ExchangeCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exchangeCode, exists := r.codes[code]
+	if !exists {
+		return nil, errors.New("exchange code not found")
+	}
+
+	delete(r.codes, code)
+	return exchangeCode, nil
+}
+
+// SaveOAuthState はOAuthのstateを保存
+func (r *AuthRepository) SaveOAuthState(state *model.OAuthState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 認可URLは未認証でも発行できるため、期限切れのstateを削除してメモリの増加を防ぐ
+	now := time.Now()
+	for key, s := range r.states {
+		if s.ExpiresAt.Before(now) {
+			delete(r.states, key)
+		}
+	}
+
+	r.states[state.State] = state
+	return nil
+}
+
+// ConsumeOAuthState はOAuthのstateを取得し、再利用できないよう削除
+func (r *AuthRepository) ConsumeOAuthState(state string) (*model.OAuthState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oauthState, exists := r.states[state]
+	if !exists {
+		return nil, errors.New("oauth state not found")
+	}
+
+	delete(r.states, state)
+	return oauthState, nil
+}
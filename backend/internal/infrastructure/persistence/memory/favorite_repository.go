@@ -1,6 +1,8 @@
 package memory
 
 import (
+	"slices"
+	"sort"
 	"sync"
 
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
@@ -8,14 +10,18 @@ import (
 
 // FavoriteRepository はインメモリお気に入りリポジトリの実装
 type FavoriteRepository struct {
-	favorites []*model.Favorite
-	mu        sync.RWMutex
+	favorites   []*model.Favorite
+	collections map[string]*model.Collection
+	members     map[string]map[string]*model.CollectionMember
+	mu          sync.RWMutex
 }
 
 // NewFavoriteRepository はFavoriteRepositoryのインスタンスを生成
 func NewFavoriteRepository() *FavoriteRepository {
 	return &FavoriteRepository{
-		favorites: make([]*model.Favorite, 0),
+		favorites:   make([]*model.Favorite, 0),
+		collections: make(map[string]*model.Collection),
+		members:     make(map[string]map[string]*model.CollectionMember),
 	}
 }
 
@@ -27,22 +33,98 @@ func (r *FavoriteRepository) FindByUserID(userID string) ([]*model.Favorite, err
 	result := make([]*model.Favorite, 0)
 	for _, fav := range r.favorites {
 		if fav.UserID == userID {
-			result = append(result, fav)
+			result = append(result, cloneFavorite(fav))
 		}
 	}
 
 	return result, nil
 }
 
+// FindPageByUserID はユーザーIDからお気に入りを登録日時の新しい順に1ページ分検索
+func (r *FavoriteRepository) FindPageByUserID(userID string, page model.PageRequest) (*model.FavoritePage, error) {
+	offset, err := model.DecodeOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	favorites, err := r.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(favorites, func(i, j int) bool {
+		return favorites[i].CreatedAt.After(favorites[j].CreatedAt)
+	})
+
+	result := &model.FavoritePage{Favorites: []*model.Favorite{}}
+	if offset >= len(favorites) {
+		return result, nil
+	}
+
+	end := offset + page.Size()
+	if end < len(favorites) {
+		result.NextCursor = model.EncodeOffsetCursor(end)
+	} else {
+		end = len(favorites)
+	}
+	result.Favorites = favorites[offset:end]
+
+	return result, nil
+}
+
 // Save はお気に入りを保存
+// 同じ更新情報のお気に入りが既にある場合は上書きせずにErrFavoriteAlreadyExistsを返す
 func (r *FavoriteRepository) Save(favorite *model.Favorite) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.favorites = append(r.favorites, favorite)
+	for _, fav := range r.favorites {
+		if fav.UserID == favorite.UserID && fav.ItemID == favorite.ItemID {
+			return model.ErrFavoriteAlreadyExists
+		}
+	}
+
+	r.favorites = append(r.favorites, cloneFavorite(favorite))
 	return nil
 }
 
+// Update はお気に入りのスナップショットを更新
+func (r *FavoriteRepository) Update(favorite *model.Favorite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, fav := range r.favorites {
+		if fav.UserID == favorite.UserID && fav.ItemID == favorite.ItemID {
+			updated := cloneFavorite(fav)
+			updated.Snapshot = cloneFavorite(favorite).Snapshot
+			r.favorites[i] = updated
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// UpdateAnnotations はお気に入りのコレクション・タグ・メモを更新
+func (r *FavoriteRepository) UpdateAnnotations(favorite *model.Favorite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, fav := range r.favorites {
+		if fav.UserID == favorite.UserID && fav.ItemID == favorite.ItemID {
+			annotated := cloneFavorite(favorite)
+			updated := cloneFavorite(fav)
+			updated.CollectionID = annotated.CollectionID
+			updated.Tags = annotated.Tags
+			updated.Note = annotated.Note
+			r.favorites[i] = updated
+			return nil
+		}
+	}
+
+	return model.ErrFavoriteNotFound
+}
+
 // Delete はお気に入りを削除
 func (r *FavoriteRepository) Delete(userID string, itemID string) error {
 	r.mu.Lock()
@@ -54,6 +136,9 @@ func (r *FavoriteRepository) Delete(userID string, itemID string) error {
 			filtered = append(filtered, fav)
 		}
 	}
+	if len(filtered) == len(r.favorites) {
+		return model.ErrFavoriteNotFound
+	}
 
 	r.favorites = filtered
 	return nil
@@ -72,3 +157,132 @@ func (r *FavoriteRepository) Exists(userID string, itemID string) (bool, error)
 
 	return false, nil
 }
+
+// SaveCollection はコレクションを保存
+func (r *FavoriteRepository) SaveCollection(collection *model.Collection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cloned := *collection
+	r.collections[collection.ID] = &cloned
+	return nil
+}
+
+// FindCollection はIDからコレクションを取得
+func (r *FavoriteRepository) FindCollection(collectionID string) (*model.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	collection, ok := r.collections[collectionID]
+	if !ok {
+		return nil, model.ErrCollectionNotFound
+	}
+
+	cloned := *collection
+	return &cloned, nil
+}
+
+// FindCollectionsByUserID はユーザーIDからコレクションを検索
+func (r *FavoriteRepository) FindCollectionsByUserID(userID string) ([]*model.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.Collection, 0)
+	for _, collection := range r.collections {
+		if collection.UserID == userID {
+			cloned := *collection
+			result = append(result, &cloned)
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteCollection はコレクションを削除
+func (r *FavoriteRepository) DeleteCollection(collectionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.collections, collectionID)
+	return nil
+}
+
+// FindByCollectionID はコレクションに入っているお気に入りを検索
+func (r *FavoriteRepository) FindByCollectionID(collectionID string) ([]*model.Favorite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.Favorite, 0)
+	for _, fav := range r.favorites {
+		if fav.CollectionID == collectionID {
+			result = append(result, cloneFavorite(fav))
+		}
+	}
+
+	return result, nil
+}
+
+// SaveCollectionMember はコレクションのメンバーを保存
+func (r *FavoriteRepository) SaveCollectionMember(member *model.CollectionMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.members[member.CollectionID] == nil {
+		r.members[member.CollectionID] = make(map[string]*model.CollectionMember)
+	}
+	cloned := *member
+	r.members[member.CollectionID][member.UserID] = &cloned
+	return nil
+}
+
+// FindCollectionMembers はコレクションのメンバーを検索
+func (r *FavoriteRepository) FindCollectionMembers(collectionID string) ([]*model.CollectionMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.CollectionMember, 0)
+	for _, member := range r.members[collectionID] {
+		cloned := *member
+		result = append(result, &cloned)
+	}
+
+	return result, nil
+}
+
+// FindCollectionsByMemberID はユーザーが共有されているコレクションを検索
+func (r *FavoriteRepository) FindCollectionsByMemberID(userID string) ([]*model.Collection, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*model.Collection, 0)
+	for collectionID, members := range r.members {
+		collection, ok := r.collections[collectionID]
+		if _, isMember := members[userID]; !ok || !isMember {
+			continue
+		}
+		cloned := *collection
+		result = append(result, &cloned)
+	}
+
+	return result, nil
+}
+
+// DeleteCollectionMember はコレクションのメンバーを削除
+func (r *FavoriteRepository) DeleteCollectionMember(collectionID string, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.members[collectionID], userID)
+	return nil
+}
+
+// cloneFavorite は呼び出し元との間でお気に入りを共有しないよう複製
+func cloneFavorite(favorite *model.Favorite) *model.Favorite {
+	cloned := *favorite
+	if favorite.Snapshot != nil {
+		snapshot := *favorite.Snapshot
+		cloned.Snapshot = &snapshot
+	}
+	cloned.Tags = slices.Clone(favorite.Tags)
+	return &cloned
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/repotest"
+)
+
+func TestFavoriteRepository_Conformance(t *testing.T) {
+	repotest.RunFavoriteRepositoryTests(t, func(t *testing.T) model.FavoriteRepository {
+		return NewFavoriteRepository()
+	})
+}
+
+func TestAuthRepository_Conformance(t *testing.T) {
+	repotest.RunAuthRepositoryTests(t, func(t *testing.T) model.AuthRepository {
+		return NewAuthRepository()
+	})
+}
+
+func TestActivityStore_Conformance(t *testing.T) {
+	repotest.RunActivityStoreTests(t, func(t *testing.T) model.ActivityStore {
+		return NewActivityStore()
+	})
+}
+
+// 期限切れのstateと交換コードは次の保存時に削除されることを確認する
+func TestAuthRepository_DeletesExpiredOnSave(t *testing.T) {
+	repo := NewAuthRepository()
+	expired := time.Now().Add(-time.Minute)
+	valid := time.Now().Add(time.Minute)
+
+	repo.SaveOAuthState(&model.OAuthState{State: "expired", ExpiresAt: expired})
+	repo.SaveOAuthState(&model.OAuthState{State: "valid", ExpiresAt: valid})
+	if _, exists := repo.states["expired"]; exists {
+		t.Error("Expected expired state to be deleted")
+	}
+	if _, exists := repo.states["valid"]; !exists {
+		t.Error("Expected valid state to be kept")
+	}
+
+	repo.SaveExchangeCode(&model.ExchangeCode{Code: "expired", ExpiresAt: expired})
+	repo.SaveExchangeCode(&model.ExchangeCode{Code: "valid", ExpiresAt: valid})
+	if _, exists := repo.codes["expired"]; exists {
+		t.Error("Expected expired exchange code to be deleted")
+	}
+	if _, exists := repo.codes["valid"]; !exists {
+		t.Error("Expected valid exchange code to be kept")
+	}
+}
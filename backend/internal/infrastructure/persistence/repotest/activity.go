@@ -0,0 +1,148 @@
+package repotest
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// RunActivityStoreTests は更新情報ストアの共通テストを実行する
+// newStore はサブテストごとに呼び出される
+func RunActivityStoreTests(t *testing.T, newStore func(t *testing.T) model.ActivityStore) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		store := newStore(t)
+		userID := uniqueID(t, "user")
+
+		if _, ok, err := store.LoadIngestState(userID); err != nil || ok {
+			t.Fatalf("Expected no ingest state, got ok=%v err=%v", ok, err)
+		}
+
+		state := model.IngestState{Gaps: []model.IngestGap{{MaxID: 250, MinID: 200}}, Version: 1}
+		if err := store.SaveActivities(userID, []*model.BacklogItem{newActivity(100), newActivity(300)}, state); err != nil {
+			t.Fatalf("Failed to save activities: %v", err)
+		}
+		// 同じIDの更新情報は上書きする
+		updated := newActivity(300)
+		updated.ContentSummary = "updated"
+		state = model.IngestState{ReachedOldest: true, Version: 2}
+		if err := store.SaveActivities(userID, []*model.BacklogItem{updated, newActivity(200)}, state); err != nil {
+			t.Fatalf("Failed to save activities: %v", err)
+		}
+
+		got, ok, err := store.LoadIngestState(userID)
+		if err != nil || !ok {
+			t.Fatalf("Failed to load ingest state: ok=%v err=%v", ok, err)
+		}
+		if got.Version != 2 || !got.ReachedOldest || len(got.Gaps) != 0 {
+			t.Errorf("Unexpected ingest state: %+v", got)
+		}
+
+		items, err := store.LoadActivities(userID)
+		if err != nil {
+			t.Fatalf("Failed to load activities: %v", err)
+		}
+		if ids := activityIDs(items); !slices.Equal(ids, []string{"300", "200", "100"}) {
+			t.Fatalf("Expected activities newest first, got %v", ids)
+		}
+		if items[0].ContentSummary != "updated" || items[0].ProjectKey != "PROJ" || !items[0].Created.Equal(updated.Created) {
+			t.Errorf("Unexpected activity: %+v", items[0])
+		}
+	})
+
+	t.Run("VersionConflict", func(t *testing.T) {
+		store := newStore(t)
+		userID := uniqueID(t, "user")
+
+		if err := store.SaveActivities(userID, nil, model.IngestState{Version: 2}); !errors.Is(err, model.ErrIngestStateConflict) {
+			t.Fatalf("Expected ErrIngestStateConflict for a skipped version, got %v", err)
+		}
+		if err := store.SaveActivities(userID, nil, model.IngestState{Version: 1}); err != nil {
+			t.Fatalf("Failed to save first version: %v", err)
+		}
+		// ほかのサーバーが同じバージョンを先に保存した場合は上書きしない
+		if err := store.SaveActivities(userID, nil, model.IngestState{Version: 1, ReachedOldest: true}); !errors.Is(err, model.ErrIngestStateConflict) {
+			t.Fatalf("Expected ErrIngestStateConflict for a stale version, got %v", err)
+		}
+
+		got, _, err := store.LoadIngestState(userID)
+		if err != nil {
+			t.Fatalf("Failed to load ingest state: %v", err)
+		}
+		if got.Version != 1 || got.ReachedOldest {
+			t.Errorf("Expected the first saved state to remain, got %+v", got)
+		}
+	})
+
+	t.Run("PruneAndDelete", func(t *testing.T) {
+		store := newStore(t)
+		alice, bob := uniqueID(t, "alice"), uniqueID(t, "bob")
+
+		for _, userID := range []string{alice, bob} {
+			items := []*model.BacklogItem{newActivity(100), newActivity(200), newActivity(300)}
+			if err := store.SaveActivities(userID, items, model.IngestState{Version: 1}); err != nil {
+				t.Fatalf("Failed to save activities: %v", err)
+			}
+		}
+
+		if err := store.PruneActivities(alice, 200); err != nil {
+			t.Fatalf("Failed to prune activities: %v", err)
+		}
+		items, err := store.LoadActivities(alice)
+		if err != nil {
+			t.Fatalf("Failed to load activities: %v", err)
+		}
+		if ids := activityIDs(items); !slices.Equal(ids, []string{"300", "200"}) {
+			t.Errorf("Expected activities older than 200 to be pruned, got %v", ids)
+		}
+
+		if err := store.DeleteActivities(alice); err != nil {
+			t.Fatalf("Failed to delete activities: %v", err)
+		}
+		if items, err := store.LoadActivities(alice); err != nil || len(items) != 0 {
+			t.Errorf("Expected no activities after delete, got %d (err=%v)", len(items), err)
+		}
+		if _, ok, err := store.LoadIngestState(alice); err != nil || ok {
+			t.Errorf("Expected no ingest state after delete, got ok=%v err=%v", ok, err)
+		}
+
+		// ほかのユーザーの更新情報は削除しない
+		users, err := store.ActivityUsers()
+		if err != nil {
+			t.Fatalf("Failed to list activity users: %v", err)
+		}
+		if slices.Contains(users, alice) || !slices.Contains(users, bob) {
+			t.Errorf("Unexpected activity users: %v", users)
+		}
+		if items, err := store.LoadActivities(bob); err != nil || len(items) != 3 {
+			t.Errorf("Expected bob's activities to remain, got %d (err=%v)", len(items), err)
+		}
+	})
+}
+
+// newActivity はテスト用の更新情報を生成
+func newActivity(id int) *model.BacklogItem {
+	return &model.BacklogItem{
+		ID:             strconv.Itoa(id),
+		ProjectID:      "1",
+		ProjectKey:     "PROJ",
+		ProjectName:    "Project",
+		TypeID:         1,
+		Type:           "課題の追加",
+		ContentSummary: "activity " + strconv.Itoa(id),
+		CreatedUser:    model.User{ID: "10", Name: "Alice"},
+		Created:        time.Date(2024, 1, 1, 0, 0, id, 0, time.UTC),
+	}
+}
+
+// activityIDs は更新情報のIDを順に返す
+func activityIDs(items []*model.BacklogItem) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
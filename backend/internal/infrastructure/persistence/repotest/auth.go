@@ -0,0 +1,171 @@
+package repotest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// RunAuthRepositoryTests は認証リポジトリの共通テストを実行する
+// newRepository はサブテストごとに呼び出される
+func RunAuthRepositoryTests(t *testing.T, newRepository func(t *testing.T) model.AuthRepository) {
+	t.Run("TokenRoundTrip", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+
+		if _, err := repo.GetTokenByUserID(userID); !errors.Is(err, model.ErrTokenNotFound) {
+			t.Errorf("Expected ErrTokenNotFound, got %v", err)
+		}
+
+		token := &model.AuthToken{AccessToken: "access", TokenType: "Bearer", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour).Truncate(time.Second), UserID: userID}
+		if err := repo.SaveToken(token); err != nil {
+			t.Fatalf("Failed to save token: %v", err)
+		}
+		// 同じユーザーのトークンは上書きする
+		rotated := *token
+		rotated.AccessToken = "rotated"
+		if err := repo.SaveToken(&rotated); err != nil {
+			t.Fatalf("Failed to overwrite token: %v", err)
+		}
+
+		got, err := repo.GetToken(userID)
+		if err != nil {
+			t.Fatalf("Failed to get token: %v", err)
+		}
+		if got.AccessToken != "rotated" || got.RefreshToken != "refresh" || !got.ExpiresAt.Equal(token.ExpiresAt) {
+			t.Errorf("Unexpected token: %+v", got)
+		}
+
+		tokens, err := repo.GetAllTokens()
+		if err != nil {
+			t.Fatalf("Failed to get all tokens: %v", err)
+		}
+		found := 0
+		for _, token := range tokens {
+			if token.UserID == userID {
+				found++
+			}
+		}
+		if found != 1 {
+			t.Errorf("Expected 1 token for the user in GetAllTokens, got %d", found)
+		}
+
+		if err := repo.DeleteToken(userID); err != nil {
+			t.Fatalf("Failed to delete token: %v", err)
+		}
+		if err := repo.DeleteToken(userID); err != nil {
+			t.Fatalf("Expected deleting a missing token to succeed, got %v", err)
+		}
+		if _, err := repo.GetTokenByUserID(userID); !errors.Is(err, model.ErrTokenNotFound) {
+			t.Errorf("Expected ErrTokenNotFound after delete, got %v", err)
+		}
+	})
+
+	t.Run("SessionsPerUser", func(t *testing.T) {
+		repo := newRepository(t)
+		alice, bob := uniqueID(t, "alice"), uniqueID(t, "bob")
+		now := time.Now().Truncate(time.Second)
+
+		sessions := []*model.Session{
+			{ID: uniqueID(t, "s1"), UserID: alice, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+			{ID: uniqueID(t, "s2"), UserID: alice, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+			{ID: uniqueID(t, "s3"), UserID: bob, CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		}
+		for _, session := range sessions {
+			if err := repo.SaveSession(session); err != nil {
+				t.Fatalf("Failed to save session: %v", err)
+			}
+		}
+
+		got, err := repo.GetSession(sessions[0].ID)
+		if err != nil {
+			t.Fatalf("Failed to get session: %v", err)
+		}
+		if got.UserID != alice || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+			t.Errorf("Unexpected session: %+v", got)
+		}
+
+		// 他のユーザーのセッションは削除しない
+		if err := repo.DeleteSessionsByUserID(alice); err != nil {
+			t.Fatalf("Failed to delete sessions: %v", err)
+		}
+		for _, session := range sessions[:2] {
+			if _, err := repo.GetSession(session.ID); err == nil {
+				t.Errorf("Expected session %s to be deleted", session.ID)
+			}
+		}
+		if _, err := repo.GetSession(sessions[2].ID); err != nil {
+			t.Errorf("Expected bob's session to remain, got %v", err)
+		}
+
+		if err := repo.DeleteSession(sessions[2].ID); err != nil {
+			t.Fatalf("Failed to delete session: %v", err)
+		}
+		if err := repo.DeleteSession(sessions[2].ID); err != nil {
+			t.Fatalf("Expected deleting a missing session to succeed, got %v", err)
+		}
+	})
+
+	t.Run("ExchangeCodeConsumedOnce", func(t *testing.T) {
+		repo := newRepository(t)
+		code := &model.ExchangeCode{Code: uniqueID(t, "code"), UserID: uniqueID(t, "user"), ExpiresAt: time.Now().Add(time.Minute)}
+		if err := repo.SaveExchangeCode(code); err != nil {
+			t.Fatalf("Failed to save exchange code: %v", err)
+		}
+
+		consumed := consumeConcurrently(func() error {
+			got, err := repo.ConsumeExchangeCode(code.Code)
+			if err == nil && got.UserID != code.UserID {
+				t.Errorf("Unexpected exchange code: %+v", got)
+			}
+			return err
+		})
+		if consumed != 1 {
+			t.Errorf("Expected exchange code to be consumed exactly once, got %d", consumed)
+		}
+	})
+
+	t.Run("OAuthStateConsumedOnce", func(t *testing.T) {
+		repo := newRepository(t)
+		state := &model.OAuthState{State: uniqueID(t, "state"), CodeVerifier: "verifier", ExpiresAt: time.Now().Add(time.Minute)}
+		if err := repo.SaveOAuthState(state); err != nil {
+			t.Fatalf("Failed to save oauth state: %v", err)
+		}
+
+		consumed := consumeConcurrently(func() error {
+			got, err := repo.ConsumeOAuthState(state.State)
+			if err == nil && got.CodeVerifier != "verifier" {
+				t.Errorf("Unexpected oauth state: %+v", got)
+			}
+			return err
+		})
+		if consumed != 1 {
+			t.Errorf("Expected oauth state to be consumed exactly once, got %d", consumed)
+		}
+	})
+}
+
+// consumeConcurrently は同じ値の取得と削除を同時に実行し、成功した回数を返す
+func consumeConcurrently(consume func() error) int {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		consumed int
+	)
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := consume(); err == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return consumed
+}
@@ -0,0 +1,397 @@
+// Package repotest はmodel.FavoriteRepository・model.AuthRepository・model.ActivityStoreの全実装が満たすべき振る舞いを検証する共通テスト
+// 各実装のテストから呼び出し、メモリ・DynamoDB・SQLなどの実装間で同じ振る舞いになることを保証する
+package repotest
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// concurrency は同時実行のテストで起動するgoroutineの数
+const concurrency = 10
+
+// RunFavoriteRepositoryTests はお気に入りリポジトリの共通テストを実行する
+// newRepository はサブテストごとに呼び出される。同じストレージを共有する実装でも衝突しないよう、テストではユーザーIDとコレクションIDを一意にする
+func RunFavoriteRepositoryTests(t *testing.T, newRepository func(t *testing.T) model.FavoriteRepository) {
+	t.Run("SaveRejectsDuplicates", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+		created := time.Now().Truncate(time.Millisecond)
+
+		mustSave(t, repo, newFavorite(userID, "1", created))
+		// 同じ更新情報を再度保存すると重複エラーになり、最初の登録日時が残る
+		if err := repo.Save(newFavorite(userID, "1", created.Add(time.Hour))); !errors.Is(err, model.ErrFavoriteAlreadyExists) {
+			t.Fatalf("Expected ErrFavoriteAlreadyExists, got %v", err)
+		}
+
+		favorites := mustFindByUserID(t, repo, userID)
+		if len(favorites) != 1 {
+			t.Fatalf("Expected 1 favorite, got %d", len(favorites))
+		}
+		if !favorites[0].CreatedAt.Equal(created) {
+			t.Errorf("Expected first CreatedAt %v to be kept, got %v", created, favorites[0].CreatedAt)
+		}
+	})
+
+	t.Run("DeleteMissingFavorite", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+
+		if err := repo.Delete(userID, "404"); !errors.Is(err, model.ErrFavoriteNotFound) {
+			t.Fatalf("Expected ErrFavoriteNotFound for a missing favorite, got %v", err)
+		}
+
+		mustSave(t, repo, newFavorite(userID, "1", time.Now()))
+		if err := repo.Delete(userID, "1"); err != nil {
+			t.Fatalf("Failed to delete favorite: %v", err)
+		}
+		if err := repo.Delete(userID, "1"); !errors.Is(err, model.ErrFavoriteNotFound) {
+			t.Fatalf("Expected ErrFavoriteNotFound when deleting twice, got %v", err)
+		}
+		if exists := mustExist(t, repo, userID, "1"); exists {
+			t.Error("Expected favorite to be deleted")
+		}
+	})
+
+	t.Run("UpdateMissingFavorite", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+
+		// スナップショットの更新で削除済みのお気に入りを再作成しない
+		favorite := newFavorite(userID, "1", time.Now())
+		if err := repo.Update(favorite); err != nil {
+			t.Fatalf("Expected updating a missing favorite to succeed, got %v", err)
+		}
+		if exists := mustExist(t, repo, userID, "1"); exists {
+			t.Error("Expected Update not to create a favorite")
+		}
+
+		if err := repo.UpdateAnnotations(favorite); !errors.Is(err, model.ErrFavoriteNotFound) {
+			t.Errorf("Expected ErrFavoriteNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ConcurrentSaves", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+		now := time.Now()
+
+		// 異なる更新情報と同じ更新情報を同時に保存する
+		var wg sync.WaitGroup
+		distinct := make(chan error, concurrency)
+		shared := make(chan error, concurrency)
+		for i := 0; i < concurrency; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				distinct <- repo.Save(newFavorite(userID, strconv.Itoa(i), now.Add(time.Duration(i)*time.Millisecond)))
+			}(i)
+			go func(i int) {
+				defer wg.Done()
+				shared <- repo.Save(newFavorite(userID, "shared", now.Add(time.Duration(i)*time.Millisecond)))
+			}(i)
+		}
+		wg.Wait()
+		close(distinct)
+		close(shared)
+
+		for err := range distinct {
+			if err != nil {
+				t.Fatalf("Failed to save favorite concurrently: %v", err)
+			}
+		}
+		// 同じ更新情報は1件だけが保存され、残りは重複エラーになる
+		saved := 0
+		for err := range shared {
+			switch {
+			case err == nil:
+				saved++
+			case !errors.Is(err, model.ErrFavoriteAlreadyExists):
+				t.Fatalf("Unexpected error for concurrent duplicate save: %v", err)
+			}
+		}
+		if saved != 1 {
+			t.Errorf("Expected exactly 1 concurrent save to succeed, got %d", saved)
+		}
+
+		if favorites := mustFindByUserID(t, repo, userID); len(favorites) != concurrency+1 {
+			t.Errorf("Expected %d favorites, got %d", concurrency+1, len(favorites))
+		}
+	})
+
+	t.Run("PerUserIsolation", func(t *testing.T) {
+		repo := newRepository(t)
+		alice, bob := uniqueID(t, "alice"), uniqueID(t, "bob")
+		now := time.Now()
+
+		mustSave(t, repo, newFavorite(alice, "1", now))
+		mustSave(t, repo, newFavorite(alice, "2", now))
+		mustSave(t, repo, newFavorite(bob, "1", now))
+
+		// 他のユーザーのお気に入りは見えず、削除や更新も影響しない
+		if err := repo.Delete(bob, "1"); err != nil {
+			t.Fatalf("Failed to delete favorite: %v", err)
+		}
+		if err := repo.Delete(bob, "2"); !errors.Is(err, model.ErrFavoriteNotFound) {
+			t.Errorf("Expected ErrFavoriteNotFound for another user's favorite, got %v", err)
+		}
+		annotated := newFavorite(bob, "2", now)
+		annotated.Note = "bob"
+		if err := repo.UpdateAnnotations(annotated); !errors.Is(err, model.ErrFavoriteNotFound) {
+			t.Errorf("Expected ErrFavoriteNotFound for another user's favorite, got %v", err)
+		}
+
+		if favorites := mustFindByUserID(t, repo, alice); len(favorites) != 2 {
+			t.Errorf("Expected alice to keep 2 favorites, got %d", len(favorites))
+		}
+		for _, favorite := range mustFindByUserID(t, repo, alice) {
+			if favorite.UserID != alice || favorite.Note != "" {
+				t.Errorf("Unexpected favorite for alice: %+v", favorite)
+			}
+		}
+		if favorites := mustFindByUserID(t, repo, bob); len(favorites) != 0 {
+			t.Errorf("Expected bob to have no favorites, got %d", len(favorites))
+		}
+		if exists := mustExist(t, repo, bob, "2"); exists {
+			t.Error("Expected alice's favorite not to exist for bob")
+		}
+	})
+
+	t.Run("PageNewestFirst", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+		now := time.Now()
+
+		for i := 1; i <= 5; i++ {
+			mustSave(t, repo, newFavorite(userID, strconv.Itoa(i), now.Add(time.Duration(i)*time.Second)))
+		}
+
+		var itemIDs []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("Paging did not terminate")
+			}
+			page, err := repo.FindPageByUserID(userID, model.PageRequest{Cursor: cursor, Limit: 2})
+			if err != nil {
+				t.Fatalf("Failed to find favorites page: %v", err)
+			}
+			for _, favorite := range page.Favorites {
+				itemIDs = append(itemIDs, favorite.ItemID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		expected := []string{"5", "4", "3", "2", "1"}
+		if len(itemIDs) != len(expected) {
+			t.Fatalf("Expected %v, got %v", expected, itemIDs)
+		}
+		for i := range expected {
+			if itemIDs[i] != expected[i] {
+				t.Fatalf("Expected %v, got %v", expected, itemIDs)
+			}
+		}
+
+		if _, err := repo.FindPageByUserID(userID, model.PageRequest{Cursor: "not-a-cursor"}); !errors.Is(err, model.ErrInvalidCursor) {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	t.Run("SnapshotAndAnnotations", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+
+		favorite := newFavorite(userID, "1", time.Now())
+		mustSave(t, repo, favorite)
+
+		favorite.Snapshot = &model.FavoriteSnapshot{ProjectName: "プロジェクトA", ContentSummary: "更新後", IssueStatus: "完了"}
+		if err := repo.Update(favorite); err != nil {
+			t.Fatalf("Failed to update snapshot: %v", err)
+		}
+		favorite.CollectionID = uniqueID(t, "collection")
+		favorite.Tags = []string{"重要", "確認"}
+		favorite.Note = "メモ"
+		if err := repo.UpdateAnnotations(favorite); err != nil {
+			t.Fatalf("Failed to update annotations: %v", err)
+		}
+
+		favorites := mustFindByUserID(t, repo, userID)
+		if len(favorites) != 1 {
+			t.Fatalf("Expected 1 favorite, got %d", len(favorites))
+		}
+		got := favorites[0]
+		if got.Snapshot == nil || got.Snapshot.ContentSummary != "更新後" || got.Snapshot.IssueStatus != "完了" {
+			t.Errorf("Unexpected snapshot: %+v", got.Snapshot)
+		}
+		if got.CollectionID != favorite.CollectionID || len(got.Tags) != 2 || got.Note != "メモ" {
+			t.Errorf("Unexpected annotations: %+v", got)
+		}
+
+		inCollection, err := repo.FindByCollectionID(favorite.CollectionID)
+		if err != nil {
+			t.Fatalf("Failed to find collection favorites: %v", err)
+		}
+		if len(inCollection) != 1 || inCollection[0].ItemID != "1" {
+			t.Errorf("Unexpected collection favorites: %+v", inCollection)
+		}
+	})
+
+	t.Run("SnapshotRoundTrip", func(t *testing.T) {
+		repo := newRepository(t)
+		userID := uniqueID(t, "user")
+		now := time.Now().Truncate(time.Millisecond)
+
+		// 全てのフィールドが実装によらず保存したとおりに読み出される
+		expected := model.FavoriteSnapshot{
+			ProjectID:      "10",
+			ProjectKey:     "PROJA",
+			ProjectName:    "プロジェクトA",
+			TypeID:         2,
+			Type:           "課題の更新",
+			IssueKey:       "PROJA-12",
+			IssueStatus:    "処理中",
+			ContentSummary: "ログイン機能の実装",
+			CreatedUser: model.User{
+				ID:          "100",
+				Name:        "山田太郎",
+				RoleType:    2,
+				Lang:        "ja",
+				MailAddress: "yamada@example.com",
+			},
+			Created:     now.Add(-time.Hour),
+			URL:         "https://example.backlog.jp/view/PROJA-12",
+			RefreshedAt: now,
+		}
+		favorite := newFavorite(userID, "1", now)
+		snapshot := expected
+		favorite.Snapshot = &snapshot
+		mustSave(t, repo, favorite)
+
+		favorites := mustFindByUserID(t, repo, userID)
+		if len(favorites) != 1 || favorites[0].Snapshot == nil {
+			t.Fatalf("Expected 1 favorite with a snapshot, got %+v", favorites)
+		}
+		got := *favorites[0].Snapshot
+		if !got.Created.Equal(expected.Created) || !got.RefreshedAt.Equal(expected.RefreshedAt) {
+			t.Errorf("Expected times %v and %v, got %v and %v", expected.Created, expected.RefreshedAt, got.Created, got.RefreshedAt)
+		}
+		got.Created, got.RefreshedAt = expected.Created, expected.RefreshedAt
+		if got != expected {
+			t.Errorf("Expected snapshot %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("CollectionsAndMembers", func(t *testing.T) {
+		repo := newRepository(t)
+		owner, member := uniqueID(t, "owner"), uniqueID(t, "member")
+		now := time.Now().Truncate(time.Millisecond)
+
+		if _, err := repo.FindCollection(uniqueID(t, "missing")); !errors.Is(err, model.ErrCollectionNotFound) {
+			t.Errorf("Expected ErrCollectionNotFound, got %v", err)
+		}
+
+		collection := &model.Collection{ID: uniqueID(t, "collection"), UserID: owner, Name: "リリース", CreatedAt: now, UpdatedAt: now}
+		if err := repo.SaveCollection(collection); err != nil {
+			t.Fatalf("Failed to save collection: %v", err)
+		}
+		collection.Name = "リリース2"
+		if err := repo.SaveCollection(collection); err != nil {
+			t.Fatalf("Failed to update collection: %v", err)
+		}
+
+		owned, err := repo.FindCollectionsByUserID(owner)
+		if err != nil {
+			t.Fatalf("Failed to find collections: %v", err)
+		}
+		if len(owned) != 1 || owned[0].Name != "リリース2" {
+			t.Errorf("Unexpected owned collections: %+v", owned)
+		}
+
+		if err := repo.SaveCollectionMember(&model.CollectionMember{CollectionID: collection.ID, UserID: member, Role: model.CollectionRoleViewer, AddedAt: now}); err != nil {
+			t.Fatalf("Failed to save member: %v", err)
+		}
+		if err := repo.SaveCollectionMember(&model.CollectionMember{CollectionID: collection.ID, UserID: member, Role: model.CollectionRoleEditor, AddedAt: now}); err != nil {
+			t.Fatalf("Failed to update member: %v", err)
+		}
+
+		members, err := repo.FindCollectionMembers(collection.ID)
+		if err != nil {
+			t.Fatalf("Failed to find members: %v", err)
+		}
+		if len(members) != 1 || members[0].Role != model.CollectionRoleEditor {
+			t.Errorf("Unexpected members: %+v", members)
+		}
+
+		shared, err := repo.FindCollectionsByMemberID(member)
+		if err != nil {
+			t.Fatalf("Failed to find shared collections: %v", err)
+		}
+		if len(shared) != 1 || shared[0].ID != collection.ID {
+			t.Errorf("Unexpected shared collections: %+v", shared)
+		}
+		if shared, _ := repo.FindCollectionsByMemberID(owner); len(shared) != 0 {
+			t.Errorf("Expected the owner not to be stored as a member, got %+v", shared)
+		}
+
+		if err := repo.DeleteCollectionMember(collection.ID, member); err != nil {
+			t.Fatalf("Failed to delete member: %v", err)
+		}
+		if err := repo.DeleteCollectionMember(collection.ID, member); err != nil {
+			t.Fatalf("Expected deleting a missing member to succeed, got %v", err)
+		}
+		if err := repo.DeleteCollection(collection.ID); err != nil {
+			t.Fatalf("Failed to delete collection: %v", err)
+		}
+		if _, err := repo.FindCollection(collection.ID); !errors.Is(err, model.ErrCollectionNotFound) {
+			t.Errorf("Expected ErrCollectionNotFound after delete, got %v", err)
+		}
+	})
+}
+
+// newFavorite はテスト用のお気に入りを生成
+func newFavorite(userID string, itemID string, createdAt time.Time) *model.Favorite {
+	return &model.Favorite{
+		ID:        userID + "-" + itemID,
+		UserID:    userID,
+		ItemID:    itemID,
+		CreatedAt: createdAt,
+	}
+}
+
+func mustSave(t *testing.T, repo model.FavoriteRepository, favorite *model.Favorite) {
+	t.Helper()
+	if err := repo.Save(favorite); err != nil {
+		t.Fatalf("Failed to save favorite: %v", err)
+	}
+}
+
+func mustFindByUserID(t *testing.T, repo model.FavoriteRepository, userID string) []*model.Favorite {
+	t.Helper()
+	favorites, err := repo.FindByUserID(userID)
+	if err != nil {
+		t.Fatalf("Failed to find favorites: %v", err)
+	}
+	return favorites
+}
+
+func mustExist(t *testing.T, repo model.FavoriteRepository, userID string, itemID string) bool {
+	t.Helper()
+	exists, err := repo.Exists(userID, itemID)
+	if err != nil {
+		t.Fatalf("Failed to check favorite: %v", err)
+	}
+	return exists
+}
+
+// uniqueID はストレージを共有する実装でもテスト間で衝突しないIDを生成
+func uniqueID(t *testing.T, prefix string) string {
+	return prefix + "-" + t.Name() + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
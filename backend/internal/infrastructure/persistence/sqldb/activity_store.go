@@ -0,0 +1,164 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// ActivityStore はdatabase/sqlを使った更新情報ストアの実装
+type ActivityStore struct {
+	db *DB
+}
+
+// NewActivityStore はActivityStoreのインスタンスを生成
+func NewActivityStore(db *DB) *ActivityStore {
+	return &ActivityStore{db: db}
+}
+
+// SaveActivities は更新情報と取り込み状況を1つのトランザクションで保存
+// 取り込み状況は保存済みのバージョンが1つ前の場合だけ更新し、それ以外はErrIngestStateConflictを返す
+func (s *ActivityStore) SaveActivities(userID string, items []*model.BacklogItem, state model.IngestState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ingest state: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result sql.Result
+	if state.Version == 1 {
+		result, err = tx.Exec(s.db.rebind(`INSERT INTO activity_ingest_states (user_id, state, version) VALUES (?, ?, ?)
+			ON CONFLICT (user_id) DO NOTHING`), userID, string(data), state.Version)
+	} else {
+		result, err = tx.Exec(s.db.rebind("UPDATE activity_ingest_states SET state = ?, version = ? WHERE user_id = ? AND version = ?"),
+			string(data), state.Version, userID, state.Version-1)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save ingest state: %w", err)
+	}
+	if err := requireAffected(result, model.ErrIngestStateConflict); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		id, err := strconv.Atoi(item.ID)
+		if err != nil {
+			continue
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal activity: %w", err)
+		}
+		_, err = tx.Exec(s.db.rebind(`INSERT INTO activity_documents (user_id, activity_id, item) VALUES (?, ?, ?)
+			ON CONFLICT (user_id, activity_id) DO UPDATE SET item = excluded.item`), userID, id, string(data))
+		if err != nil {
+			return fmt.Errorf("failed to save activity: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit activities: %w", err)
+	}
+	return nil
+}
+
+// LoadIngestState は保存済みの取り込み状況を取得
+func (s *ActivityStore) LoadIngestState(userID string) (model.IngestState, bool, error) {
+	var (
+		state model.IngestState
+		data  string
+	)
+	err := s.db.queryRow("SELECT state FROM activity_ingest_states WHERE user_id = ?", userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return state, false, nil
+	}
+	if err != nil {
+		return state, false, fmt.Errorf("failed to get ingest state: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return state, false, fmt.Errorf("failed to unmarshal ingest state: %w", err)
+	}
+	return state, true, nil
+}
+
+// LoadActivities は保存済みの更新情報を新しい順に取得
+func (s *ActivityStore) LoadActivities(userID string) ([]*model.BacklogItem, error) {
+	rows, err := s.db.query("SELECT item FROM activity_documents WHERE user_id = ? ORDER BY activity_id DESC", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activities: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]*model.BacklogItem, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		var item model.BacklogItem
+		if err := json.Unmarshal([]byte(data), &item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal activity: %w", err)
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read activities: %w", err)
+	}
+
+	return items, nil
+}
+
+// PruneActivities はoldestIDより古い更新情報を削除
+func (s *ActivityStore) PruneActivities(userID string, oldestID int) error {
+	_, err := s.db.exec("DELETE FROM activity_documents WHERE user_id = ? AND activity_id < ?", userID, oldestID)
+	if err != nil {
+		return fmt.Errorf("failed to prune activities: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteActivities はユーザーの更新情報と取り込み状況を削除
+func (s *ActivityStore) DeleteActivities(userID string) error {
+	if _, err := s.db.exec("DELETE FROM activity_documents WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete activities: %w", err)
+	}
+	if _, err := s.db.exec("DELETE FROM activity_ingest_states WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("failed to delete ingest state: %w", err)
+	}
+
+	return nil
+}
+
+// ActivityUsers は取り込み状況を保存しているユーザーIDを取得
+func (s *ActivityStore) ActivityUsers() ([]string, error) {
+	rows, err := s.db.query("SELECT user_id FROM activity_ingest_states")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query activity users: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]string, 0)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan activity user: %w", err)
+		}
+		users = append(users, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read activity users: %w", err)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,300 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/encryption"
+)
+
+// AuthRepository はdatabase/sqlを使った認証リポジトリの実装
+// アクセストークン・リフレッシュトークン・code_verifierはエンベロープ暗号化して保存する
+type AuthRepository struct {
+	db       *DB
+	envelope *encryption.Envelope
+}
+
+// NewAuthRepository はAuthRepositoryのインスタンスを生成
+func NewAuthRepository(db *DB, envelope *encryption.Envelope) *AuthRepository {
+	return &AuthRepository{
+		db:       db,
+		envelope: envelope,
+	}
+}
+
+// SaveToken はトークンを暗号化して保存
+func (r *AuthRepository) SaveToken(token *model.AuthToken) error {
+	data, err := r.seal(tokenIdentity(token.UserID), token)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.exec(`INSERT INTO auth_tokens (user_id, data) VALUES (?, ?)
+		ON CONFLICT (user_id) DO UPDATE SET data = excluded.data`, token.UserID, data)
+	if err != nil {
+		return fmt.Errorf("failed to save token: %w", err)
+	}
+
+	return nil
+}
+
+// GetTokenByUserID はユーザーIDからトークンを取得
+func (r *AuthRepository) GetTokenByUserID(userID string) (*model.AuthToken, error) {
+	var data string
+	err := r.db.queryRow("SELECT data FROM auth_tokens WHERE user_id = ?", userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	// 別のユーザーの行からコピーされたなど、この行のものとして復号できないトークンは存在しないものとして扱う
+	var token model.AuthToken
+	if err := r.open(tokenIdentity(userID), data, &token); errors.Is(err, encryption.ErrInvalidCiphertext) {
+		return nil, model.ErrTokenNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// DeleteToken はトークンを削除
+func (r *AuthRepository) DeleteToken(userID string) error {
+	_, err := r.db.exec("DELETE FROM auth_tokens WHERE user_id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+
+	return nil
+}
+
+// GetToken はユーザーIDからトークンを取得（GetTokenByUserIDのエイリアス）
+func (r *AuthRepository) GetToken(userID string) (*model.AuthToken, error) {
+	return r.GetTokenByUserID(userID)
+}
+
+// GetAllTokens は全トークンを取得
+func (r *AuthRepository) GetAllTokens() ([]*model.AuthToken, error) {
+	rows, err := r.db.query("SELECT user_id, data FROM auth_tokens")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []*model.AuthToken
+	for rows.Next() {
+		var userID, data string
+		if err := rows.Scan(&userID, &data); err != nil {
+			return nil, fmt.Errorf("failed to scan token: %w", err)
+		}
+
+		// 復号できないトークンは再ログインするまで使えないため対象外にする
+		var token model.AuthToken
+		if err := r.open(tokenIdentity(userID), data, &token); errors.Is(err, encryption.ErrInvalidCiphertext) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, &token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// SaveSession はセッションを保存
+// DynamoDBのTTLの代わりに、保存のたびに期限切れのセッションを削除する
+func (r *AuthRepository) SaveSession(session *model.Session) error {
+	if err := r.deleteExpired("auth_sessions"); err != nil {
+		return err
+	}
+
+	_, err := r.db.exec(`INSERT INTO auth_sessions (id, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET user_id = excluded.user_id, created_at = excluded.created_at, expires_at = excluded.expires_at`,
+		session.ID, session.UserID, unixNano(session.CreatedAt), unixNano(session.ExpiresAt))
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession はセッションIDからセッションを取得
+func (r *AuthRepository) GetSession(sessionID string) (*model.Session, error) {
+	var (
+		session              = model.Session{ID: sessionID}
+		createdAt, expiresAt int64
+	)
+	err := r.db.queryRow("SELECT user_id, created_at, expires_at FROM auth_sessions WHERE id = ?", sessionID).Scan(&session.UserID, &createdAt, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("session not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	session.CreatedAt = fromUnixNano(createdAt)
+	session.ExpiresAt = fromUnixNano(expiresAt)
+	return &session, nil
+}
+
+// DeleteSession はセッションを削除
+func (r *AuthRepository) DeleteSession(sessionID string) error {
+	_, err := r.db.exec("DELETE FROM auth_sessions WHERE id = ?", sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSessionsByUserID はユーザーの全セッションを削除
+func (r *AuthRepository) DeleteSessionsByUserID(userID string) error {
+	_, err := r.db.exec("DELETE FROM auth_sessions WHERE user_id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	return nil
+}
+
+// SaveExchangeCode は交換コードを保存
+func (r *AuthRepository) SaveExchangeCode(code *model.ExchangeCode) error {
+	if err := r.deleteExpired("auth_exchange_codes"); err != nil {
+		return err
+	}
+
+	_, err := r.db.exec("INSERT INTO auth_exchange_codes (code, user_id, expires_at) VALUES (?, ?, ?)",
+		code.Code, code.UserID, unixNano(code.ExpiresAt))
+	if err != nil {
+		return fmt.Errorf("failed to save exchange code: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeExchangeCode は交換コードを削除し、削除前の内容を返す
+// DELETE ... RETURNINGで取得と削除を1回の操作で行うため、交換コードの再利用を防げる
+func (r *AuthRepository) ConsumeExchangeCode(code string) (*model.ExchangeCode, error) {
+	var (
+		exchangeCode = model.ExchangeCode{Code: code}
+		expiresAt    int64
+	)
+	err := r.db.queryRow("DELETE FROM auth_exchange_codes WHERE code = ? RETURNING user_id, expires_at", code).Scan(&exchangeCode.UserID, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("exchange code not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume exchange code: %w", err)
+	}
+
+	exchangeCode.ExpiresAt = fromUnixNano(expiresAt)
+	return &exchangeCode, nil
+}
+
+// SaveOAuthState はOAuthのstateを保存（code_verifierは暗号化）
+func (r *AuthRepository) SaveOAuthState(state *model.OAuthState) error {
+	if err := r.deleteExpired("auth_oauth_states"); err != nil {
+		return err
+	}
+
+	sealed, err := r.envelope.Seal([]byte(state.CodeVerifier), stateIdentity(state.State))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.exec("INSERT INTO auth_oauth_states (state, data, expires_at) VALUES (?, ?, ?)",
+		state.State, base64.StdEncoding.EncodeToString(sealed), unixNano(state.ExpiresAt))
+	if err != nil {
+		return fmt.Errorf("failed to save oauth state: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeOAuthState はOAuthのstateを削除し、削除前の内容を返す
+func (r *AuthRepository) ConsumeOAuthState(state string) (*model.OAuthState, error) {
+	var (
+		data      string
+		expiresAt int64
+	)
+	err := r.db.queryRow("DELETE FROM auth_oauth_states WHERE state = ? RETURNING data, expires_at", state).Scan(&data, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.New("oauth state not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume oauth state: %w", err)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode oauth state: %w", err)
+	}
+	codeVerifier, err := r.envelope.Open(sealed, stateIdentity(state))
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OAuthState{
+		State:        state,
+		CodeVerifier: string(codeVerifier),
+		ExpiresAt:    fromUnixNano(expiresAt),
+	}, nil
+}
+
+// deleteExpired は有効期限を過ぎた項目をテーブルから削除する内部メソッド
+func (r *AuthRepository) deleteExpired(table string) error {
+	_, err := r.db.exec("DELETE FROM "+table+" WHERE expires_at < ?", time.Now().UnixNano())
+	if err != nil {
+		return fmt.Errorf("failed to delete expired items from %s: %w", table, err)
+	}
+
+	return nil
+}
+
+// seal は値をJSONにしてエンベロープ暗号化し、Base64で返す（identityは保存先の行の識別子）
+func (r *AuthRepository) seal(identity []byte, v interface{}) (string, error) {
+	plaintext, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := r.envelope.Seal(plaintext, identity)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open はBase64のエンベロープ暗号化された値を復号してJSONデコード（identityが暗号化時と異なる場合は失敗する）
+func (r *AuthRepository) open(identity []byte, data string, v interface{}) error {
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := r.envelope.Open(sealed, identity)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, v)
+}
+
+// tokenIdentity は暗号化したトークンを保存する行の識別子（暗号化の関連データ）
+func tokenIdentity(userID string) []byte {
+	return []byte("auth_tokens:" + userID)
+}
+
+// stateIdentity は暗号化したcode_verifierを保存する行の識別子（暗号化の関連データ）
+func stateIdentity(state string) []byte {
+	return []byte("auth_oauth_states:" + state)
+}
@@ -0,0 +1,140 @@
+package sqldb
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/encryption"
+)
+
+func newTestAuthRepository(t *testing.T) *AuthRepository {
+	t.Helper()
+
+	key := make([]byte, 32)
+	rand.Read(key)
+	envelope, err := encryption.NewEnvelope(key)
+	if err != nil {
+		t.Fatalf("Failed to create envelope: %v", err)
+	}
+
+	return NewAuthRepository(newTestDB(t), envelope)
+}
+
+func TestAuthRepository_TokenRoundTrip(t *testing.T) {
+	repo := newTestAuthRepository(t)
+
+	token := &model.AuthToken{AccessToken: "access", TokenType: "Bearer", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"}
+	if err := repo.SaveToken(token); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	token.AccessToken = "rotated"
+	if err := repo.SaveToken(token); err != nil {
+		t.Fatalf("Failed to overwrite token: %v", err)
+	}
+
+	got, err := repo.GetTokenByUserID("user1")
+	if err != nil {
+		t.Fatalf("Failed to get token: %v", err)
+	}
+	if got.AccessToken != "rotated" || got.RefreshToken != "refresh" {
+		t.Errorf("Unexpected token: %+v", got)
+	}
+
+	// 平文のトークンがデータベースに保存されていないこと
+	var data string
+	if err := repo.db.queryRow("SELECT data FROM auth_tokens WHERE user_id = ?", "user1").Scan(&data); err != nil {
+		t.Fatalf("Failed to read raw token: %v", err)
+	}
+	if data == "" || strings.Contains(data, "rotated") {
+		t.Errorf("Expected token to be encrypted, got %q", data)
+	}
+
+	if err := repo.DeleteToken("user1"); err != nil {
+		t.Fatalf("Failed to delete token: %v", err)
+	}
+	if _, err := repo.GetTokenByUserID("user1"); err != model.ErrTokenNotFound {
+		t.Errorf("Expected ErrTokenNotFound, got %v", err)
+	}
+}
+
+// 別のユーザーの行にコピーされた暗号化トークンは復号されないことを確認する
+func TestAuthRepository_RejectsTokenCopiedFromOtherUser(t *testing.T) {
+	repo := newTestAuthRepository(t)
+
+	for _, userID := range []string{"user1", "user2"} {
+		if err := repo.SaveToken(&model.AuthToken{AccessToken: "access-" + userID, ExpiresAt: time.Now().Add(time.Hour), UserID: userID}); err != nil {
+			t.Fatalf("Failed to save token: %v", err)
+		}
+	}
+
+	if _, err := repo.db.exec("UPDATE auth_tokens SET data = (SELECT data FROM auth_tokens WHERE user_id = ?) WHERE user_id = ?", "user1", "user2"); err != nil {
+		t.Fatalf("Failed to copy token: %v", err)
+	}
+
+	if _, err := repo.GetTokenByUserID("user2"); err != model.ErrTokenNotFound {
+		t.Errorf("Expected ErrTokenNotFound for copied token, got %v", err)
+	}
+	tokens, err := repo.GetAllTokens()
+	if err != nil {
+		t.Fatalf("Failed to get all tokens: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].UserID != "user1" {
+		t.Errorf("Expected only user1 token, got %+v", tokens)
+	}
+}
+
+func TestAuthRepository_ConsumeOnce(t *testing.T) {
+	repo := newTestAuthRepository(t)
+	expiresAt := time.Now().Add(time.Minute)
+
+	if err := repo.SaveExchangeCode(&model.ExchangeCode{Code: "code1", UserID: "user1", ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("Failed to save exchange code: %v", err)
+	}
+	code, err := repo.ConsumeExchangeCode("code1")
+	if err != nil || code.UserID != "user1" {
+		t.Fatalf("Unexpected exchange code: %+v, %v", code, err)
+	}
+	if _, err := repo.ConsumeExchangeCode("code1"); err == nil {
+		t.Error("Expected exchange code to be consumed only once")
+	}
+
+	if err := repo.SaveOAuthState(&model.OAuthState{State: "state1", CodeVerifier: "verifier", ExpiresAt: expiresAt}); err != nil {
+		t.Fatalf("Failed to save oauth state: %v", err)
+	}
+	state, err := repo.ConsumeOAuthState("state1")
+	if err != nil || state.CodeVerifier != "verifier" {
+		t.Fatalf("Unexpected oauth state: %+v, %v", state, err)
+	}
+	if _, err := repo.ConsumeOAuthState("state1"); err == nil {
+		t.Error("Expected oauth state to be consumed only once")
+	}
+}
+
+func TestAuthRepository_Sessions(t *testing.T) {
+	repo := newTestAuthRepository(t)
+	now := time.Now()
+
+	for _, session := range []*model.Session{
+		{ID: "s1", UserID: "user1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "s2", UserID: "user1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "s3", UserID: "user2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	} {
+		if err := repo.SaveSession(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+
+	if err := repo.DeleteSessionsByUserID("user1"); err != nil {
+		t.Fatalf("Failed to delete sessions: %v", err)
+	}
+	if _, err := repo.GetSession("s1"); err == nil {
+		t.Error("Expected session s1 to be deleted")
+	}
+	session, err := repo.GetSession("s3")
+	if err != nil || session.UserID != "user2" || !session.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("Unexpected session: %+v, %v", session, err)
+	}
+}
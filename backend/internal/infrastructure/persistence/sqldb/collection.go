@@ -0,0 +1,170 @@
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// collectionColumns はコレクションを検索する際の列（scanCollectionの順序と一致させる）
+const collectionColumns = "c.id, c.user_id, c.name, c.description, c.created_at, c.updated_at"
+
+// SaveCollection はコレクションを保存
+func (r *FavoriteRepository) SaveCollection(collection *model.Collection) error {
+	_, err := r.db.exec(`INSERT INTO collections (id, user_id, name, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, description = excluded.description, updated_at = excluded.updated_at`,
+		collection.ID, collection.UserID, collection.Name, collection.Description, unixNano(collection.CreatedAt), unixNano(collection.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save collection: %w", err)
+	}
+
+	return nil
+}
+
+// FindCollection はIDからコレクションを取得
+func (r *FavoriteRepository) FindCollection(collectionID string) (*model.Collection, error) {
+	collection, err := scanCollection(r.db.queryRow("SELECT "+collectionColumns+" FROM collections c WHERE c.id = ?", collectionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, model.ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	return collection, nil
+}
+
+// FindCollectionsByUserID はユーザーIDからコレクションを検索
+func (r *FavoriteRepository) FindCollectionsByUserID(userID string) ([]*model.Collection, error) {
+	rows, err := r.db.query("SELECT "+collectionColumns+" FROM collections c WHERE c.user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collections: %w", err)
+	}
+
+	return scanCollections(rows)
+}
+
+// DeleteCollection はコレクションを削除
+func (r *FavoriteRepository) DeleteCollection(collectionID string) error {
+	_, err := r.db.exec("DELETE FROM collections WHERE id = ?", collectionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// FindByCollectionID はコレクションに入っているお気に入りを検索
+func (r *FavoriteRepository) FindByCollectionID(collectionID string) ([]*model.Favorite, error) {
+	rows, err := r.db.query("SELECT "+favoriteColumns+" FROM favorites WHERE collection_id = ? ORDER BY created_at DESC, item_id DESC", collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collection favorites: %w", err)
+	}
+
+	return scanFavorites(rows)
+}
+
+// SaveCollectionMember はコレクションのメンバーを保存
+func (r *FavoriteRepository) SaveCollectionMember(member *model.CollectionMember) error {
+	_, err := r.db.exec(`INSERT INTO collection_members (collection_id, user_id, role, added_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (collection_id, user_id) DO UPDATE SET role = excluded.role`,
+		member.CollectionID, member.UserID, string(member.Role), unixNano(member.AddedAt))
+	if err != nil {
+		return fmt.Errorf("failed to save collection member: %w", err)
+	}
+
+	return nil
+}
+
+// FindCollectionMembers はコレクションのメンバーを検索
+func (r *FavoriteRepository) FindCollectionMembers(collectionID string) ([]*model.CollectionMember, error) {
+	rows, err := r.db.query("SELECT collection_id, user_id, role, added_at FROM collection_members WHERE collection_id = ?", collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collection members: %w", err)
+	}
+	defer rows.Close()
+
+	members := make([]*model.CollectionMember, 0)
+	for rows.Next() {
+		var (
+			member  model.CollectionMember
+			role    string
+			addedAt int64
+		)
+		if err := rows.Scan(&member.CollectionID, &member.UserID, &role, &addedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan collection member: %w", err)
+		}
+		member.Role = model.CollectionRole(role)
+		member.AddedAt = fromUnixNano(addedAt)
+		members = append(members, &member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read collection members: %w", err)
+	}
+
+	return members, nil
+}
+
+// FindCollectionsByMemberID はユーザーが共有されているコレクションを検索
+func (r *FavoriteRepository) FindCollectionsByMemberID(userID string) ([]*model.Collection, error) {
+	rows, err := r.db.query("SELECT "+collectionColumns+" FROM collections c JOIN collection_members m ON m.collection_id = c.id WHERE m.user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shared collections: %w", err)
+	}
+
+	return scanCollections(rows)
+}
+
+// DeleteCollectionMember はコレクションのメンバーを削除
+func (r *FavoriteRepository) DeleteCollectionMember(collectionID string, userID string) error {
+	_, err := r.db.exec("DELETE FROM collection_members WHERE collection_id = ? AND user_id = ?", collectionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection member: %w", err)
+	}
+
+	return nil
+}
+
+// scanner はsql.Rowとsql.Rowsに共通する読み取りメソッド
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCollection は1行をコレクションに変換
+func scanCollection(row scanner) (*model.Collection, error) {
+	var (
+		collection           model.Collection
+		createdAt, updatedAt int64
+	)
+	err := row.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.Description, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.CreatedAt = fromUnixNano(createdAt)
+	collection.UpdatedAt = fromUnixNano(updatedAt)
+	return &collection, nil
+}
+
+// scanCollections は検索結果の全行をコレクションに変換し、rowsを閉じる
+func scanCollections(rows *sql.Rows) ([]*model.Collection, error) {
+	defer rows.Close()
+
+	collections := make([]*model.Collection, 0)
+	for rows.Next() {
+		collection, err := scanCollection(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read collections: %w", err)
+	}
+
+	return collections, nil
+}
@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestDB はテスト用のデータベースに接続する
+// POSTGRES_TEST_DSNが設定されている場合はPostgreSQL、それ以外は一時ディレクトリのSQLiteを使用する
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	dialect, dsn := DialectSQLite, "file:"+filepath.Join(t.TempDir(), "test.db")
+	if postgresDSN := os.Getenv("POSTGRES_TEST_DSN"); postgresDSN != "" {
+		dialect, dsn = DialectPostgres, postgresDSN
+	}
+
+	db, err := Open(dialect, dsn)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestOpen_MigrationsAreIdempotent(t *testing.T) {
+	db := newTestDB(t)
+
+	// 2回目は適用済みのマイグレーションをスキップする
+	if err := db.Migrate(); err != nil {
+		t.Fatalf("Failed to migrate twice: %v", err)
+	}
+
+	var count int
+	if err := db.queryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", "0001_create_tables").Scan(&count); err != nil {
+		t.Fatalf("Failed to query schema_migrations: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected migration to be recorded once, got %d", count)
+	}
+}
+
+func TestDB_Rebind(t *testing.T) {
+	postgres := &DB{dialect: DialectPostgres}
+	if got := postgres.rebind("SELECT * FROM t WHERE a = ? AND b = ?"); got != "SELECT * FROM t WHERE a = $1 AND b = $2" {
+		t.Errorf("Unexpected postgres query: %s", got)
+	}
+
+	sqlite := &DB{dialect: DialectSQLite}
+	if got := sqlite.rebind("SELECT ?"); got != "SELECT ?" {
+		t.Errorf("Unexpected sqlite query: %s", got)
+	}
+}
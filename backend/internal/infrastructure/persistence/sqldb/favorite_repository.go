@@ -0,0 +1,256 @@
+package sqldb
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// favoriteColumns はお気に入りを検索する際の列（scanFavoriteの順序と一致させる）
+const favoriteColumns = "id, user_id, item_id, created_at, snapshot, collection_id, tags, note"
+
+// FavoriteRepository はdatabase/sqlを使ったお気に入りリポジトリの実装
+// お気に入りはユーザーIDと更新情報IDを主キーとして保存する
+type FavoriteRepository struct {
+	db *DB
+}
+
+// NewFavoriteRepository はFavoriteRepositoryのインスタンスを生成
+func NewFavoriteRepository(db *DB) *FavoriteRepository {
+	return &FavoriteRepository{
+		db: db,
+	}
+}
+
+// FindByUserID はユーザーIDからお気に入りを登録日時の新しい順に検索
+func (r *FavoriteRepository) FindByUserID(userID string) ([]*model.Favorite, error) {
+	rows, err := r.db.query("SELECT "+favoriteColumns+" FROM favorites WHERE user_id = ? ORDER BY created_at DESC, item_id DESC", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favorites: %w", err)
+	}
+
+	return scanFavorites(rows)
+}
+
+// favoriteCursor はページングのカーソルに含める、前のページの最後のお気に入りの並び順のキー
+type favoriteCursor struct {
+	CreatedAt int64  `json:"createdAt"`
+	ItemID    string `json:"itemId"`
+}
+
+// encodeFavoriteCursor はお気に入りの並び順のキーを不透明なカーソルに変換
+func encodeFavoriteCursor(favorite *model.Favorite) string {
+	b, _ := json.Marshal(favoriteCursor{CreatedAt: unixNano(favorite.CreatedAt), ItemID: favorite.ItemID})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeFavoriteCursor はカーソルを並び順のキーに変換。空のカーソルは先頭を表すためnilを返す
+func decodeFavoriteCursor(cursor string) (*favoriteCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, model.ErrInvalidCursor
+	}
+
+	var c favoriteCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ItemID == "" {
+		return nil, model.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// FindPageByUserID はユーザーIDからお気に入りを登録日時の新しい順に1ページ分検索
+// カーソルは前のページの最後の(created_at, item_id)を指すため、ページの間に追加・削除されても重複や抜けが起きない
+func (r *FavoriteRepository) FindPageByUserID(userID string, page model.PageRequest) (*model.FavoritePage, error) {
+	cursor, err := decodeFavoriteCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	size := page.Size()
+
+	// 次のページがあるか判定するため1件多く取得する
+	query := "SELECT " + favoriteColumns + " FROM favorites WHERE user_id = ?"
+	args := []interface{}{userID}
+	if cursor != nil {
+		query += " AND (created_at, item_id) < (?, ?)"
+		args = append(args, cursor.CreatedAt, cursor.ItemID)
+	}
+	query += " ORDER BY created_at DESC, item_id DESC LIMIT ?"
+	args = append(args, size+1)
+
+	rows, err := r.db.query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favorites: %w", err)
+	}
+
+	favorites, err := scanFavorites(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.FavoritePage{Favorites: favorites}
+	if len(favorites) > size {
+		result.Favorites = favorites[:size]
+		result.NextCursor = encodeFavoriteCursor(result.Favorites[size-1])
+	}
+
+	return result, nil
+}
+
+// Save はお気に入りを保存
+// 同じ更新情報のお気に入りが既にある場合は上書きせずにErrFavoriteAlreadyExistsを返す
+func (r *FavoriteRepository) Save(favorite *model.Favorite) error {
+	snapshot, err := marshalSnapshot(favorite.Snapshot)
+	if err != nil {
+		return err
+	}
+	tags, err := marshalTags(favorite.Tags)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.exec(`INSERT INTO favorites (id, user_id, item_id, created_at, snapshot, collection_id, tags, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id, item_id) DO NOTHING`,
+		favorite.ID, favorite.UserID, favorite.ItemID, unixNano(favorite.CreatedAt), snapshot, favorite.CollectionID, tags, favorite.Note)
+	if err != nil {
+		return fmt.Errorf("failed to save favorite: %w", err)
+	}
+
+	// 主キーが衝突して挿入されなかった場合は既に存在する
+	return requireAffected(result, model.ErrFavoriteAlreadyExists)
+}
+
+// Update はお気に入りのスナップショットを更新
+// 更新中に削除されたお気に入りは再作成しない
+func (r *FavoriteRepository) Update(favorite *model.Favorite) error {
+	snapshot, err := marshalSnapshot(favorite.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.exec("UPDATE favorites SET snapshot = ? WHERE user_id = ? AND item_id = ?", snapshot, favorite.UserID, favorite.ItemID)
+	if err != nil {
+		return fmt.Errorf("failed to update favorite: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAnnotations はお気に入りのコレクション・タグ・メモを更新
+func (r *FavoriteRepository) UpdateAnnotations(favorite *model.Favorite) error {
+	tags, err := marshalTags(favorite.Tags)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.exec("UPDATE favorites SET collection_id = ?, tags = ?, note = ? WHERE user_id = ? AND item_id = ?",
+		favorite.CollectionID, tags, favorite.Note, favorite.UserID, favorite.ItemID)
+	if err != nil {
+		return fmt.Errorf("failed to update favorite annotations: %w", err)
+	}
+
+	return requireAffected(result, model.ErrFavoriteNotFound)
+}
+
+// Delete はお気に入りを削除
+func (r *FavoriteRepository) Delete(userID string, itemID string) error {
+	result, err := r.db.exec("DELETE FROM favorites WHERE user_id = ? AND item_id = ?", userID, itemID)
+	if err != nil {
+		return fmt.Errorf("failed to delete favorite: %w", err)
+	}
+
+	return requireAffected(result, model.ErrFavoriteNotFound)
+}
+
+// Exists はお気に入りが存在するかチェック
+func (r *FavoriteRepository) Exists(userID string, itemID string) (bool, error) {
+	var count int
+	err := r.db.queryRow("SELECT COUNT(*) FROM favorites WHERE user_id = ? AND item_id = ?", userID, itemID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check favorite: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// requireAffected は更新された行がない場合にerrNoRowsを返す
+func requireAffected(result sql.Result, errNoRows error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return errNoRows
+	}
+	return nil
+}
+
+// scanFavorites は検索結果の全行をお気に入りに変換し、rowsを閉じる
+func scanFavorites(rows *sql.Rows) ([]*model.Favorite, error) {
+	defer rows.Close()
+
+	favorites := make([]*model.Favorite, 0)
+	for rows.Next() {
+		var (
+			favorite  model.Favorite
+			createdAt int64
+			snapshot  sql.NullString
+			tags      string
+		)
+		err := rows.Scan(&favorite.ID, &favorite.UserID, &favorite.ItemID, &createdAt, &snapshot, &favorite.CollectionID, &tags, &favorite.Note)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan favorite: %w", err)
+		}
+
+		favorite.CreatedAt = fromUnixNano(createdAt)
+		if snapshot.Valid {
+			favorite.Snapshot = &model.FavoriteSnapshot{}
+			if err := json.Unmarshal([]byte(snapshot.String), favorite.Snapshot); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal favorite snapshot: %w", err)
+			}
+		}
+		if err := json.Unmarshal([]byte(tags), &favorite.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal favorite tags: %w", err)
+		}
+
+		favorites = append(favorites, &favorite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read favorites: %w", err)
+	}
+
+	return favorites, nil
+}
+
+// marshalSnapshot はスナップショットをJSONに変換（スナップショットがない場合はNULL）
+func marshalSnapshot(snapshot *model.FavoriteSnapshot) (sql.NullString, error) {
+	if snapshot == nil {
+		return sql.NullString{}, nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to marshal favorite snapshot: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// marshalTags はタグをJSON配列に変換（タグがない場合は空の配列）
+func marshalTags(tags []string) (string, error) {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	data, err := json.Marshal(tags)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal favorite tags: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,161 @@
+package sqldb
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+func TestFavoriteRepository_SaveAndPage(t *testing.T) {
+	repo := NewFavoriteRepository(newTestDB(t))
+	userID := "test-user-" + time.Now().Format("150405.000000")
+
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		itemID := strconv.Itoa(i)
+		favorite := &model.Favorite{
+			ID:        itemID,
+			UserID:    userID,
+			ItemID:    itemID,
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+			Snapshot:  &model.FavoriteSnapshot{ProjectName: "プロジェクトA", ContentSummary: "内容" + itemID},
+		}
+		if err := repo.Save(favorite); err != nil {
+			t.Fatalf("Failed to save favorite: %v", err)
+		}
+	}
+
+	// 同じ更新情報を再度保存すると重複エラーになり、登録日時も上書きされない
+	if err := repo.Save(&model.Favorite{ID: "dup", UserID: userID, ItemID: "1", CreatedAt: now.Add(time.Hour)}); err != model.ErrFavoriteAlreadyExists {
+		t.Fatalf("Expected ErrFavoriteAlreadyExists, got %v", err)
+	}
+
+	var itemIDs []string
+	cursor := ""
+	for {
+		page, err := repo.FindPageByUserID(userID, model.PageRequest{Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("Failed to find favorites: %v", err)
+		}
+		for _, favorite := range page.Favorites {
+			itemIDs = append(itemIDs, favorite.ItemID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(itemIDs) != 5 || itemIDs[0] != "5" || itemIDs[4] != "1" {
+		t.Errorf("Unexpected favorites order: %v", itemIDs)
+	}
+
+	favorites, err := repo.FindByUserID(userID)
+	if err != nil {
+		t.Fatalf("Failed to find favorites: %v", err)
+	}
+	if favorites[4].Snapshot == nil || favorites[4].Snapshot.ContentSummary != "内容1" || !favorites[4].CreatedAt.Equal(now.Add(time.Second)) {
+		t.Errorf("Unexpected favorite: %+v", favorites[4])
+	}
+
+	if _, err := repo.FindPageByUserID(userID, model.PageRequest{Cursor: "invalid"}); err != model.ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestFavoriteRepository_AnnotationsAndCollections(t *testing.T) {
+	repo := NewFavoriteRepository(newTestDB(t))
+
+	if err := repo.UpdateAnnotations(&model.Favorite{UserID: "user1", ItemID: "1"}); err != model.ErrFavoriteNotFound {
+		t.Errorf("Expected ErrFavoriteNotFound, got %v", err)
+	}
+
+	now := time.Now()
+	collection := &model.Collection{ID: "c1", UserID: "user1", Name: "リリース", CreatedAt: now, UpdatedAt: now}
+	if err := repo.SaveCollection(collection); err != nil {
+		t.Fatalf("Failed to save collection: %v", err)
+	}
+	if err := repo.SaveCollectionMember(&model.CollectionMember{CollectionID: "c1", UserID: "user2", Role: model.CollectionRoleViewer, AddedAt: now}); err != nil {
+		t.Fatalf("Failed to save member: %v", err)
+	}
+
+	if err := repo.Save(&model.Favorite{ID: "1", UserID: "user1", ItemID: "1", CreatedAt: now}); err != nil {
+		t.Fatalf("Failed to save favorite: %v", err)
+	}
+	if err := repo.UpdateAnnotations(&model.Favorite{UserID: "user1", ItemID: "1", CollectionID: "c1", Tags: []string{"重要"}, Note: "メモ"}); err != nil {
+		t.Fatalf("Failed to update annotations: %v", err)
+	}
+
+	favorites, err := repo.FindByCollectionID("c1")
+	if err != nil {
+		t.Fatalf("Failed to find collection favorites: %v", err)
+	}
+	if len(favorites) != 1 || favorites[0].Note != "メモ" || len(favorites[0].Tags) != 1 {
+		t.Errorf("Unexpected collection favorites: %+v", favorites)
+	}
+
+	shared, err := repo.FindCollectionsByMemberID("user2")
+	if err != nil {
+		t.Fatalf("Failed to find shared collections: %v", err)
+	}
+	if len(shared) != 1 || shared[0].Name != "リリース" {
+		t.Errorf("Unexpected shared collections: %+v", shared)
+	}
+
+	if err := repo.DeleteCollection("c1"); err != nil {
+		t.Fatalf("Failed to delete collection: %v", err)
+	}
+	if _, err := repo.FindCollection("c1"); err != model.ErrCollectionNotFound {
+		t.Errorf("Expected ErrCollectionNotFound, got %v", err)
+	}
+}
+
+// ページの間にお気に入りを削除・追加しても、次のページが重複したり抜けたりしないことを確認する
+func TestFavoriteRepository_PageKeysetCursor(t *testing.T) {
+	repo := NewFavoriteRepository(newTestDB(t))
+	userID := "test-user-" + time.Now().Format("150405.000000")
+
+	// 登録日時が同じお気に入りは更新情報IDの降順に並ぶ
+	now := time.Now()
+	for i := 1; i <= 6; i++ {
+		itemID := strconv.Itoa(i)
+		if err := repo.Save(&model.Favorite{ID: itemID, UserID: userID, ItemID: itemID, CreatedAt: now.Add(time.Duration(i/2) * time.Second)}); err != nil {
+			t.Fatalf("Failed to save favorite: %v", err)
+		}
+	}
+
+	page, err := repo.FindPageByUserID(userID, model.PageRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("Failed to find favorites: %v", err)
+	}
+	if len(page.Favorites) != 2 || page.Favorites[0].ItemID != "6" || page.Favorites[1].ItemID != "5" {
+		t.Fatalf("Unexpected first page: %+v", page.Favorites)
+	}
+
+	if err := repo.Delete(userID, "6"); err != nil {
+		t.Fatalf("Failed to delete favorite: %v", err)
+	}
+	if err := repo.Save(&model.Favorite{ID: "7", UserID: userID, ItemID: "7", CreatedAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Failed to save favorite: %v", err)
+	}
+
+	var itemIDs []string
+	cursor := page.NextCursor
+	for cursor != "" {
+		page, err := repo.FindPageByUserID(userID, model.PageRequest{Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("Failed to find favorites: %v", err)
+		}
+		for _, favorite := range page.Favorites {
+			itemIDs = append(itemIDs, favorite.ItemID)
+		}
+		cursor = page.NextCursor
+	}
+
+	expected := []string{"4", "3", "2", "1"}
+	if strings.Join(itemIDs, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v after the first page, got %v", expected, itemIDs)
+	}
+}
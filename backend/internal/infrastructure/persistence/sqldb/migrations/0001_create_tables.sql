@@ -0,0 +1,65 @@
+-- お気に入りとコレクション、認証情報のテーブルを作成する
+-- SQLiteとPostgreSQLの両方で実行できる型と構文のみを使用する（日時はUnixナノ秒、JSONはTEXTで保存）
+
+CREATE TABLE favorites (
+    user_id       TEXT   NOT NULL,
+    item_id       TEXT   NOT NULL,
+    id            TEXT   NOT NULL,
+    created_at    BIGINT NOT NULL,
+    snapshot      TEXT,
+    collection_id TEXT   NOT NULL DEFAULT '',
+    tags          TEXT   NOT NULL DEFAULT '[]',
+    note          TEXT   NOT NULL DEFAULT '',
+    PRIMARY KEY (user_id, item_id)
+);
+
+CREATE INDEX favorites_user_created_idx ON favorites (user_id, created_at);
+CREATE INDEX favorites_collection_idx ON favorites (collection_id);
+
+CREATE TABLE collections (
+    id          TEXT   NOT NULL PRIMARY KEY,
+    user_id     TEXT   NOT NULL,
+    name        TEXT   NOT NULL,
+    description TEXT   NOT NULL DEFAULT '',
+    created_at  BIGINT NOT NULL,
+    updated_at  BIGINT NOT NULL
+);
+
+CREATE INDEX collections_user_idx ON collections (user_id);
+
+CREATE TABLE collection_members (
+    collection_id TEXT   NOT NULL,
+    user_id       TEXT   NOT NULL,
+    role          TEXT   NOT NULL,
+    added_at      BIGINT NOT NULL,
+    PRIMARY KEY (collection_id, user_id)
+);
+
+CREATE INDEX collection_members_user_idx ON collection_members (user_id);
+
+-- トークンとcode_verifierはエンベロープ暗号化してBase64で保存する
+CREATE TABLE auth_tokens (
+    user_id TEXT NOT NULL PRIMARY KEY,
+    data    TEXT NOT NULL
+);
+
+CREATE TABLE auth_sessions (
+    id         TEXT   NOT NULL PRIMARY KEY,
+    user_id    TEXT   NOT NULL,
+    created_at BIGINT NOT NULL,
+    expires_at BIGINT NOT NULL
+);
+
+CREATE INDEX auth_sessions_user_idx ON auth_sessions (user_id);
+
+CREATE TABLE auth_exchange_codes (
+    code       TEXT   NOT NULL PRIMARY KEY,
+    user_id    TEXT   NOT NULL,
+    expires_at BIGINT NOT NULL
+);
+
+CREATE TABLE auth_oauth_states (
+    state      TEXT   NOT NULL PRIMARY KEY,
+    data       TEXT   NOT NULL,
+    expires_at BIGINT NOT NULL
+);
@@ -0,0 +1,16 @@
+-- 全文検索インデックスに取り込んだ更新情報と取り込み状況を保存するテーブルを作成する
+-- 更新情報と取り込み状況はJSONをTEXTで保存する
+
+CREATE TABLE activity_documents (
+    user_id     TEXT   NOT NULL,
+    activity_id BIGINT NOT NULL,
+    item        TEXT   NOT NULL,
+    PRIMARY KEY (user_id, activity_id)
+);
+
+-- versionは取り込みを保存するたびに増やし、複数のサーバーが同時に保存した場合に後から保存した方を失敗させる
+CREATE TABLE activity_ingest_states (
+    user_id TEXT   NOT NULL PRIMARY KEY,
+    state   TEXT   NOT NULL,
+    version BIGINT NOT NULL
+);
@@ -0,0 +1,26 @@
+package sqldb
+
+import (
+	"testing"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/repotest"
+)
+
+func TestFavoriteRepository_Conformance(t *testing.T) {
+	repotest.RunFavoriteRepositoryTests(t, func(t *testing.T) model.FavoriteRepository {
+		return NewFavoriteRepository(newTestDB(t))
+	})
+}
+
+func TestAuthRepository_Conformance(t *testing.T) {
+	repotest.RunAuthRepositoryTests(t, func(t *testing.T) model.AuthRepository {
+		return newTestAuthRepository(t)
+	})
+}
+
+func TestActivityStore_Conformance(t *testing.T) {
+	repotest.RunActivityStoreTests(t, func(t *testing.T) model.ActivityStore {
+		return NewActivityStore(newTestDB(t))
+	})
+}
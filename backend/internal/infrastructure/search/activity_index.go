@@ -0,0 +1,517 @@
+package search
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// fieldWeights はキーワードが一致したフィールドごとのスコアの重み
+var fieldWeights = map[string]float64{
+	"contentSummary": 3,
+	"createdUser":    2,
+	"projectName":    1.5,
+	"type":           1,
+	"id":             1,
+}
+
+// ActivityIndex はメモリ上に保持するユーザーごとの全文検索インデックス
+// 日本語を分かち書きせずに検索できるよう、正規化した文字列のユニグラムとバイグラムで転置インデックスを作成する
+// 永続化はActivityIngesterがmodel.ActivityStoreで行い、再起動後はストアから復元する
+type ActivityIndex struct {
+	mu                  sync.RWMutex
+	users               map[string]*userIndex
+	maxDocumentsPerUser int
+}
+
+// userIndex は1ユーザー分のインデックス
+type userIndex struct {
+	documents map[int]*document
+	postings  map[string]map[int]struct{}
+	ids       []int // 新しい順に並べた更新情報ID
+	state     model.IngestState
+}
+
+// document はインデックスに登録された更新情報
+type document struct {
+	id     int
+	item   *model.BacklogItem
+	fields []indexedField
+}
+
+// indexedField は検索対象のフィールドと、その正規化済みの文字列
+// 元の文字列と正規化済みの文字列はルーン単位で位置が対応する
+type indexedField struct {
+	name       string
+	original   []rune
+	normalized []rune
+}
+
+// NewActivityIndex はActivityIndexのインスタンスを生成
+// 1ユーザーあたりmaxDocumentsPerUser件を超えた場合は古い更新情報から削除する
+func NewActivityIndex(maxDocumentsPerUser int) *ActivityIndex {
+	return &ActivityIndex{
+		users:               make(map[string]*userIndex),
+		maxDocumentsPerUser: maxDocumentsPerUser,
+	}
+}
+
+// Add は更新情報をインデックスに追加し、取り込み状況を更新
+func (x *ActivityIndex) Add(userID string, items []*model.BacklogItem, state model.IngestState) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	ui, ok := x.users[userID]
+	if !ok {
+		ui = &userIndex{
+			documents: make(map[int]*document),
+			postings:  make(map[string]map[int]struct{}),
+		}
+		x.users[userID] = ui
+	}
+
+	for _, item := range items {
+		id, err := strconv.Atoi(item.ID)
+		if err != nil {
+			continue
+		}
+		if _, exists := ui.documents[id]; exists {
+			ui.remove(id)
+		}
+		ui.add(newDocument(id, item))
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(ui.ids)))
+	ui.state = state
+
+	// 上限を超えた分は古い更新情報から削除
+	for x.maxDocumentsPerUser > 0 && len(ui.ids) > x.maxDocumentsPerUser {
+		ui.remove(ui.ids[len(ui.ids)-1])
+		ui.state.Truncated = true
+	}
+
+	// 削除した更新情報より古い範囲は取り込まない
+	if ui.state.Truncated && len(ui.ids) > 0 {
+		oldestID := ui.ids[len(ui.ids)-1]
+		ui.state.ReachedOldest = false
+		gaps := make([]model.IngestGap, 0, len(ui.state.Gaps))
+		for _, gap := range ui.state.Gaps {
+			if gap.MaxID > oldestID {
+				gaps = append(gaps, gap)
+			}
+		}
+		ui.state.Gaps = gaps
+	}
+
+	return nil
+}
+
+// Search は検索条件に一致する更新情報を返す
+// キーワードを指定した場合はスコアの高い順、指定しない場合は新しい順に並べる
+// 一致する更新情報を返し終えたら、インデックスより古い更新情報をBacklog APIで検索するカーソルを返す
+func (x *ActivityIndex) Search(userID string, criteria model.SearchCriteria, page model.PageRequest) (*model.SearchHitPage, error) {
+	offset, err := model.DecodeOffsetCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := page.Size()
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	ui, ok := x.users[userID]
+	if !ok {
+		return &model.SearchHitPage{}, nil
+	}
+
+	terms := queryTerms(criteria.Keyword)
+
+	// キーワード以外の条件は検索条件の判定をそのまま使用する
+	filter := criteria
+	filter.Keyword = ""
+
+	var hits []*model.SearchHit
+	for _, id := range ui.candidates(terms) {
+		doc := ui.documents[id]
+		if !filter.Matches(doc.item) {
+			continue
+		}
+
+		score, ok := doc.score(terms)
+		if !ok {
+			continue
+		}
+		hits = append(hits, &model.SearchHit{Item: doc.item, Score: score})
+	}
+
+	if len(terms) > 0 {
+		// 同じスコアの場合は新しい順を維持する
+		sort.SliceStable(hits, func(i, j int) bool {
+			return hits[i].Score > hits[j].Score
+		})
+	}
+
+	if offset >= len(hits) {
+		return &model.SearchHitPage{NextCursor: ui.olderCursor(criteria)}, nil
+	}
+
+	end := offset + limit
+	result := &model.SearchHitPage{}
+	if end < len(hits) {
+		result.NextCursor = model.EncodeOffsetCursor(end)
+	} else {
+		end = len(hits)
+		result.NextCursor = ui.olderCursor(criteria)
+	}
+
+	result.Hits = hits[offset:end]
+	for _, hit := range result.Hits {
+		id, _ := strconv.Atoi(hit.Item.ID)
+		hit.Highlights = ui.documents[id].highlights(terms)
+	}
+
+	return result, nil
+}
+
+// Get はユーザーのインデックスからIDを指定して更新情報を取得
+func (x *ActivityIndex) Get(userID string, itemID string) (*model.BacklogItem, bool) {
+	id, err := strconv.Atoi(itemID)
+	if err != nil {
+		return nil, false
+	}
+
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	ui, ok := x.users[userID]
+	if !ok {
+		return nil, false
+	}
+
+	doc, ok := ui.documents[id]
+	if !ok {
+		return nil, false
+	}
+	return doc.item, true
+}
+
+// Ready はユーザーのインデックスが作成済みかを返す
+func (x *ActivityIndex) Ready(userID string) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	_, ok := x.users[userID]
+	return ok
+}
+
+// LatestID はユーザーのインデックスに含まれる最新の更新情報IDを返す
+func (x *ActivityIndex) LatestID(userID string) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	ui, ok := x.users[userID]
+	if !ok || len(ui.ids) == 0 {
+		return 0
+	}
+	return ui.ids[0]
+}
+
+// OldestID はユーザーのインデックスに含まれる最も古い更新情報IDを返す
+func (x *ActivityIndex) OldestID(userID string) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	ui, ok := x.users[userID]
+	if !ok || len(ui.ids) == 0 {
+		return 0
+	}
+	return ui.ids[len(ui.ids)-1]
+}
+
+// IngestState はユーザーの更新情報の取り込み状況を返す
+func (x *ActivityIndex) IngestState(userID string) model.IngestState {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	ui, ok := x.users[userID]
+	if !ok {
+		return model.IngestState{}
+	}
+	state := ui.state
+	state.Gaps = append([]model.IngestGap(nil), ui.state.Gaps...)
+	return state
+}
+
+// Users はインデックスが作成済みのユーザーIDを返す
+func (x *ActivityIndex) Users() []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	userIDs := make([]string, 0, len(x.users))
+	for userID := range x.users {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs
+}
+
+// Remove はユーザーのインデックスを削除
+func (x *ActivityIndex) Remove(userID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	delete(x.users, userID)
+}
+
+// add は文書を転置インデックスに登録（idsの並び替えは呼び出し元で行う）
+func (ui *userIndex) add(doc *document) {
+	ui.documents[doc.id] = doc
+	ui.ids = append(ui.ids, doc.id)
+
+	for gram := range doc.grams() {
+		posting, ok := ui.postings[gram]
+		if !ok {
+			posting = make(map[int]struct{})
+			ui.postings[gram] = posting
+		}
+		posting[doc.id] = struct{}{}
+	}
+}
+
+// remove は文書を転置インデックスから削除
+func (ui *userIndex) remove(id int) {
+	doc, ok := ui.documents[id]
+	if !ok {
+		return
+	}
+
+	for gram := range doc.grams() {
+		delete(ui.postings[gram], id)
+		if len(ui.postings[gram]) == 0 {
+			delete(ui.postings, gram)
+		}
+	}
+	delete(ui.documents, id)
+
+	for i, v := range ui.ids {
+		if v == id {
+			ui.ids = append(ui.ids[:i], ui.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+// olderCursor はインデックスより古い更新情報をBacklog APIで検索するカーソルを返す
+// 最も古い更新情報まで取り込み済みの場合や、検索期間の開始がインデックスの範囲内の場合は空を返す
+func (ui *userIndex) olderCursor(criteria model.SearchCriteria) string {
+	if ui.state.ReachedOldest || len(ui.ids) == 0 {
+		return ""
+	}
+
+	oldest := ui.documents[ui.ids[len(ui.ids)-1]]
+	if !criteria.Since.IsZero() && oldest.item.Created.Before(criteria.Since) {
+		return ""
+	}
+	return model.EncodeActivityCursor(oldest.id)
+}
+
+// candidates は全てのキーワードのN-gramを含む文書のIDを新しい順に返す
+// N-gramの一致は候補の絞り込みに使い、実際に部分文字列として含むかはスコア計算時に判定する
+func (ui *userIndex) candidates(terms [][]rune) []int {
+	if len(terms) == 0 {
+		return ui.ids
+	}
+
+	var matched map[int]struct{}
+	for _, term := range terms {
+		for _, gram := range termGrams(term) {
+			posting := ui.postings[gram]
+			if matched == nil {
+				matched = make(map[int]struct{}, len(posting))
+				for id := range posting {
+					matched[id] = struct{}{}
+				}
+				continue
+			}
+			for id := range matched {
+				if _, ok := posting[id]; !ok {
+					delete(matched, id)
+				}
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(matched))
+	for _, id := range ui.ids {
+		if _, ok := matched[id]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// newDocument は更新情報から検索対象のフィールドを正規化して文書を作成
+func newDocument(id int, item *model.BacklogItem) *document {
+	doc := &document{id: id, item: item}
+	for _, f := range []struct{ name, text string }{
+		{"id", item.ID},
+		{"projectName", item.ProjectName},
+		{"type", item.Type},
+		{"contentSummary", item.ContentSummary},
+		{"createdUser", item.CreatedUser.Name},
+	} {
+		original := []rune(f.text)
+		doc.fields = append(doc.fields, indexedField{
+			name:       f.name,
+			original:   original,
+			normalized: normalizeRunes(original),
+		})
+	}
+	return doc
+}
+
+// grams は文書の全フィールドのユニグラムとバイグラムを返す
+func (d *document) grams() map[string]struct{} {
+	grams := make(map[string]struct{})
+	for _, f := range d.fields {
+		for i := range f.normalized {
+			grams[string(f.normalized[i:i+1])] = struct{}{}
+			if i+1 < len(f.normalized) {
+				grams[string(f.normalized[i:i+2])] = struct{}{}
+			}
+		}
+	}
+	return grams
+}
+
+// score は全てのキーワードを含む場合に、一致したフィールドの重みと出現回数からスコアを計算
+func (d *document) score(terms [][]rune) (float64, bool) {
+	score := 0.0
+	for _, term := range terms {
+		termScore := 0.0
+		for _, f := range d.fields {
+			termScore += fieldWeights[f.name] * float64(len(matchPositions(f.normalized, term)))
+		}
+		if termScore == 0 {
+			return 0, false
+		}
+		score += termScore
+	}
+	return score, true
+}
+
+// highlights はキーワードに一致したフィールドごとのハイライト断片を返す
+func (d *document) highlights(terms [][]rune) []model.Highlight {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var highlights []model.Highlight
+	for _, f := range d.fields {
+		matched := make([]bool, len(f.normalized))
+		found := false
+		for _, term := range terms {
+			for _, pos := range matchPositions(f.normalized, term) {
+				for i := pos; i < pos+len(term); i++ {
+					matched[i] = true
+				}
+				found = true
+			}
+		}
+		if found {
+			highlights = append(highlights, model.Highlight{
+				Field:    f.name,
+				Segments: segments(f.original, matched),
+			})
+		}
+	}
+	return highlights
+}
+
+// snippetRadius は長いフィールドのハイライト断片に含める一致箇所前後の文字数
+const snippetRadius = 30
+
+// segments は一致箇所の印をもとに文字列を一致箇所とそれ以外の断片に分割
+// 長い文字列は最初の一致箇所の前後だけを切り出す
+func segments(text []rune, matched []bool) []model.HighlightSegment {
+	start, end := 0, len(text)
+	if len(text) > snippetRadius*3 {
+		first := 0
+		for first < len(matched) && !matched[first] {
+			first++
+		}
+		start = max(0, first-snippetRadius)
+		end = min(len(text), first+snippetRadius*2)
+	}
+
+	var result []model.HighlightSegment
+	if start > 0 {
+		result = append(result, model.HighlightSegment{Text: "…"})
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && matched[j] == matched[i] {
+			j++
+		}
+		result = append(result, model.HighlightSegment{Text: string(text[i:j]), Match: matched[i]})
+		i = j
+	}
+	if end < len(text) {
+		result = append(result, model.HighlightSegment{Text: "…"})
+	}
+	return result
+}
+
+// queryTerms は空白で区切られたキーワードを正規化して返す。全てのキーワードを含む更新情報を検索対象とする
+func queryTerms(keyword string) [][]rune {
+	var terms [][]rune
+	for _, word := range strings.Fields(string(normalizeRunes([]rune(keyword)))) {
+		terms = append(terms, []rune(word))
+	}
+	return terms
+}
+
+// termGrams はキーワードの候補絞り込みに使うN-gramを返す。1文字のキーワードはユニグラムを使用する
+func termGrams(term []rune) []string {
+	if len(term) == 1 {
+		return []string{string(term)}
+	}
+
+	grams := make([]string, 0, len(term)-1)
+	for i := 0; i+1 < len(term); i++ {
+		grams = append(grams, string(term[i:i+2]))
+	}
+	return grams
+}
+
+// matchPositions は正規化済みの文字列中でキーワードが出現するルーン位置を返す
+func matchPositions(text, term []rune) []int {
+	var positions []int
+	for i := 0; i+len(term) <= len(text); i++ {
+		if string(text[i:i+len(term)]) == string(term) {
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// normalizeRunes は表記ゆれを吸収するために文字を1文字ずつ正規化
+// 全角英数記号を半角に、カタカナをひらがなに、英字を小文字に揃える。変換前後でルーン数は変わらない
+func normalizeRunes(runes []rune) []rune {
+	normalized := make([]rune, len(runes))
+	for i, r := range runes {
+		switch {
+		case r == '　':
+			r = ' '
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		case r >= 'ァ' && r <= 'ヶ':
+			r -= 0x60
+		}
+		normalized[i] = unicode.ToLower(r)
+	}
+	return normalized
+}
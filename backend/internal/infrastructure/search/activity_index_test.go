@@ -0,0 +1,179 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+func newItem(id int, projectName, summary, userName string) *model.BacklogItem {
+	return &model.BacklogItem{
+		ID:             fmt.Sprintf("%d", id),
+		ProjectID:      "1",
+		ProjectName:    projectName,
+		TypeID:         1,
+		Type:           "課題の追加",
+		ContentSummary: summary,
+		CreatedUser:    model.User{ID: "1", Name: userName},
+		Created:        time.Date(2024, 1, 1, 0, 0, id, 0, time.UTC),
+	}
+}
+
+func TestActivityIndex_SearchJapanese(t *testing.T) {
+	index := NewActivityIndex(0)
+	index.Add("user1", []*model.BacklogItem{
+		newItem(1, "プロジェクトA", "ログイン機能の実装", "山田太郎"),
+		newItem(2, "プロジェクトA", "ログイン画面のデザイン修正とログイン処理のテスト", "佐藤花子"),
+		newItem(3, "プロジェクトB", "検索機能の追加", "鈴木一郎"),
+		newItem(4, "プロジェクトB", "ＡＰＩのエラー処理", "田中次郎"),
+	}, model.IngestState{ReachedOldest: true})
+
+	testCases := []struct {
+		name     string
+		keyword  string
+		expected []string
+	}{
+		{"分かち書きなしの部分一致", "ログイン", []string{"2", "1"}},
+		{"1文字のキーワード", "索", []string{"3"}},
+		{"複数キーワードのAND検索", "ログイン 実装", []string{"1"}},
+		{"全角英字と大文字小文字の正規化", "api", []string{"4"}},
+		{"ひらがなとカタカナの正規化", "ろぐいん", []string{"2", "1"}},
+		{"一致しないキーワード", "デプロイ", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := index.Search("user1", model.SearchCriteria{Keyword: tc.keyword}, model.PageRequest{})
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+
+			if len(result.Hits) != len(tc.expected) {
+				t.Fatalf("Expected %d hits, got %d", len(tc.expected), len(result.Hits))
+			}
+			for i, id := range tc.expected {
+				if result.Hits[i].Item.ID != id {
+					t.Errorf("Expected hit %d to be %s, got %s", i, id, result.Hits[i].Item.ID)
+				}
+			}
+		})
+	}
+
+	// 他のユーザーのインデックスは検索しない
+	result, _ := index.Search("user2", model.SearchCriteria{Keyword: "ログイン"}, model.PageRequest{})
+	if len(result.Hits) != 0 {
+		t.Errorf("Expected no hits for another user, got %d", len(result.Hits))
+	}
+}
+
+func TestActivityIndex_Highlights(t *testing.T) {
+	index := NewActivityIndex(0)
+	index.Add("user1", []*model.BacklogItem{newItem(1, "プロジェクトA", "ログイン機能のログイン処理", "山田太郎")}, model.IngestState{ReachedOldest: true})
+
+	result, err := index.Search("user1", model.SearchCriteria{Keyword: "ろぐいん"}, model.PageRequest{})
+	if err != nil || len(result.Hits) != 1 {
+		t.Fatalf("Expected 1 hit, got %v %v", result, err)
+	}
+
+	highlights := result.Hits[0].Highlights
+	if len(highlights) != 1 || highlights[0].Field != "contentSummary" {
+		t.Fatalf("Unexpected highlights: %+v", highlights)
+	}
+
+	expected := []model.HighlightSegment{
+		{Text: "ログイン", Match: true},
+		{Text: "機能の"},
+		{Text: "ログイン", Match: true},
+		{Text: "処理"},
+	}
+	if fmt.Sprint(highlights[0].Segments) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, got %v", expected, highlights[0].Segments)
+	}
+}
+
+func TestActivityIndex_PagingAndLimit(t *testing.T) {
+	index := NewActivityIndex(50)
+	for id := 1; id <= 80; id++ {
+		index.Add("user1", []*model.BacklogItem{newItem(id, "プロジェクトA", "定期更新", "山田太郎")}, index.IngestState("user1"))
+	}
+
+	if latest := index.LatestID("user1"); latest != 80 {
+		t.Errorf("Expected latest ID 80, got %d", latest)
+	}
+
+	var ids []string
+	page := model.PageRequest{Limit: 20}
+	for {
+		result, err := index.Search("user1", model.SearchCriteria{}, page)
+		if err != nil {
+			t.Fatalf("Failed to search: %v", err)
+		}
+		for _, hit := range result.Hits {
+			ids = append(ids, hit.Item.ID)
+		}
+		if !model.IsOffsetCursor(result.NextCursor) {
+			page.Cursor = result.NextCursor
+			break
+		}
+		page.Cursor = result.NextCursor
+	}
+
+	// 上限を超えた古い更新情報は削除され、新しい順に返される
+	if len(ids) != 50 || ids[0] != "80" || ids[49] != "31" {
+		t.Errorf("Expected IDs 80..31, got %d items from %s to %s", len(ids), ids[0], ids[len(ids)-1])
+	}
+
+	// 削除した更新情報はBacklog APIで続きを検索する
+	if page.Cursor != model.EncodeActivityCursor(31) {
+		t.Errorf("Expected Backlog API cursor for ID 31, got %q", page.Cursor)
+	}
+	if state := index.IngestState("user1"); !state.Truncated || state.ReachedOldest {
+		t.Errorf("Expected truncated state, got %+v", state)
+	}
+
+	if _, err := index.Search("user1", model.SearchCriteria{}, model.PageRequest{Cursor: "invalid"}); err != model.ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestActivityIndex_OlderCursor(t *testing.T) {
+	index := NewActivityIndex(0)
+	index.Add("user1", []*model.BacklogItem{
+		newItem(10, "プロジェクトA", "ログイン機能の実装", "山田太郎"),
+		newItem(11, "プロジェクトA", "検索機能の追加", "山田太郎"),
+	}, model.IngestState{})
+
+	testCases := []struct {
+		name     string
+		criteria model.SearchCriteria
+		page     model.PageRequest
+		hits     int
+		cursor   string
+	}{
+		{"最後のページでBacklog APIのカーソルを返す", model.SearchCriteria{Keyword: "ログイン"}, model.PageRequest{}, 1, model.EncodeActivityCursor(10)},
+		{"一致しない場合もBacklog APIのカーソルを返す", model.SearchCriteria{Keyword: "デプロイ"}, model.PageRequest{}, 0, model.EncodeActivityCursor(10)},
+		{"途中のページはインデックスのカーソルを返す", model.SearchCriteria{}, model.PageRequest{Limit: 1}, 1, model.EncodeOffsetCursor(1)},
+		{"検索期間の開始がインデックスの範囲内の場合は続きを検索しない", model.SearchCriteria{Since: time.Date(2024, 1, 1, 0, 0, 11, 0, time.UTC)}, model.PageRequest{}, 1, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := index.Search("user1", tc.criteria, tc.page)
+			if err != nil {
+				t.Fatalf("Failed to search: %v", err)
+			}
+			if len(result.Hits) != tc.hits || result.NextCursor != tc.cursor {
+				t.Errorf("Expected %d hits and cursor %q, got %d hits and cursor %q", tc.hits, tc.cursor, len(result.Hits), result.NextCursor)
+			}
+		})
+	}
+
+	// 最も古い更新情報まで取り込み済みの場合は続きを検索しない
+	index.Add("user1", nil, model.IngestState{ReachedOldest: true})
+	result, _ := index.Search("user1", model.SearchCriteria{}, model.PageRequest{})
+	if result.NextCursor != "" {
+		t.Errorf("Expected no cursor after reaching the oldest activity, got %q", result.NextCursor)
+	}
+}
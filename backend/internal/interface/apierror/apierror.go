@@ -0,0 +1,65 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+// 機械可読なエラーコード
+const (
+	CodeUnauthorized        = "UNAUTHORIZED"
+	CodeInvalidState        = "INVALID_STATE"
+	CodeInvalidCursor       = "INVALID_CURSOR"
+	CodeInvalidArgument     = "INVALID_ARGUMENT"
+	CodeForbidden           = "FORBIDDEN"
+	CodeNotFound            = "NOT_FOUND"
+	CodeAlreadyExists       = "ALREADY_EXISTS"
+	CodeRateLimited         = "RATE_LIMITED"
+	CodeUpstreamUnavailable = "UPSTREAM_UNAVAILABLE"
+	CodeInternal            = "INTERNAL_ERROR"
+)
+
+// Classify はユースケースから返されたエラーをHTTPステータスコードとエラーコードに分類
+func Classify(err error) (int, string) {
+	switch {
+	case errors.Is(err, usecase.ErrUnauthorized),
+		errors.Is(err, model.ErrBacklogUnauthorized):
+		return http.StatusUnauthorized, CodeUnauthorized
+	case errors.Is(err, usecase.ErrInvalidState):
+		return http.StatusBadRequest, CodeInvalidState
+	case errors.Is(err, model.ErrInvalidCursor):
+		return http.StatusBadRequest, CodeInvalidCursor
+	case errors.Is(err, usecase.ErrInvalidSearchCriteria),
+		errors.Is(err, usecase.ErrInvalidFavoriteInput):
+		return http.StatusBadRequest, CodeInvalidArgument
+	case errors.Is(err, model.ErrCollectionForbidden),
+		errors.Is(err, model.ErrBacklogForbidden):
+		return http.StatusForbidden, CodeForbidden
+	case errors.Is(err, model.ErrItemNotFound),
+		errors.Is(err, model.ErrProjectNotFound),
+		errors.Is(err, usecase.ErrFavoriteNotFound),
+		errors.Is(err, model.ErrCollectionNotFound):
+		return http.StatusNotFound, CodeNotFound
+	case errors.Is(err, usecase.ErrFavoriteAlreadyExists):
+		return http.StatusConflict, CodeAlreadyExists
+	case errors.Is(err, model.ErrBacklogRateLimited):
+		return http.StatusTooManyRequests, CodeRateLimited
+	case errors.Is(err, model.ErrBacklogUnavailable):
+		return http.StatusServiceUnavailable, CodeUpstreamUnavailable
+	default:
+		return http.StatusInternalServerError, CodeInternal
+	}
+}
+
+// ResetAt はレート制限のエラーから制限が解除される日時を取り出す（レート制限以外や解除日時が不明な場合はfalse）
+func ResetAt(err error) (time.Time, bool) {
+	var rateLimitErr *model.RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.Reset.IsZero() {
+		return time.Time{}, false
+	}
+	return rateLimitErr.Reset, true
+}
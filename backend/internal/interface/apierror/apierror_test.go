@@ -0,0 +1,61 @@
+package apierror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"無効なトークン", usecase.ErrInvalidToken, http.StatusUnauthorized, CodeUnauthorized},
+		{"無効なセッション", usecase.ErrInvalidSession, http.StatusUnauthorized, CodeUnauthorized},
+		{"Backlogの認証エラー", fmt.Errorf("%w: status 401", model.ErrBacklogUnauthorized), http.StatusUnauthorized, CodeUnauthorized},
+		{"Backlogの権限不足", fmt.Errorf("%w: status 403", model.ErrBacklogForbidden), http.StatusForbidden, CodeForbidden},
+		{"不正なカーソル", model.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor},
+		{"不正な検索条件", fmt.Errorf("%w: unknown activity type 27", usecase.ErrInvalidSearchCriteria), http.StatusBadRequest, CodeInvalidArgument},
+		{"不正なタグ", fmt.Errorf("%w: at most 20 tags are allowed", usecase.ErrInvalidFavoriteInput), http.StatusBadRequest, CodeInvalidArgument},
+		{"存在しない更新情報", model.ErrItemNotFound, http.StatusNotFound, CodeNotFound},
+		{"参加していないプロジェクト", model.ErrProjectNotFound, http.StatusNotFound, CodeNotFound},
+		{"登録されていないお気に入り", usecase.ErrFavoriteNotFound, http.StatusNotFound, CodeNotFound},
+		{"登録済みのお気に入り", fmt.Errorf("add favorite: %w", usecase.ErrFavoriteAlreadyExists), http.StatusConflict, CodeAlreadyExists},
+		{"存在しないコレクション", model.ErrCollectionNotFound, http.StatusNotFound, CodeNotFound},
+		{"コレクションの権限不足", model.ErrCollectionForbidden, http.StatusForbidden, CodeForbidden},
+		{"レート制限", fmt.Errorf("%w: status 429", model.ErrBacklogRateLimited), http.StatusTooManyRequests, CodeRateLimited},
+		{"解除日時付きのレート制限", fmt.Errorf("failed to get activities: %w", &model.RateLimitError{Reset: time.Unix(1700000000, 0)}), http.StatusTooManyRequests, CodeRateLimited},
+		{"Backlog障害", fmt.Errorf("%w: status 503", model.ErrBacklogUnavailable), http.StatusServiceUnavailable, CodeUpstreamUnavailable},
+		{"不明なエラー", errors.New("boom"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, code := Classify(tc.err)
+			if status != tc.expectedStatus || code != tc.expectedCode {
+				t.Errorf("Expected %d %s, got %d %s", tc.expectedStatus, tc.expectedCode, status, code)
+			}
+		})
+	}
+}
+
+func TestResetAt(t *testing.T) {
+	reset := time.Unix(1700000000, 0)
+	if got, ok := ResetAt(fmt.Errorf("failed to get activities: %w", &model.RateLimitError{Reset: reset})); !ok || !got.Equal(reset) {
+		t.Errorf("Expected %v, got %v %v", reset, got, ok)
+	}
+	if _, ok := ResetAt(&model.RateLimitError{}); ok {
+		t.Error("Expected no reset time for unknown reset")
+	}
+	if _, ok := ResetAt(model.ErrBacklogUnavailable); ok {
+		t.Error("Expected no reset time for other errors")
+	}
+}
@@ -0,0 +1,335 @@
+package graphql
+
+import (
+	gql "github.com/graph-gophers/graphql-go"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// activityContentResolver はActivityContent共用体のリゾルバー
+type activityContentResolver struct {
+	content *model.ActivityContent
+}
+
+func (r *backlogItemResolver) Content() *activityContentResolver {
+	if r.item.Content == nil {
+		return nil
+	}
+	return &activityContentResolver{content: r.item.Content}
+}
+
+func (r *activityContentResolver) ToIssueContent() (*issueContentResolver, bool) {
+	if r.content.Issue == nil {
+		return nil, false
+	}
+	return &issueContentResolver{content: r.content.Issue}, true
+}
+
+func (r *activityContentResolver) ToCommentContent() (*commentContentResolver, bool) {
+	if r.content.Comment == nil {
+		return nil, false
+	}
+	return &commentContentResolver{content: r.content.Comment}, true
+}
+
+func (r *activityContentResolver) ToWikiContent() (*wikiContentResolver, bool) {
+	if r.content.Wiki == nil {
+		return nil, false
+	}
+	return &wikiContentResolver{content: r.content.Wiki}, true
+}
+
+func (r *activityContentResolver) ToFileContent() (*fileContentResolver, bool) {
+	if r.content.File == nil {
+		return nil, false
+	}
+	return &fileContentResolver{content: r.content.File}, true
+}
+
+func (r *activityContentResolver) ToGitPushContent() (*gitPushContentResolver, bool) {
+	if r.content.GitPush == nil {
+		return nil, false
+	}
+	return &gitPushContentResolver{content: r.content.GitPush}, true
+}
+
+func (r *activityContentResolver) ToPullRequestContent() (*pullRequestContentResolver, bool) {
+	if r.content.PullRequest == nil {
+		return nil, false
+	}
+	return &pullRequestContentResolver{content: r.content.PullRequest}, true
+}
+
+func (r *activityContentResolver) ToMilestoneContent() (*milestoneContentResolver, bool) {
+	if r.content.Milestone == nil {
+		return nil, false
+	}
+	return &milestoneContentResolver{content: r.content.Milestone}, true
+}
+
+func (r *activityContentResolver) ToMembershipContent() (*membershipContentResolver, bool) {
+	if r.content.Membership == nil {
+		return nil, false
+	}
+	return &membershipContentResolver{content: r.content.Membership}, true
+}
+
+// issueContentResolver はIssueContent型のリゾルバー
+type issueContentResolver struct {
+	content *model.IssueContent
+}
+
+func (r *issueContentResolver) IssueKey() *string {
+	return optionalString(r.content.IssueKey)
+}
+
+func (r *issueContentResolver) Summary() string {
+	return r.content.Summary
+}
+
+func (r *issueContentResolver) Description() *string {
+	return optionalString(r.content.Description)
+}
+
+func (r *issueContentResolver) Comment() *string {
+	return optionalString(r.content.Comment)
+}
+
+func (r *issueContentResolver) Changes() []*activityChangeResolver {
+	return newActivityChangeResolvers(r.content.Changes)
+}
+
+func (r *issueContentResolver) LinkedIssueKeys() []string {
+	if r.content.LinkedIssueKeys == nil {
+		return []string{}
+	}
+	return r.content.LinkedIssueKeys
+}
+
+// commentContentResolver はCommentContent型のリゾルバー
+type commentContentResolver struct {
+	content *model.CommentContent
+}
+
+func (r *commentContentResolver) IssueKey() *string {
+	return optionalString(r.content.IssueKey)
+}
+
+func (r *commentContentResolver) Summary() string {
+	return r.content.Summary
+}
+
+func (r *commentContentResolver) CommentID() *gql.ID {
+	if r.content.CommentID == "" {
+		return nil
+	}
+	id := gql.ID(r.content.CommentID)
+	return &id
+}
+
+func (r *commentContentResolver) Body() string {
+	return r.content.Body
+}
+
+func (r *commentContentResolver) Changes() []*activityChangeResolver {
+	return newActivityChangeResolvers(r.content.Changes)
+}
+
+// wikiContentResolver はWikiContent型のリゾルバー
+type wikiContentResolver struct {
+	content *model.WikiContent
+}
+
+func (r *wikiContentResolver) WikiID() gql.ID {
+	return gql.ID(r.content.WikiID)
+}
+
+func (r *wikiContentResolver) Name() string {
+	return r.content.Name
+}
+
+func (r *wikiContentResolver) Diff() *string {
+	return optionalString(r.content.Diff)
+}
+
+func (r *wikiContentResolver) Version() *int32 {
+	if r.content.Version == 0 {
+		return nil
+	}
+	version := int32(r.content.Version)
+	return &version
+}
+
+// fileContentResolver はFileContent型のリゾルバー
+type fileContentResolver struct {
+	content *model.FileContent
+}
+
+func (r *fileContentResolver) FileID() gql.ID {
+	return gql.ID(r.content.FileID)
+}
+
+func (r *fileContentResolver) Dir() string {
+	return r.content.Dir
+}
+
+func (r *fileContentResolver) Name() string {
+	return r.content.Name
+}
+
+func (r *fileContentResolver) Size() float64 {
+	return float64(r.content.Size)
+}
+
+// gitPushContentResolver はGitPushContent型のリゾルバー
+type gitPushContentResolver struct {
+	content *model.GitPushContent
+}
+
+func (r *gitPushContentResolver) Repository() string {
+	return r.content.Repository
+}
+
+func (r *gitPushContentResolver) Ref() *string {
+	return optionalString(r.content.Ref)
+}
+
+func (r *gitPushContentResolver) ChangeType() *string {
+	return optionalString(r.content.ChangeType)
+}
+
+func (r *gitPushContentResolver) RevisionCount() int32 {
+	return int32(r.content.RevisionCount)
+}
+
+func (r *gitPushContentResolver) Revisions() []*gitRevisionResolver {
+	resolvers := make([]*gitRevisionResolver, len(r.content.Revisions))
+	for i := range r.content.Revisions {
+		resolvers[i] = &gitRevisionResolver{revision: &r.content.Revisions[i]}
+	}
+	return resolvers
+}
+
+// gitRevisionResolver はGitRevision型のリゾルバー
+type gitRevisionResolver struct {
+	revision *model.GitRevision
+}
+
+func (r *gitRevisionResolver) Rev() string {
+	return r.revision.Rev
+}
+
+func (r *gitRevisionResolver) Comment() string {
+	return r.revision.Comment
+}
+
+// pullRequestContentResolver はPullRequestContent型のリゾルバー
+type pullRequestContentResolver struct {
+	content *model.PullRequestContent
+}
+
+func (r *pullRequestContentResolver) Repository() string {
+	return r.content.Repository
+}
+
+func (r *pullRequestContentResolver) Number() int32 {
+	return int32(r.content.Number)
+}
+
+func (r *pullRequestContentResolver) Summary() string {
+	return r.content.Summary
+}
+
+func (r *pullRequestContentResolver) Description() *string {
+	return optionalString(r.content.Description)
+}
+
+func (r *pullRequestContentResolver) Comment() *string {
+	return optionalString(r.content.Comment)
+}
+
+func (r *pullRequestContentResolver) IssueKey() *string {
+	return optionalString(r.content.IssueKey)
+}
+
+func (r *pullRequestContentResolver) Changes() []*activityChangeResolver {
+	return newActivityChangeResolvers(r.content.Changes)
+}
+
+// milestoneContentResolver はMilestoneContent型のリゾルバー
+type milestoneContentResolver struct {
+	content *model.MilestoneContent
+}
+
+func (r *milestoneContentResolver) MilestoneID() gql.ID {
+	return gql.ID(r.content.MilestoneID)
+}
+
+func (r *milestoneContentResolver) Name() string {
+	return r.content.Name
+}
+
+func (r *milestoneContentResolver) Description() *string {
+	return optionalString(r.content.Description)
+}
+
+func (r *milestoneContentResolver) StartDate() *string {
+	return optionalString(r.content.StartDate)
+}
+
+func (r *milestoneContentResolver) ReferenceDate() *string {
+	return optionalString(r.content.ReferenceDate)
+}
+
+func (r *milestoneContentResolver) Changes() []*activityChangeResolver {
+	return newActivityChangeResolvers(r.content.Changes)
+}
+
+// membershipContentResolver はMembershipContent型のリゾルバー
+type membershipContentResolver struct {
+	content *model.MembershipContent
+}
+
+func (r *membershipContentResolver) Users() []*userResolver {
+	resolvers := make([]*userResolver, len(r.content.Users))
+	for i := range r.content.Users {
+		resolvers[i] = &userResolver{user: &r.content.Users[i]}
+	}
+	return resolvers
+}
+
+func (r *membershipContentResolver) Groups() []string {
+	if r.content.Groups == nil {
+		return []string{}
+	}
+	return r.content.Groups
+}
+
+func (r *membershipContentResolver) Comment() *string {
+	return optionalString(r.content.Comment)
+}
+
+// activityChangeResolver はActivityChange型のリゾルバー
+type activityChangeResolver struct {
+	change *model.ActivityChange
+}
+
+// newActivityChangeResolvers は変更された項目のリストからリゾルバーのリストを生成
+func newActivityChangeResolvers(changes []model.ActivityChange) []*activityChangeResolver {
+	resolvers := make([]*activityChangeResolver, len(changes))
+	for i := range changes {
+		resolvers[i] = &activityChangeResolver{change: &changes[i]}
+	}
+	return resolvers
+}
+
+func (r *activityChangeResolver) Field() string {
+	return r.change.Field
+}
+
+func (r *activityChangeResolver) OldValue() string {
+	return r.change.OldValue
+}
+
+func (r *activityChangeResolver) NewValue() string {
+	return r.change.NewValue
+}
@@ -0,0 +1,55 @@
+package graphql
+
+import (
+	"errors"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/interface/apierror"
+)
+
+// errUnauthenticated は未認証のリクエストに返すエラー
+var errUnauthenticated = &resolverError{
+	err:  errors.New("authentication required"),
+	code: apierror.CodeUnauthorized,
+}
+
+// errSessionUnavailable はセッショントークンをブラウザのCookieに保存できないリクエストに返すエラー
+var errSessionUnavailable = &resolverError{
+	err:  errors.New("session cookie cannot be set for this request"),
+	code: apierror.CodeInternal,
+}
+
+// resolverError は機械可読なエラーコードをextensionsに含めるGraphQLエラー
+type resolverError struct {
+	err     error
+	code    string
+	resetAt time.Time // レート制限が解除される日時（レート制限以外はゼロ値）
+}
+
+func (e *resolverError) Error() string {
+	return e.err.Error()
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.err
+}
+
+// Extensions はGraphQLレスポンスのerrors[].extensionsに含める値を返す
+func (e *resolverError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.code}
+	if !e.resetAt.IsZero() {
+		extensions["resetAt"] = e.resetAt.Format(time.RFC3339)
+	}
+	return extensions
+}
+
+// wrapError はユースケースのエラーをエラーコード付きのGraphQLエラーに変換
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	_, code := apierror.Classify(err)
+	resetAt, _ := apierror.ResetAt(err)
+	return &resolverError{err: err, code: code, resetAt: resetAt}
+}
@@ -0,0 +1,77 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+type contextKey struct{ name string }
+
+var (
+	// userIDKey はリクエストコンテキストに認証済みユーザーIDを格納するキー
+	userIDKey = contextKey{"userID"}
+	// stateBinderKey はリクエストコンテキストにOAuth stateをブラウザに保存する関数を格納するキー
+	stateBinderKey = contextKey{"stateBinder"}
+	// sessionBinderKey はリクエストコンテキストにセッショントークンをブラウザに保存する関数を格納するキー
+	sessionBinderKey = contextKey{"sessionBinder"}
+)
+
+// NewHandler はschema.graphqlを解決するHTTPハンドラーを生成
+func NewHandler(
+	authUseCase *usecase.AuthUseCase,
+	sessionUseCase *usecase.SessionUseCase,
+	backlogItemUseCase *usecase.BacklogItemUseCase,
+	collectionUseCase *usecase.CollectionUseCase,
+) http.Handler {
+	resolver := &Resolver{
+		authUseCase:        authUseCase,
+		sessionUseCase:     sessionUseCase,
+		backlogItemUseCase: backlogItemUseCase,
+		collectionUseCase:  collectionUseCase,
+	}
+
+	schema := gql.MustParseSchema(schemaString, resolver)
+	return &relay.Handler{Schema: schema}
+}
+
+// WithUserID は認証済みユーザーIDをコンテキストに設定
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// userIDFromContext はコンテキストから認証済みユーザーIDを取得
+func userIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	return userID, ok && userID != ""
+}
+
+// WithStateBinder は発行したOAuth stateをリクエスト元のブラウザに保存する関数をコンテキストに設定
+func WithStateBinder(ctx context.Context, bind func(state string)) context.Context {
+	return context.WithValue(ctx, stateBinderKey, bind)
+}
+
+// stateBinderFromContext はコンテキストからOAuth stateをブラウザに保存する関数を取得
+func stateBinderFromContext(ctx context.Context) (func(state string), bool) {
+	bind, ok := ctx.Value(stateBinderKey).(func(state string))
+	return bind, ok
+}
+
+// WithSessionBinder は発行したセッショントークンをリクエスト元のブラウザにHttpOnly Cookieとして保存する関数をコンテキストに設定
+// セッショントークンはJavaScriptから読み取れないよう、レスポンスには含めない。空のセッショントークンはCookieの削除を表す
+func WithSessionBinder(ctx context.Context, bind func(sessionToken string)) context.Context {
+	return context.WithValue(ctx, sessionBinderKey, bind)
+}
+
+// sessionBinderFromContext はコンテキストからセッショントークンをブラウザに保存する関数を取得
+func sessionBinderFromContext(ctx context.Context) (func(sessionToken string), bool) {
+	bind, ok := ctx.Value(sessionBinderKey).(func(sessionToken string))
+	return bind, ok
+}
@@ -0,0 +1,453 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+// stubAuthService はAuthServiceのテスト用実装
+type stubAuthService struct{}
+
+func (s *stubAuthService) GetAuthorizationURL(state string, codeVerifier string) string {
+	return "https://example.backlog.jp/OAuth2AccessRequest.action?state=" + state
+}
+
+func (s *stubAuthService) ExchangeCodeForToken(code string, codeVerifier string) (*model.AuthToken, error) {
+	return &model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (s *stubAuthService) RefreshToken(refreshToken string) (*model.AuthToken, error) {
+	return &model.AuthToken{AccessToken: "refreshed", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (s *stubAuthService) GetBacklogUser(accessToken string) (*model.User, error) {
+	return &model.User{ID: "user1", Name: "Test User", RoleType: 1}, nil
+}
+
+// stubBacklogItemService はBacklogItemServiceのテスト用実装
+type stubBacklogItemService struct {
+	lastCriteria model.SearchCriteria
+	lastFeed     model.PersonalFeed
+}
+
+func (s *stubBacklogItemService) SearchItems(accessToken string, criteria model.SearchCriteria, page model.PageRequest) (*model.BacklogItemPage, error) {
+	s.lastCriteria = criteria
+	return &model.BacklogItemPage{
+		Items: []*model.BacklogItem{
+			{ID: "1", ProjectID: "10", ProjectName: "プロジェクトA", Type: "課題の追加", ContentSummary: "ログイン機能の実装", CreatedUser: model.User{ID: "2", Name: "佐藤花子"},
+				URL: "https://example.backlog.jp/view/PROJ-1",
+				Content: &model.ActivityContent{Issue: &model.IssueContent{
+					IssueKey: "PROJ-1",
+					Summary:  "ログイン機能の実装",
+					Changes:  []model.ActivityChange{{Field: "status", OldValue: "1", NewValue: "2"}},
+				}}},
+		},
+		NextCursor: "next",
+	}, nil
+}
+
+func (s *stubBacklogItemService) GetItem(accessToken string, itemID string) (*model.BacklogItem, error) {
+	if itemID != "1" {
+		return nil, model.ErrItemNotFound
+	}
+	return &model.BacklogItem{ID: "1", ProjectID: "10", ProjectName: "プロジェクトA", Type: "課題の追加", ContentSummary: "ログイン機能の実装", CreatedUser: model.User{ID: "2", Name: "佐藤花子"}}, nil
+}
+
+func (s *stubBacklogItemService) GetIssue(accessToken string, issueKey string) (*model.Issue, error) {
+	return nil, model.ErrItemNotFound
+}
+
+func (s *stubBacklogItemService) GetUserItems(accessToken string, userID string, page model.PageRequest) (*model.BacklogItemPage, error) {
+	return &model.BacklogItemPage{}, nil
+}
+
+func (s *stubBacklogItemService) SearchPersonalItems(accessToken string, feed model.PersonalFeed, page model.PageRequest) (*model.BacklogItemPage, error) {
+	s.lastFeed = feed
+	return &model.BacklogItemPage{}, nil
+}
+
+func (s *stubBacklogItemService) GetAssignedIssues(accessToken string, userID string) ([]*model.Issue, error) {
+	return []*model.Issue{{IssueKey: "PROJ-1"}, {IssueKey: "PROJ-2"}}, nil
+}
+
+func (s *stubBacklogItemService) GetWatchedIssues(accessToken string, userID string) ([]*model.Issue, error) {
+	return []*model.Issue{{IssueKey: "PROJ-2"}, {IssueKey: "PROJ-3"}}, nil
+}
+
+func (s *stubBacklogItemService) GetRateLimit(accessToken string) *model.RateLimit {
+	return nil
+}
+
+func (s *stubBacklogItemService) GetProjects(accessToken string) ([]*model.Project, error) {
+	return []*model.Project{{ID: "10", ProjectKey: "PROJ", Name: "プロジェクトA"}}, nil
+}
+
+func (s *stubBacklogItemService) GetProject(accessToken string, projectIDOrKey string) (*model.Project, error) {
+	if projectIDOrKey != "10" && projectIDOrKey != "PROJ" {
+		return nil, model.ErrProjectNotFound
+	}
+	return &model.Project{ID: "10", ProjectKey: "PROJ", Name: "プロジェクトA"}, nil
+}
+
+// execute はGraphQLクエリをハンドラーに送信してレスポンスを返す
+func execute(t *testing.T, handler http.Handler, userID, query string) map[string]interface{} {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	if userID != "" {
+		req = req.WithContext(WithUserID(req.Context(), userID))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp
+}
+
+func TestHandler_SearchItemsWithFavorite(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	resp := execute(t, handler, "user1", `mutation { addFavorite(itemId: "1") }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+
+	// 同じ更新情報を再度追加すると重複エラーになる
+	resp = execute(t, handler, "user1", `mutation { addFavorite(itemId: "1") }`)
+	errs, _ := resp["errors"].([]interface{})
+	if len(errs) == 0 {
+		t.Fatal("Expected already exists error, but got nil")
+	}
+	extensions, _ := errs[0].(map[string]interface{})["extensions"].(map[string]interface{})
+	if extensions["code"] != "ALREADY_EXISTS" {
+		t.Errorf("Expected ALREADY_EXISTS error code, got %v", extensions["code"])
+	}
+
+	resp = execute(t, handler, "user1", `{ searchItems(limit: 1) { items { id isFavorite createdUser { name } } nextCursor } }`)
+	page := resp["data"].(map[string]interface{})["searchItems"].(map[string]interface{})
+	if page["nextCursor"] != "next" {
+		t.Errorf("Expected next cursor, got %v", page["nextCursor"])
+	}
+	items := page["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+	if !items[0].(map[string]interface{})["isFavorite"].(bool) {
+		t.Error("Expected item to be favorite")
+	}
+}
+
+// 認可URLのstateをリクエスト元のブラウザに保存することを確認する
+func TestHandler_AuthorizationURLBindsState(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	var boundState string
+	body, _ := json.Marshal(map[string]string{"query": `{ authorizationURL }`})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req = req.WithContext(WithStateBinder(req.Context(), func(state string) { boundState = state }))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var resp struct {
+		Data struct {
+			AuthorizationURL string `json:"authorizationURL"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if boundState == "" || !strings.HasSuffix(resp.Data.AuthorizationURL, "state="+boundState) {
+		t.Errorf("Expected bound state in %s, got %q", resp.Data.AuthorizationURL, boundState)
+	}
+}
+
+func TestHandler_SearchItemsWithFilters(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+
+	backlogItemService := &stubBacklogItemService{}
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(backlogItemService, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	resp := execute(t, handler, "user1", `{ searchItems(projectIds: ["10"], typeIds: [1, 3], createdUserIds: ["2"], since: "2024-04-01") { items { id typeId } } }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+
+	criteria := backlogItemService.lastCriteria
+	if len(criteria.ProjectIDs) != 1 || len(criteria.TypeIDs) != 2 || len(criteria.CreatedUserIDs) != 1 || criteria.Since.IsZero() {
+		t.Errorf("Unexpected criteria: %+v", criteria)
+	}
+
+	resp = execute(t, handler, "user1", `{ searchItems(typeIds: [99]) { items { id } } }`)
+	errs, _ := resp["errors"].([]interface{})
+	if len(errs) == 0 {
+		t.Fatal("Expected invalid argument error, but got nil")
+	}
+	extensions, _ := errs[0].(map[string]interface{})["extensions"].(map[string]interface{})
+	if extensions["code"] != "INVALID_ARGUMENT" {
+		t.Errorf("Expected INVALID_ARGUMENT error code, got %v", extensions["code"])
+	}
+}
+
+func TestHandler_SearchItemsContentAndURL(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	resp := execute(t, handler, "user1", `{ searchItems { items { url content {
+		__typename
+		... on IssueContent { issueKey changes { field oldValue newValue } }
+		... on WikiContent { name }
+	} } } }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+
+	items := resp["data"].(map[string]interface{})["searchItems"].(map[string]interface{})["items"].([]interface{})
+	if items[0].(map[string]interface{})["url"] != "https://example.backlog.jp/view/PROJ-1" {
+		t.Errorf("Unexpected url: %v", items[0].(map[string]interface{})["url"])
+	}
+	content := items[0].(map[string]interface{})["content"].(map[string]interface{})
+	if content["__typename"] != "IssueContent" || content["issueKey"] != "PROJ-1" {
+		t.Errorf("Unexpected content: %v", content)
+	}
+	changes := content["changes"].([]interface{})
+	if len(changes) != 1 || changes[0].(map[string]interface{})["newValue"] != "2" {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+}
+
+func TestHandler_Projects(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+
+	backlogItemService := &stubBacklogItemService{}
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(backlogItemService, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	resp := execute(t, handler, "user1", `{ projects { id projectKey name archived } }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+	projects := resp["data"].(map[string]interface{})["projects"].([]interface{})
+	if len(projects) != 1 || projects[0].(map[string]interface{})["projectKey"] != "PROJ" {
+		t.Errorf("Unexpected projects: %v", projects)
+	}
+
+	// プロジェクトキーで指定したプロジェクトの更新情報をプロジェクトの情報とともに返す
+	resp = execute(t, handler, "user1", `{ projectItems(projectId: "PROJ", keyword: "ログイン") { project { id name } items { id projectId } nextCursor } }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+	page := resp["data"].(map[string]interface{})["projectItems"].(map[string]interface{})
+	if page["project"].(map[string]interface{})["id"] != "10" || len(page["items"].([]interface{})) != 1 {
+		t.Errorf("Unexpected project items: %v", page)
+	}
+	criteria := backlogItemService.lastCriteria
+	if len(criteria.ProjectIDs) != 1 || criteria.ProjectIDs[0] != "10" || criteria.Keyword != "ログイン" {
+		t.Errorf("Unexpected criteria: %+v", criteria)
+	}
+
+	resp = execute(t, handler, "user1", `{ projectItems(projectId: "UNKNOWN") { items { id } } }`)
+	errs, _ := resp["errors"].([]interface{})
+	if len(errs) == 0 {
+		t.Fatal("Expected not found error, but got nil")
+	}
+	extensions, _ := errs[0].(map[string]interface{})["extensions"].(map[string]interface{})
+	if extensions["code"] != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND error code, got %v", extensions["code"])
+	}
+}
+
+func TestHandler_PersonalFeed(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+
+	backlogItemService := &stubBacklogItemService{}
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(backlogItemService, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	resp := execute(t, handler, "user1", `{ myItems { items { id } } personalFeed(limit: 10) { items { id } nextCursor } }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+
+	// 担当・ウォッチしている課題の課題キーを重複なくフィードの条件にする
+	feed := backlogItemService.lastFeed
+	if feed.UserID != "user1" || len(feed.IssueKeys) != 3 {
+		t.Errorf("Unexpected feed: %+v", feed)
+	}
+}
+
+func TestHandler_RequiresAuthentication(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, memory.NewFavoriteRepository(), authUseCase, nil)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	resp := execute(t, handler, "", `{ authStatus { isAuthenticated } }`)
+	status := resp["data"].(map[string]interface{})["authStatus"].(map[string]interface{})
+	if status["isAuthenticated"].(bool) {
+		t.Error("Expected unauthenticated status")
+	}
+
+	resp = execute(t, handler, "", `{ favorites { items { id } } }`)
+	errs, _ := resp["errors"].([]interface{})
+	if len(errs) == 0 {
+		t.Fatal("Expected authentication error, but got nil")
+	}
+	extensions, _ := errs[0].(map[string]interface{})["extensions"].(map[string]interface{})
+	if extensions["code"] != "UNAUTHORIZED" {
+		t.Errorf("Expected UNAUTHORIZED error code, got %v", extensions["code"])
+	}
+}
+
+func TestHandler_Collections(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+
+	favoriteRepo := memory.NewFavoriteRepository()
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, favoriteRepo, authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(favoriteRepo))
+
+	resp := execute(t, handler, "user1", `mutation { createCollection(name: "リリース") { id name } }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+	collectionID := resp["data"].(map[string]interface{})["createCollection"].(map[string]interface{})["id"].(string)
+
+	execute(t, handler, "user1", `mutation { addFavorite(itemId: "1") }`)
+	resp = execute(t, handler, "user1", `mutation { updateFavorite(itemId: "1", collectionId: "`+collectionID+`", tags: ["重要"], note: "確認する") }`)
+	if resp["errors"] != nil {
+		t.Fatalf("Unexpected errors: %v", resp["errors"])
+	}
+
+	resp = execute(t, handler, "user1", `{ favorites(collectionId: "`+collectionID+`", limit: 10) { items { id collectionId tags note } nextCursor } }`)
+	page := resp["data"].(map[string]interface{})["favorites"].(map[string]interface{})
+	if page["nextCursor"] != nil {
+		t.Errorf("Expected no next cursor, got %v", page["nextCursor"])
+	}
+	items := page["items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 favorite, got %d", len(items))
+	}
+	item := items[0].(map[string]interface{})
+	if item["collectionId"] != collectionID || item["note"] != "確認する" || len(item["tags"].([]interface{})) != 1 {
+		t.Errorf("Unexpected favorite: %v", item)
+	}
+
+	resp = execute(t, handler, "user1", `{ collection(id: "unknown") { id } }`)
+	errs, _ := resp["errors"].([]interface{})
+	if len(errs) == 0 {
+		t.Fatal("Expected not found error, but got nil")
+	}
+	extensions, _ := errs[0].(map[string]interface{})["extensions"].(map[string]interface{})
+	if extensions["code"] != "NOT_FOUND" {
+		t.Errorf("Expected NOT_FOUND error code, got %v", extensions["code"])
+	}
+}
+
+// 交換コードで発行したセッショントークンをレスポンスに含めず、Cookieに保存する関数に渡すことを確認する
+func TestHandler_AuthorizeCallbackBindsSession(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	code, err := sessionUseCase.CreateExchangeCode("user1")
+	if err != nil {
+		t.Fatalf("Failed to create exchange code: %v", err)
+	}
+
+	var boundToken string
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":     `mutation($code: String!) { authorizeCallback(code: $code) { success } }`,
+		"variables": map[string]string{"code": code},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	req = req.WithContext(WithSessionBinder(req.Context(), func(sessionToken string) { boundToken = sessionToken }))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), `"success":true`) {
+		t.Fatalf("Expected success, got %s", rec.Body.String())
+	}
+	if boundToken == "" || strings.Contains(rec.Body.String(), boundToken) {
+		t.Errorf("Expected session token to be bound but not returned, got %q in %s", boundToken, rec.Body.String())
+	}
+	if session, err := sessionUseCase.ResolveSession(boundToken); err != nil || session.UserID != "user1" {
+		t.Errorf("Expected bound token to resolve to user1, got %+v %v", session, err)
+	}
+}
+
+// ログアウトでセッションを破棄し、ブラウザのセッションCookieを削除することを確認する
+func TestHandler_LogoutClearsSession(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := usecase.NewAuthUseCase(&stubAuthService{}, authRepo)
+	backlogItemUseCase := usecase.NewBacklogItemUseCase(&stubBacklogItemService{}, memory.NewFavoriteRepository(), authUseCase, nil)
+	sessionUseCase := usecase.NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+	handler := NewHandler(authUseCase, sessionUseCase, backlogItemUseCase, usecase.NewCollectionUseCase(memory.NewFavoriteRepository()))
+
+	sessionToken, _, err := sessionUseCase.CreateSession("user1")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	boundToken := "unchanged"
+	body, _ := json.Marshal(map[string]string{"query": `mutation { logout }`})
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	ctx := WithUserID(req.Context(), "user1")
+	ctx = WithSessionBinder(ctx, func(sessionToken string) { boundToken = sessionToken })
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req.WithContext(ctx))
+
+	if !strings.Contains(rec.Body.String(), `"logout":true`) {
+		t.Fatalf("Expected logout to succeed, got %s", rec.Body.String())
+	}
+	if boundToken != "" {
+		t.Errorf("Expected session cookie to be cleared, got %q", boundToken)
+	}
+	if _, err := sessionUseCase.ResolveSession(sessionToken); err == nil {
+		t.Error("Expected session to be deleted")
+	}
+}
@@ -0,0 +1,433 @@
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+// Resolver はQueryとMutationのルートリゾルバー
+type Resolver struct {
+	authUseCase        *usecase.AuthUseCase
+	sessionUseCase     *usecase.SessionUseCase
+	backlogItemUseCase *usecase.BacklogItemUseCase
+	collectionUseCase  *usecase.CollectionUseCase
+}
+
+// SearchItems は更新情報を検索する
+func (r *Resolver) SearchItems(ctx context.Context, args struct {
+	Keyword        *string
+	ProjectIDs     *[]gql.ID
+	TypeIDs        *[]int32
+	CreatedUserIDs *[]gql.ID
+	Since          *string
+	Until          *string
+	Cursor         *string
+	Limit          *int32
+}) (*backlogItemPageResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	input := searchInput(args.Keyword, args.TypeIDs, args.CreatedUserIDs, args.Since, args.Until)
+	input.ProjectIDs = idsToStrings(args.ProjectIDs)
+
+	result, err := r.backlogItemUseCase.SearchItems(userID, input, pageRequest(args.Cursor, args.Limit))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &backlogItemPageResolver{page: result}, nil
+}
+
+// MyItems はユーザー自身が作成した更新情報を取得する
+func (r *Resolver) MyItems(ctx context.Context, args struct {
+	Cursor *string
+	Limit  *int32
+}) (*backlogItemPageResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	result, err := r.backlogItemUseCase.GetMyItems(userID, pageRequest(args.Cursor, args.Limit))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &backlogItemPageResolver{page: result}, nil
+}
+
+// PersonalFeed はユーザー自身と、担当・ウォッチしている課題の更新情報を取得する
+func (r *Resolver) PersonalFeed(ctx context.Context, args struct {
+	Cursor *string
+	Limit  *int32
+}) (*backlogItemPageResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	result, err := r.backlogItemUseCase.GetPersonalFeed(userID, pageRequest(args.Cursor, args.Limit))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &backlogItemPageResolver{page: result}, nil
+}
+
+// Projects は参加しているプロジェクトを取得する
+func (r *Resolver) Projects(ctx context.Context) ([]*projectResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	projects, err := r.backlogItemUseCase.GetProjects(userID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resolvers := make([]*projectResolver, len(projects))
+	for i, project := range projects {
+		resolvers[i] = &projectResolver{project: project}
+	}
+	return resolvers, nil
+}
+
+// ProjectItems はプロジェクトの更新情報を検索する
+func (r *Resolver) ProjectItems(ctx context.Context, args struct {
+	ProjectID      gql.ID
+	Keyword        *string
+	TypeIDs        *[]int32
+	CreatedUserIDs *[]gql.ID
+	Since          *string
+	Until          *string
+	Cursor         *string
+	Limit          *int32
+}) (*projectItemPageResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	input := searchInput(args.Keyword, args.TypeIDs, args.CreatedUserIDs, args.Since, args.Until)
+	result, err := r.backlogItemUseCase.GetProjectItems(userID, string(args.ProjectID), input, pageRequest(args.Cursor, args.Limit))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &projectItemPageResolver{page: result}, nil
+}
+
+// Favorites はお気に入りの更新情報を取得する
+func (r *Resolver) Favorites(ctx context.Context, args struct {
+	CollectionID *gql.ID
+	Tag          *string
+	Cursor       *string
+	Limit        *int32
+}) (*backlogItemPageResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	filter := usecase.FavoriteFilter{
+		CollectionID: idToString(args.CollectionID),
+		Tag:          derefString(args.Tag),
+	}
+
+	result, err := r.backlogItemUseCase.GetFavorites(userID, filter, pageRequest(args.Cursor, args.Limit))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &backlogItemPageResolver{page: result}, nil
+}
+
+// Collections はお気に入りのコレクションを取得する
+func (r *Resolver) Collections(ctx context.Context) ([]*collectionResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	collections, err := r.collectionUseCase.ListCollections(userID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return newCollectionResolvers(collections), nil
+}
+
+// Collection はお気に入りのコレクションを取得する
+func (r *Resolver) Collection(ctx context.Context, args struct{ ID gql.ID }) (*collectionResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	collection, err := r.collectionUseCase.GetCollection(userID, string(args.ID))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &collectionResolver{collection: collection}, nil
+}
+
+// AuthStatus は認証状態を取得する
+func (r *Resolver) AuthStatus(ctx context.Context) *authStatusResolver {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return &authStatusResolver{}
+	}
+
+	user, err := r.authUseCase.GetCurrentUser(userID)
+	if err != nil {
+		return &authStatusResolver{}
+	}
+
+	return &authStatusResolver{user: user}
+}
+
+// AuthorizationURL は認可URLを取得する
+// stateをリクエスト元のブラウザに保存できない場合、コールバックは拒否される
+func (r *Resolver) AuthorizationURL(ctx context.Context) (string, error) {
+	authURL, state, err := r.authUseCase.GetAuthorizationURL()
+	if err != nil {
+		return "", wrapError(err)
+	}
+
+	if bind, ok := stateBinderFromContext(ctx); ok {
+		bind(state)
+	}
+	return authURL, nil
+}
+
+// AddFavorite はお気に入りを追加する
+func (r *Resolver) AddFavorite(ctx context.Context, args struct{ ItemID gql.ID }) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	if err := r.backlogItemUseCase.AddFavorite(userID, string(args.ItemID)); err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// RemoveFavorite はお気に入りを削除する
+func (r *Resolver) RemoveFavorite(ctx context.Context, args struct{ ItemID gql.ID }) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	if err := r.backlogItemUseCase.RemoveFavorite(userID, string(args.ItemID)); err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// CollectionMembers はコレクションのオーナーとメンバーを取得する
+func (r *Resolver) CollectionMembers(ctx context.Context, args struct{ CollectionID gql.ID }) ([]*collectionMemberResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	members, err := r.collectionUseCase.ListMembers(userID, string(args.CollectionID))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	resolvers := make([]*collectionMemberResolver, len(members))
+	for i, member := range members {
+		resolvers[i] = &collectionMemberResolver{member: member}
+	}
+	return resolvers, nil
+}
+
+// UpdateFavorite はお気に入りのコレクション・タグ・メモを更新する
+func (r *Resolver) UpdateFavorite(ctx context.Context, args struct {
+	ItemID       gql.ID
+	CollectionID *gql.ID
+	Tags         *[]string
+	Note         *string
+}) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	input := usecase.FavoriteAnnotationInput{
+		CollectionID: idToString(args.CollectionID),
+		Note:         derefString(args.Note),
+	}
+	if args.Tags != nil {
+		input.Tags = *args.Tags
+	}
+
+	if err := r.backlogItemUseCase.AnnotateFavorite(userID, string(args.ItemID), input); err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// CreateCollection はお気に入りのコレクションを作成する
+func (r *Resolver) CreateCollection(ctx context.Context, args struct {
+	Name        string
+	Description *string
+}) (*collectionResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	collection, err := r.collectionUseCase.CreateCollection(userID, usecase.CollectionInput{
+		Name:        args.Name,
+		Description: derefString(args.Description),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &collectionResolver{collection: collection}, nil
+}
+
+// UpdateCollection はお気に入りのコレクションを更新する
+func (r *Resolver) UpdateCollection(ctx context.Context, args struct {
+	ID          gql.ID
+	Name        string
+	Description *string
+}) (*collectionResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	collection, err := r.collectionUseCase.UpdateCollection(userID, string(args.ID), usecase.CollectionInput{
+		Name:        args.Name,
+		Description: derefString(args.Description),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &collectionResolver{collection: collection}, nil
+}
+
+// DeleteCollection はお気に入りのコレクションを削除する
+func (r *Resolver) DeleteCollection(ctx context.Context, args struct{ ID gql.ID }) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	if err := r.collectionUseCase.DeleteCollection(userID, string(args.ID)); err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// RemoveCollectionItem は更新情報をコレクションから除外する
+func (r *Resolver) RemoveCollectionItem(ctx context.Context, args struct {
+	CollectionID gql.ID
+	ItemID       gql.ID
+}) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	if err := r.collectionUseCase.RemoveCollectionItem(userID, string(args.CollectionID), string(args.ItemID)); err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// InviteCollectionMember はコレクションにメンバーを招待する
+func (r *Resolver) InviteCollectionMember(ctx context.Context, args struct {
+	CollectionID gql.ID
+	UserID       gql.ID
+	Role         string
+}) (*collectionMemberResolver, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return nil, errUnauthenticated
+	}
+
+	member, err := r.collectionUseCase.InviteMember(userID, string(args.CollectionID), usecase.CollectionMemberInput{
+		UserID: string(args.UserID),
+		Role:   roleFromEnum(args.Role),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &collectionMemberResolver{member: member}, nil
+}
+
+// RemoveCollectionMember はコレクションからメンバーを削除する
+func (r *Resolver) RemoveCollectionMember(ctx context.Context, args struct {
+	CollectionID gql.ID
+	UserID       gql.ID
+}) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	if err := r.collectionUseCase.RemoveMember(userID, string(args.CollectionID), string(args.UserID)); err != nil {
+		return false, wrapError(err)
+	}
+
+	return true, nil
+}
+
+// AuthorizeCallback は交換コードをセッションに交換し、セッショントークンをリクエスト元のブラウザのCookieに保存する
+// Cookieを保存できない場合は交換コードを消費せずにエラーを返す
+func (r *Resolver) AuthorizeCallback(ctx context.Context, args struct{ Code string }) (*authResultResolver, error) {
+	bind, ok := sessionBinderFromContext(ctx)
+	if !ok {
+		return nil, errSessionUnavailable
+	}
+
+	sessionToken, session, err := r.sessionUseCase.ExchangeSession(args.Code)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	user, err := r.authUseCase.GetCurrentUser(session.UserID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	bind(sessionToken)
+	return &authResultResolver{user: user}, nil
+}
+
+// Logout はログアウト処理を行い、リクエスト元のブラウザのセッションCookieを削除する
+func (r *Resolver) Logout(ctx context.Context) (bool, error) {
+	userID, ok := userIDFromContext(ctx)
+	if !ok {
+		return false, errUnauthenticated
+	}
+
+	if err := r.authUseCase.Logout(userID); err != nil {
+		return false, wrapError(err)
+	}
+
+	if bind, ok := sessionBinderFromContext(ctx); ok {
+		bind("")
+	}
+	return true, nil
+}
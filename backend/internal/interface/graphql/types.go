@@ -0,0 +1,377 @@
+package graphql
+
+import (
+	"strings"
+	"time"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/usecase"
+)
+
+// backlogItemResolver はBacklogItem型のリゾルバー
+type backlogItemResolver struct {
+	item *usecase.BacklogItemOutput
+}
+
+// newBacklogItemResolvers は出力用データのリストからリゾルバーのリストを生成
+func newBacklogItemResolvers(items []*usecase.BacklogItemOutput) []*backlogItemResolver {
+	resolvers := make([]*backlogItemResolver, len(items))
+	for i, item := range items {
+		resolvers[i] = &backlogItemResolver{item: item}
+	}
+	return resolvers
+}
+
+func (r *backlogItemResolver) ID() gql.ID {
+	return gql.ID(r.item.ID)
+}
+
+func (r *backlogItemResolver) ProjectID() string {
+	return r.item.ProjectID
+}
+
+func (r *backlogItemResolver) ProjectKey() string {
+	return r.item.ProjectKey
+}
+
+func (r *backlogItemResolver) ProjectName() string {
+	return r.item.ProjectName
+}
+
+func (r *backlogItemResolver) TypeID() int32 {
+	return int32(r.item.TypeID)
+}
+
+func (r *backlogItemResolver) Type() string {
+	return r.item.Type
+}
+
+func (r *backlogItemResolver) ContentSummary() string {
+	return r.item.ContentSummary
+}
+
+func (r *backlogItemResolver) CreatedUser() *userResolver {
+	return &userResolver{user: &model.User{
+		ID:          r.item.CreatedUser.ID,
+		Name:        r.item.CreatedUser.Name,
+		RoleType:    r.item.CreatedUser.RoleType,
+		Lang:        r.item.CreatedUser.Lang,
+		MailAddress: r.item.CreatedUser.MailAddress,
+	}}
+}
+
+func (r *backlogItemResolver) Created() string {
+	return r.item.Created.Format(time.RFC3339)
+}
+
+func (r *backlogItemResolver) URL() string {
+	return r.item.URL
+}
+
+func (r *backlogItemResolver) IsFavorite() bool {
+	return r.item.IsFavorite
+}
+
+func (r *backlogItemResolver) Score() *float64 {
+	if r.item.Score == 0 {
+		return nil
+	}
+	return &r.item.Score
+}
+
+func (r *backlogItemResolver) Highlights() []*highlightResolver {
+	resolvers := make([]*highlightResolver, len(r.item.Highlights))
+	for i := range r.item.Highlights {
+		resolvers[i] = &highlightResolver{highlight: &r.item.Highlights[i]}
+	}
+	return resolvers
+}
+
+// highlightResolver はHighlight型のリゾルバー
+type highlightResolver struct {
+	highlight *model.Highlight
+}
+
+func (r *highlightResolver) Field() string {
+	return r.highlight.Field
+}
+
+func (r *highlightResolver) Segments() []*highlightSegmentResolver {
+	resolvers := make([]*highlightSegmentResolver, len(r.highlight.Segments))
+	for i := range r.highlight.Segments {
+		resolvers[i] = &highlightSegmentResolver{segment: &r.highlight.Segments[i]}
+	}
+	return resolvers
+}
+
+// highlightSegmentResolver はHighlightSegment型のリゾルバー
+type highlightSegmentResolver struct {
+	segment *model.HighlightSegment
+}
+
+func (r *highlightSegmentResolver) Text() string {
+	return r.segment.Text
+}
+
+func (r *highlightSegmentResolver) Match() bool {
+	return r.segment.Match
+}
+
+func (r *backlogItemResolver) CollectionID() *gql.ID {
+	if r.item.CollectionID == "" {
+		return nil
+	}
+	id := gql.ID(r.item.CollectionID)
+	return &id
+}
+
+func (r *backlogItemResolver) Tags() []string {
+	if r.item.Tags == nil {
+		return []string{}
+	}
+	return r.item.Tags
+}
+
+func (r *backlogItemResolver) Note() *string {
+	return optionalString(r.item.Note)
+}
+
+// collectionResolver はCollection型のリゾルバー
+type collectionResolver struct {
+	collection *usecase.CollectionOutput
+}
+
+// newCollectionResolvers は出力用データのリストからリゾルバーのリストを生成
+func newCollectionResolvers(collections []*usecase.CollectionOutput) []*collectionResolver {
+	resolvers := make([]*collectionResolver, len(collections))
+	for i, collection := range collections {
+		resolvers[i] = &collectionResolver{collection: collection}
+	}
+	return resolvers
+}
+
+func (r *collectionResolver) ID() gql.ID {
+	return gql.ID(r.collection.ID)
+}
+
+func (r *collectionResolver) OwnerID() gql.ID {
+	return gql.ID(r.collection.OwnerID)
+}
+
+func (r *collectionResolver) Name() string {
+	return r.collection.Name
+}
+
+func (r *collectionResolver) Description() *string {
+	return optionalString(r.collection.Description)
+}
+
+func (r *collectionResolver) Role() string {
+	return roleToEnum(r.collection.Role)
+}
+
+func (r *collectionResolver) CreatedAt() string {
+	return r.collection.CreatedAt.Format(time.RFC3339)
+}
+
+func (r *collectionResolver) UpdatedAt() string {
+	return r.collection.UpdatedAt.Format(time.RFC3339)
+}
+
+// collectionMemberResolver はCollectionMember型のリゾルバー
+type collectionMemberResolver struct {
+	member *model.CollectionMember
+}
+
+func (r *collectionMemberResolver) UserID() gql.ID {
+	return gql.ID(r.member.UserID)
+}
+
+func (r *collectionMemberResolver) Role() string {
+	return roleToEnum(r.member.Role)
+}
+
+func (r *collectionMemberResolver) AddedAt() string {
+	return r.member.AddedAt.Format(time.RFC3339)
+}
+
+// roleToEnum はコレクションの権限をCollectionRole列挙型の値に変換
+func roleToEnum(role model.CollectionRole) string {
+	return strings.ToUpper(string(role))
+}
+
+// roleFromEnum はCollectionRole列挙型の値をコレクションの権限に変換
+func roleFromEnum(role string) model.CollectionRole {
+	return model.CollectionRole(strings.ToLower(role))
+}
+
+// backlogItemPageResolver はBacklogItemPage型のリゾルバー
+type backlogItemPageResolver struct {
+	page *usecase.BacklogItemPageOutput
+}
+
+func (r *backlogItemPageResolver) Items() []*backlogItemResolver {
+	return newBacklogItemResolvers(r.page.Items)
+}
+
+func (r *backlogItemPageResolver) NextCursor() *string {
+	return optionalString(r.page.NextCursor)
+}
+
+// projectResolver はProject型のリゾルバー
+type projectResolver struct {
+	project *model.Project
+}
+
+func (r *projectResolver) ID() gql.ID {
+	return gql.ID(r.project.ID)
+}
+
+func (r *projectResolver) ProjectKey() string {
+	return r.project.ProjectKey
+}
+
+func (r *projectResolver) Name() string {
+	return r.project.Name
+}
+
+func (r *projectResolver) Archived() bool {
+	return r.project.Archived
+}
+
+// projectItemPageResolver はProjectItemPage型のリゾルバー
+type projectItemPageResolver struct {
+	page *usecase.ProjectItemPageOutput
+}
+
+func (r *projectItemPageResolver) Project() *projectResolver {
+	return &projectResolver{project: r.page.Project}
+}
+
+func (r *projectItemPageResolver) Items() []*backlogItemResolver {
+	return newBacklogItemResolvers(r.page.Items)
+}
+
+func (r *projectItemPageResolver) NextCursor() *string {
+	return optionalString(r.page.NextCursor)
+}
+
+// userResolver はUser型のリゾルバー
+type userResolver struct {
+	user *model.User
+}
+
+func (r *userResolver) ID() gql.ID {
+	return gql.ID(r.user.ID)
+}
+
+func (r *userResolver) Name() string {
+	return r.user.Name
+}
+
+func (r *userResolver) RoleType() int32 {
+	return int32(r.user.RoleType)
+}
+
+func (r *userResolver) Lang() *string {
+	return optionalString(r.user.Lang)
+}
+
+func (r *userResolver) MailAddress() *string {
+	return optionalString(r.user.MailAddress)
+}
+
+// authStatusResolver はAuthStatus型のリゾルバー
+type authStatusResolver struct {
+	user *model.User
+}
+
+func (r *authStatusResolver) IsAuthenticated() bool {
+	return r.user != nil
+}
+
+func (r *authStatusResolver) User() *userResolver {
+	if r.user == nil {
+		return nil
+	}
+	return &userResolver{user: r.user}
+}
+
+// authResultResolver はAuthResult型のリゾルバー
+type authResultResolver struct {
+	user *model.User
+}
+
+func (r *authResultResolver) Success() bool {
+	return r.user != nil
+}
+
+func (r *authResultResolver) User() *userResolver {
+	if r.user == nil {
+		return nil
+	}
+	return &userResolver{user: r.user}
+}
+
+// derefString はnullの引数を空文字列として扱う
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// searchInput はプロジェクト以外の検索条件の引数から検索条件の入力データを作成
+func searchInput(keyword *string, typeIDs *[]int32, createdUserIDs *[]gql.ID, since, until *string) usecase.SearchInput {
+	input := usecase.SearchInput{
+		Keyword:        derefString(keyword),
+		CreatedUserIDs: idsToStrings(createdUserIDs),
+		Since:          derefString(since),
+		Until:          derefString(until),
+	}
+	if typeIDs != nil {
+		for _, typeID := range *typeIDs {
+			input.TypeIDs = append(input.TypeIDs, int(typeID))
+		}
+	}
+	return input
+}
+
+// idsToStrings はID型のリスト引数を文字列のリストに変換
+func idsToStrings(ids *[]gql.ID) []string {
+	if ids == nil {
+		return nil
+	}
+
+	values := make([]string, len(*ids))
+	for i, id := range *ids {
+		values[i] = string(id)
+	}
+	return values
+}
+
+// idToString はnullのID引数を空文字列として扱う
+func idToString(id *gql.ID) string {
+	if id == nil {
+		return ""
+	}
+	return string(*id)
+}
+
+// pageRequest はcursor・limit引数からページングの指定を作成
+func pageRequest(cursor *string, limit *int32) model.PageRequest {
+	page := model.PageRequest{Cursor: derefString(cursor)}
+	if limit != nil {
+		page.Limit = int(*limit)
+	}
+	return page
+}
+
+// optionalString は空文字列をnullとして扱う
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// ActivityIngester は認証済みユーザーごとにBacklog更新情報を定期的に取り込み、全文検索インデックスを更新する
+// 取り込んだ更新情報と取り込み状況はストアに保存し、再起動したサーバーや別のサーバーのインデックスをストアから復元する
+type ActivityIngester struct {
+	authRepository     model.AuthRepository
+	authUseCase        *AuthUseCase
+	backlogItemService model.BacklogItemService
+	activityIndex      model.ActivityIndex
+	activityStore      model.ActivityStore
+	backfillPages      int
+	mu                 sync.Mutex
+}
+
+// NewActivityIngester はActivityIngesterのインスタンスを生成
+// backfillPagesは1回の取り込みで遡るページ数（1ページ100件）の上限
+func NewActivityIngester(
+	authRepository model.AuthRepository,
+	authUseCase *AuthUseCase,
+	backlogItemService model.BacklogItemService,
+	activityIndex model.ActivityIndex,
+	activityStore model.ActivityStore,
+	backfillPages int,
+) *ActivityIngester {
+	return &ActivityIngester{
+		authRepository:     authRepository,
+		authUseCase:        authUseCase,
+		backlogItemService: backlogItemService,
+		activityIndex:      activityIndex,
+		activityStore:      activityStore,
+		backfillPages:      backfillPages,
+	}
+}
+
+// Run はintervalごとに全ユーザーの更新情報を取り込み、ctxがキャンセルされるまで繰り返す
+func (i *ActivityIngester) Run(ctx context.Context, interval time.Duration) {
+	i.IngestAll()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			i.IngestAll()
+		}
+	}
+}
+
+// IngestAll はトークンを保存している全ユーザーの更新情報を取り込み、トークンがなくなったユーザーのインデックスと保存した更新情報を削除
+func (i *ActivityIngester) IngestAll() {
+	tokens, err := i.authRepository.GetAllTokens()
+	if err != nil {
+		log.Printf("Failed to list tokens for ingestion: %v", err)
+		return
+	}
+
+	active := make(map[string]bool)
+	for _, token := range tokens {
+		active[token.UserID] = true
+		if err := i.IngestUser(token.UserID); err != nil {
+			log.Printf("Failed to ingest activities for user %s: %v", token.UserID, err)
+		}
+	}
+
+	// ログアウトなどでトークンが削除されたユーザーのインデックスと保存した更新情報は保持しない
+	users, err := i.activityStore.ActivityUsers()
+	if err != nil {
+		log.Printf("Failed to list stored activity users: %v", err)
+	}
+	for _, userID := range append(users, i.activityIndex.Users()...) {
+		if !active[userID] {
+			i.removeUser(userID)
+		}
+	}
+}
+
+// IngestUser はユーザー自身のアクセストークンで更新情報を取り込む
+// 1回の取り込みでは合計backfillPagesページまで、前回より新しい更新情報、前回遡りきれなかった範囲、
+// インデックスより古い更新情報の順に取り込み、残った範囲は次回以降に再開する
+func (i *ActivityIngester) IngestUser(userID string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	token, err := i.authUseCase.GetValidToken(userID)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			i.removeUser(userID)
+		}
+		return err
+	}
+
+	if err := i.restore(userID); err != nil {
+		return err
+	}
+
+	state := i.activityIndex.IngestState(userID)
+	latestID := i.activityIndex.LatestID(userID)
+	oldestID := i.activityIndex.OldestID(userID)
+	pages := max(i.backfillPages, 1)
+
+	// 前回の最新の更新情報より新しいもの（初回は最新から遡れるところまで）
+	items, resumeID, reachedOldest, err := i.fetchRange(token.AccessToken, 0, latestID, &pages)
+	if err != nil {
+		return err
+	}
+	if latestID == 0 {
+		state.ReachedOldest = reachedOldest
+	} else if resumeID > 0 {
+		state.Gaps = append([]model.IngestGap{{MaxID: resumeID, MinID: latestID}}, state.Gaps...)
+	}
+
+	// 前回までに遡りきれなかった範囲
+	gaps := make([]model.IngestGap, 0, len(state.Gaps))
+	for _, gap := range state.Gaps {
+		if pages > 0 {
+			gapItems, gapResumeID, _, err := i.fetchRange(token.AccessToken, gap.MaxID, gap.MinID, &pages)
+			if err != nil {
+				return err
+			}
+			items = append(items, gapItems...)
+			if gapResumeID == 0 {
+				continue
+			}
+			gap.MaxID = gapResumeID
+		}
+		gaps = append(gaps, gap)
+	}
+	state.Gaps = gaps
+
+	// インデックスより古い更新情報
+	if oldestID > 0 && !state.ReachedOldest && !state.Truncated && pages > 0 {
+		olderItems, _, reachedOldest, err := i.fetchRange(token.AccessToken, oldestID, 0, &pages)
+		if err != nil {
+			return err
+		}
+		items = append(items, olderItems...)
+		state.ReachedOldest = reachedOldest
+	}
+
+	state.Version++
+	if err := i.activityIndex.Add(userID, items, state); err != nil {
+		return err
+	}
+	return i.save(userID, items)
+}
+
+// restore は保存済みの取り込み状況がインデックスと異なる場合に、保存済みの更新情報でインデックスを作り直す
+// 再起動後のほか、別のサーバーが先に取り込んだ場合もその結果から続けて取り込む
+func (i *ActivityIngester) restore(userID string) error {
+	stored, ok, err := i.activityStore.LoadIngestState(userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// 保存されていない場合は最初から取り込む
+		i.activityIndex.Remove(userID)
+		return nil
+	}
+	if i.activityIndex.Ready(userID) && i.activityIndex.IngestState(userID).Version == stored.Version {
+		return nil
+	}
+
+	items, err := i.activityStore.LoadActivities(userID)
+	if err != nil {
+		return err
+	}
+	i.activityIndex.Remove(userID)
+	return i.activityIndex.Add(userID, items, stored)
+}
+
+// save は取り込んだ更新情報とインデックスの取り込み状況を保存し、インデックスの上限を超えて削除した更新情報をストアからも削除する
+// 別のサーバーが先に保存していた場合はその結果からインデックスを作り直し、次回の取り込みで続きを取り込む
+func (i *ActivityIngester) save(userID string, items []*model.BacklogItem) error {
+	state := i.activityIndex.IngestState(userID)
+	if err := i.activityStore.SaveActivities(userID, items, state); err != nil {
+		if errors.Is(err, model.ErrIngestStateConflict) {
+			return i.restore(userID)
+		}
+		// 保存できなかった取り込み結果は捨て、次回は保存済みの内容から取り込み直す
+		i.activityIndex.Remove(userID)
+		return err
+	}
+
+	if state.Truncated {
+		return i.activityStore.PruneActivities(userID, i.activityIndex.OldestID(userID))
+	}
+	return nil
+}
+
+// removeUser はユーザーのインデックスと保存した更新情報を削除
+func (i *ActivityIngester) removeUser(userID string) {
+	i.activityIndex.Remove(userID)
+	if err := i.activityStore.DeleteActivities(userID); err != nil {
+		log.Printf("Failed to delete stored activities for user %s: %v", userID, err)
+	}
+}
+
+// fetchRange はmaxIDより古くminIDより新しい更新情報を新しい順に、残りページ数がなくなるまで取得（maxIDが0の場合は最新から）
+// 残りページ数がなくなって範囲を遡りきれなかった場合は続きを再開する更新情報IDを、最も古い更新情報に達した場合はreachedOldestを返す
+func (i *ActivityIngester) fetchRange(accessToken string, maxID, minID int, pages *int) (items []*model.BacklogItem, resumeID int, reachedOldest bool, err error) {
+	page := model.PageRequest{Limit: model.MaxPageLimit}
+	if maxID > 0 {
+		page.Cursor = model.EncodeActivityCursor(maxID)
+	}
+
+	for *pages > 0 {
+		*pages--
+		result, err := i.backlogItemService.SearchItems(accessToken, model.SearchCriteria{}, page)
+		if err != nil {
+			return nil, 0, false, err
+		}
+
+		for _, item := range result.Items {
+			id, _ := strconv.Atoi(item.ID)
+			if id <= minID {
+				return items, 0, false, nil
+			}
+			items = append(items, item)
+			resumeID = id
+		}
+
+		if result.NextCursor == "" {
+			return items, 0, true, nil
+		}
+		page.Cursor = result.NextCursor
+	}
+
+	// 1件も取得できなかった場合は同じ位置から再開する
+	if resumeID == 0 {
+		resumeID = maxID
+	}
+	return items, resumeID, false, nil
+}
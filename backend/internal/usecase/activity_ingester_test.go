@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/search"
+)
+
+// 取り込んだ更新情報をインデックスから検索し、新しい更新情報だけを追加で取り込むことを確認する
+func TestActivityIngester_IngestUser(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	// Backlog APIと同様に新しい順に返す
+	mockBacklogService.items = []*model.BacklogItem{mockBacklogService.items[1], mockBacklogService.items[0]}
+
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user1", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	index := search.NewActivityIndex(0)
+	ingester := NewActivityIngester(authRepo, authUseCase, mockBacklogService, index, memory.NewActivityStore(), 10)
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, memory.NewFavoriteRepository(), authUseCase, index)
+
+	if err := ingester.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+
+	mockBacklogService.items = append([]*model.BacklogItem{{
+		ID:             "3",
+		ProjectName:    "プロジェクトB",
+		ContentSummary: "ログイン画面のデザイン",
+		Created:        time.Now(),
+	}}, mockBacklogService.items...)
+	if err := ingester.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+	if latest := index.LatestID("user1"); latest != 3 {
+		t.Errorf("Expected latest ID 3, got %d", latest)
+	}
+
+	// インデックスを検索した結果にはスコアとハイライトが含まれる
+	mockBacklogService.items = nil
+	result, err := backlogUseCase.SearchItems("user1", SearchInput{Keyword: "ログイン"}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to search items: %v", err)
+	}
+	if len(result.Items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(result.Items))
+	}
+	if result.Items[0].Score == 0 || len(result.Items[0].Highlights) == 0 {
+		t.Errorf("Expected score and highlights, got %+v", result.Items[0])
+	}
+}
+
+// トークンが削除されたユーザーのインデックスを削除することを確認する
+func TestActivityIngester_IngestAllRemovesLoggedOutUsers(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user1", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	index := search.NewActivityIndex(0)
+	store := memory.NewActivityStore()
+	ingester := NewActivityIngester(authRepo, authUseCase, NewMockBacklogItemService(), index, store, 10)
+
+	ingester.IngestAll()
+	if !index.Ready("user1") {
+		t.Fatal("Expected index to be ready")
+	}
+
+	authRepo.DeleteToken("user1")
+	ingester.IngestAll()
+	if index.Ready("user1") {
+		t.Error("Expected index to be removed after logout")
+	}
+	if _, ok, _ := store.LoadIngestState("user1"); ok {
+		t.Error("Expected stored activities to be removed after logout")
+	}
+}
+
+// 再起動したサーバーや別のサーバーが、保存済みの取り込み結果からインデックスを復元して続きを取り込むことを確認する
+func TestActivityIngester_RestoresFromStore(t *testing.T) {
+	mockBacklogService := &pagedBacklogItemService{NewMockBacklogItemService()}
+	mockBacklogService.items = newActivities(1, 450)
+
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user1", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	store := memory.NewActivityStore()
+	indexA, indexB := search.NewActivityIndex(0), search.NewActivityIndex(0)
+	ingesterA := NewActivityIngester(authRepo, authUseCase, mockBacklogService, indexA, store, 2)
+	ingesterB := NewActivityIngester(authRepo, authUseCase, mockBacklogService, indexB, store, 2)
+
+	// 450から251までを取り込んで保存する
+	if err := ingesterA.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+
+	// 別のサーバーは保存済みの更新情報を復元し、その続き（250から151まで）を取り込む
+	if err := ingesterB.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+	if _, ok := indexB.Get("user1", "450"); !ok {
+		t.Error("Expected ID 450 to be restored from the store")
+	}
+	if oldest := indexB.OldestID("user1"); oldest != 151 {
+		t.Errorf("Expected oldest ID 151, got %d", oldest)
+	}
+
+	// 先に保存されたバージョンと異なるインデックスは作り直してから続きを取り込む
+	if err := ingesterA.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+	if _, ok := indexA.Get("user1", "200"); !ok {
+		t.Error("Expected ID 200 ingested by the other server to be restored")
+	}
+	if oldest := indexA.OldestID("user1"); oldest != 51 {
+		t.Errorf("Expected oldest ID 51, got %d", oldest)
+	}
+	state, ok, err := store.LoadIngestState("user1")
+	if err != nil || !ok {
+		t.Fatalf("Failed to load ingest state: ok=%v err=%v", ok, err)
+	}
+	if state.Version != 3 || state.Version != indexA.IngestState("user1").Version {
+		t.Errorf("Expected stored version 3 to match the index, got %d", state.Version)
+	}
+	items, _ := store.LoadActivities("user1")
+	if len(items) != 400 {
+		t.Errorf("Expected 400 stored activities, got %d", len(items))
+	}
+}
+
+// pagedBacklogItemService はBacklog APIと同様にmaxIdのカーソルで新しい順にページングするモック
+type pagedBacklogItemService struct {
+	*MockBacklogItemService
+}
+
+func (m *pagedBacklogItemService) SearchItems(accessToken string, criteria model.SearchCriteria, page model.PageRequest) (*model.BacklogItemPage, error) {
+	maxID, err := model.DecodeActivityCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*model.BacklogItem
+	for _, item := range m.items {
+		if id, _ := strconv.Atoi(item.ID); maxID > 0 && id >= maxID {
+			continue
+		}
+		if len(items) == page.Size() {
+			last := items[len(items)-1].ID
+			lastID, _ := strconv.Atoi(last)
+			return &model.BacklogItemPage{Items: items, NextCursor: model.EncodeActivityCursor(lastID)}, nil
+		}
+		items = append(items, item)
+	}
+	return &model.BacklogItemPage{Items: items}, nil
+}
+
+// newActivities はfromからtoまでのIDの更新情報を新しい順に作成する
+func newActivities(from, to int) []*model.BacklogItem {
+	items := make([]*model.BacklogItem, 0, to-from+1)
+	for id := to; id >= from; id-- {
+		items = append(items, &model.BacklogItem{ID: strconv.Itoa(id), ContentSummary: "定期更新", Created: time.Now()})
+	}
+	return items
+}
+
+// 1回の取り込みで遡りきれなかった新しい更新情報の範囲と過去の更新情報を、次回以降の取り込みで再開することを確認する
+func TestActivityIngester_ResumesGapsAndBackfill(t *testing.T) {
+	mockBacklogService := &pagedBacklogItemService{NewMockBacklogItemService()}
+	mockBacklogService.items = newActivities(1, 450)
+
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user1", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	index := search.NewActivityIndex(0)
+	ingester := NewActivityIngester(authRepo, authUseCase, mockBacklogService, index, memory.NewActivityStore(), 2)
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, memory.NewFavoriteRepository(), authUseCase, index)
+
+	if err := ingester.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+	if oldest := index.OldestID("user1"); oldest != 251 {
+		t.Errorf("Expected oldest ID 251, got %d", oldest)
+	}
+
+	// インデックスを検索し終えたらBacklog APIで続きを検索する
+	result, err := backlogUseCase.SearchItems("user1", SearchInput{}, model.PageRequest{Cursor: model.EncodeOffsetCursor(190)})
+	if err != nil {
+		t.Fatalf("Failed to search items: %v", err)
+	}
+	if len(result.Items) != 10 || result.NextCursor != model.EncodeActivityCursor(251) {
+		t.Fatalf("Expected 10 items and a Backlog API cursor, got %d items and %q", len(result.Items), result.NextCursor)
+	}
+	result, err = backlogUseCase.SearchItems("user1", SearchInput{}, model.PageRequest{Cursor: result.NextCursor})
+	if err != nil {
+		t.Fatalf("Failed to search items: %v", err)
+	}
+	if len(result.Items) == 0 || result.Items[0].ID != "250" {
+		t.Fatalf("Expected items from ID 250, got %+v", result.Items)
+	}
+
+	// 取り込み間隔の間に2ページを超える更新情報が追加された場合は、遡りきれなかった範囲を記録する
+	mockBacklogService.items = newActivities(1, 800)
+	if err := ingester.IngestUser("user1"); err != nil {
+		t.Fatalf("Failed to ingest activities: %v", err)
+	}
+	state := index.IngestState("user1")
+	if len(state.Gaps) != 1 || state.Gaps[0] != (model.IngestGap{MaxID: 601, MinID: 450}) {
+		t.Fatalf("Expected gap between 601 and 450, got %+v", state.Gaps)
+	}
+	if _, ok := index.Get("user1", "550"); ok {
+		t.Error("Expected ID 550 not to be indexed yet")
+	}
+
+	for n := 0; n < 5; n++ {
+		if err := ingester.IngestUser("user1"); err != nil {
+			t.Fatalf("Failed to ingest activities: %v", err)
+		}
+	}
+
+	state = index.IngestState("user1")
+	if len(state.Gaps) != 0 || !state.ReachedOldest {
+		t.Errorf("Expected all activities to be ingested, got %+v", state)
+	}
+	for id := 1; id <= 800; id++ {
+		if _, ok := index.Get("user1", strconv.Itoa(id)); !ok {
+			t.Fatalf("Expected ID %d to be indexed", id)
+		}
+	}
+}
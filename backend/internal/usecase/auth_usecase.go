@@ -1,14 +1,26 @@
 package usecase
 
 import (
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"time"
 
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
 )
 
+// ErrUnauthorized は再ログインが必要な認証エラー
+// ErrInvalidToken・ErrInvalidSession・ErrInvalidExchangeCodeはerrors.Isでこのエラーと判定できる
+var ErrUnauthorized = errors.New("unauthorized")
+
 // ErrInvalidToken は無効なトークンエラー
-var ErrInvalidToken = errors.New("invalid token")
+var ErrInvalidToken = fmt.Errorf("%w: invalid token", ErrUnauthorized)
+
+// ErrInvalidState は未知・期限切れ・使用済みのOAuth stateエラー
+var ErrInvalidState = errors.New("invalid oauth state")
+
+// oauthStateTTL は認可リクエストのstateの有効期間
+const oauthStateTTL = 10 * time.Minute
 
 // AuthUseCase は認証に関するユースケース
 type AuthUseCase struct {
@@ -24,15 +36,56 @@ func NewAuthUseCase(authService model.AuthService, authRepository model.AuthRepo
 	}
 }
 
-// GetAuthorizationURL は認可URLを取得
-func (u *AuthUseCase) GetAuthorizationURL() string {
-	return u.authService.GetAuthorizationURL()
+// GetAuthorizationURL はログインごとにstateとPKCEのcode_verifierを発行し、認可URLとstateを取得
+// stateはログインを開始したブラウザに保存し、コールバックでAuthorizeCallbackに渡す
+func (u *AuthUseCase) GetAuthorizationURL() (string, string, error) {
+	state, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	codeVerifier, err := randomString(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = u.authRepository.SaveOAuthState(&model.OAuthState{
+		State:        state,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(oauthStateTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return u.authService.GetAuthorizationURL(state, codeVerifier), state, nil
+}
+
+// StateTTL は認可リクエストのstateの有効期間を返す
+func (u *AuthUseCase) StateTTL() time.Duration {
+	return oauthStateTTL
 }
 
-// AuthorizeCallback は認可コードからトークンを取得し保存する
-func (u *AuthUseCase) AuthorizeCallback(code string) (*model.AuthToken, *model.User, error) {
+// AuthorizeCallback はstateを検証した上で認可コードからトークンを取得し保存する
+// browserStateはログインを開始したブラウザに保存したstateで、コールバックのstateと一致しない場合は拒否する
+// （他人が発行したコールバックのURLを踏ませて、そのアカウントでログインさせる攻撃を防ぐため）
+func (u *AuthUseCase) AuthorizeCallback(code, state, browserState string) (*model.AuthToken, *model.User, error) {
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, nil, ErrInvalidState
+	}
+
+	// stateは一度しか使えないよう取得と同時に削除
+	oauthState, err := u.authRepository.ConsumeOAuthState(state)
+	if err != nil {
+		return nil, nil, ErrInvalidState
+	}
+
+	if oauthState.ExpiresAt.Before(time.Now()) {
+		return nil, nil, ErrInvalidState
+	}
+
 	// コードからトークンを取得
-	token, err := u.authService.ExchangeCodeForToken(code)
+	token, err := u.authService.ExchangeCodeForToken(code, oauthState.CodeVerifier)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -58,16 +111,19 @@ func (u *AuthUseCase) AuthorizeCallback(code string) (*model.AuthToken, *model.U
 // GetValidToken はユーザーの有効なトークンを取得
 func (u *AuthUseCase) GetValidToken(userID string) (*model.AuthToken, error) {
 	token, err := u.authRepository.GetTokenByUserID(userID)
+	if errors.Is(err, model.ErrTokenNotFound) {
+		return nil, ErrInvalidToken
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// トークンが有効期限切れかどうかチェック
 	if token.ExpiresAt.Before(time.Now()) {
-		// リフレッシュトークンを使用して新しいトークンを取得
+		// リフレッシュトークンを使用して新しいトークンを取得（失敗した場合は再ログインが必要）
 		newToken, err := u.authService.RefreshToken(token.RefreshToken)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 		}
 
 		// ユーザーIDを設定
@@ -85,7 +141,21 @@ func (u *AuthUseCase) GetValidToken(userID string) (*model.AuthToken, error) {
 	return token, nil
 }
 
-// Logout はユーザーのログアウト処理
+// GetCurrentUser はユーザーの有効なトークンを使ってBacklogユーザー情報を取得
+func (u *AuthUseCase) GetCurrentUser(userID string) (*model.User, error) {
+	token, err := u.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.authService.GetBacklogUser(token.AccessToken)
+}
+
+// Logout はユーザーのログアウト処理（トークンと全セッションを破棄）
 func (u *AuthUseCase) Logout(userID string) error {
+	if err := u.authRepository.DeleteSessionsByUserID(userID); err != nil {
+		return err
+	}
+
 	return u.authRepository.DeleteToken(userID)
 }
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+)
+
+// stateFromURL は認可URLからstateパラメータを取り出す
+func stateFromURL(t *testing.T, authURL string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("Failed to parse authorization URL: %v", err)
+	}
+	return parsed.Query().Get("state")
+}
+
+func TestAuthUseCase_AuthorizeCallbackValidatesState(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	authURL, state, err := authUseCase.GetAuthorizationURL()
+	if err != nil {
+		t.Fatalf("Failed to get authorization URL: %v", err)
+	}
+	if stateFromURL(t, authURL) != state {
+		t.Fatalf("Expected state %s in authorization URL %s", state, authURL)
+	}
+
+	// 未知のstateは拒否される
+	if _, _, err := authUseCase.AuthorizeCallback("code", "unknown", "unknown"); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState for unknown state, got %v", err)
+	}
+
+	// ログインを開始したブラウザのstateと一致しない場合は、発行したstateでも拒否される
+	if _, _, err := authUseCase.AuthorizeCallback("code", state, ""); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState without browser state, got %v", err)
+	}
+	if _, _, err := authUseCase.AuthorizeCallback("code", state, "other"); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState for mismatched browser state, got %v", err)
+	}
+
+	// 発行したstateは一度だけ受け付けられる
+	if _, _, err := authUseCase.AuthorizeCallback("code", state, state); err != nil {
+		t.Fatalf("Failed to authorize callback: %v", err)
+	}
+	if _, _, err := authUseCase.AuthorizeCallback("code", state, state); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState for reused state, got %v", err)
+	}
+}
+
+func TestAuthUseCase_AuthorizeCallbackRejectsExpiredState(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	authRepo.SaveOAuthState(&model.OAuthState{
+		State:        "expired",
+		CodeVerifier: "verifier",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	})
+
+	if _, _, err := authUseCase.AuthorizeCallback("code", "expired", "expired"); err != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState for expired state, got %v", err)
+	}
+}
@@ -2,173 +2,593 @@ package usecase
 
 import (
 	"errors"
+	"slices"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
 )
 
+// ErrFavoriteAlreadyExists は既にお気に入りに登録されている更新情報を追加しようとしたエラー
+var ErrFavoriteAlreadyExists = model.ErrFavoriteAlreadyExists
+
+// ErrFavoriteNotFound はお気に入りに登録されていない更新情報を操作しようとしたエラー
+var ErrFavoriteNotFound = model.ErrFavoriteNotFound
+
 // BacklogItemUseCase はBacklog更新情報に関するユースケース
 type BacklogItemUseCase struct {
 	backlogItemService model.BacklogItemService
 	favoriteRepository model.FavoriteRepository
 	authUseCase        *AuthUseCase
+	activityIndex      model.ActivityIndex
 }
 
 // BacklogItemOutput はBacklogItemの出力用データ
 type BacklogItemOutput struct {
 	ID             string `json:"id"`
 	ProjectID      string `json:"projectId"`
+	ProjectKey     string `json:"projectKey"`
 	ProjectName    string `json:"projectName"`
+	TypeID         int    `json:"typeId"`
 	Type           string `json:"type"`
 	ContentSummary string `json:"contentSummary"`
 	CreatedUser    struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		RoleType    int    `json:"roleType"`
+		Lang        string `json:"lang,omitempty"`
+		MailAddress string `json:"mailAddress,omitempty"`
 	} `json:"createdUser"`
-	Created    time.Time `json:"created"`
-	IsFavorite bool      `json:"isFavorite"`
+	Created    time.Time              `json:"created"`
+	URL        string                 `json:"url"` // Backlogの対応する画面のURL
+	Content    *model.ActivityContent `json:"content,omitempty"`
+	IsFavorite bool                   `json:"isFavorite"`
+	Score      float64                `json:"score,omitempty"`
+	Highlights []model.Highlight      `json:"highlights,omitempty"`
+
+	// CollectionID・Tags・Noteはお気に入り一覧でのみ設定される
+	CollectionID string   `json:"collectionId,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Note         string   `json:"note,omitempty"`
+}
+
+// BacklogItemPageOutput はページングされたBacklogItemの出力用データ
+type BacklogItemPageOutput struct {
+	Items      []*BacklogItemOutput `json:"items"`
+	NextCursor string               `json:"nextCursor,omitempty"`
+}
+
+// ProjectItemPageOutput はプロジェクトの情報とページングされたプロジェクトの更新情報の出力用データ
+type ProjectItemPageOutput struct {
+	Project    *model.Project       `json:"project"`
+	Items      []*BacklogItemOutput `json:"items"`
+	NextCursor string               `json:"nextCursor,omitempty"`
 }
 
 // NewBacklogItemUseCase はBacklogItemUseCaseのインスタンスを生成
+// activityIndexがnilの場合、またはユーザーのインデックスが未作成の場合はBacklog APIを直接検索する
 func NewBacklogItemUseCase(
 	backlogItemService model.BacklogItemService,
 	favoriteRepository model.FavoriteRepository,
 	authUseCase *AuthUseCase,
+	activityIndex model.ActivityIndex,
 ) *BacklogItemUseCase {
 	return &BacklogItemUseCase{
 		backlogItemService: backlogItemService,
 		favoriteRepository: favoriteRepository,
 		authUseCase:        authUseCase,
+		activityIndex:      activityIndex,
 	}
 }
 
-// SearchItems はキーワードでBacklog更新情報を検索
-func (u *BacklogItemUseCase) SearchItems(userID, keyword string) ([]*BacklogItemOutput, error) {
-	// ユーザーのアクセストークンを取得
-	_, err := u.authUseCase.GetValidToken(userID)
+// newBacklogItemOutput はドメインモデルから出力用データを作成
+func newBacklogItemOutput(item *model.BacklogItem, isFavorite bool) *BacklogItemOutput {
+	output := &BacklogItemOutput{
+		ID:             item.ID,
+		ProjectID:      item.ProjectID,
+		ProjectKey:     item.ProjectKey,
+		ProjectName:    item.ProjectName,
+		TypeID:         item.TypeID,
+		Type:           item.Type,
+		ContentSummary: item.ContentSummary,
+		Created:        item.Created,
+		URL:            item.URL,
+		Content:        item.Content,
+		IsFavorite:     isFavorite,
+	}
+	output.CreatedUser.ID = item.CreatedUser.ID
+	output.CreatedUser.Name = item.CreatedUser.Name
+	output.CreatedUser.RoleType = item.CreatedUser.RoleType
+	output.CreatedUser.Lang = item.CreatedUser.Lang
+	output.CreatedUser.MailAddress = item.CreatedUser.MailAddress
+	return output
+}
+
+// SearchItems は検索条件でBacklog更新情報を検索し、カーソル位置から1ページ分を返す
+func (u *BacklogItemUseCase) SearchItems(userID string, input SearchInput, page model.PageRequest) (*BacklogItemPageOutput, error) {
+	criteria, err := input.criteria()
 	if err != nil {
 		return nil, err
 	}
 
-	// 更新情報を検索
-	items, err := u.backlogItemService.SearchItems(keyword)
+	// ユーザーのアクセストークンを取得
+	token, err := u.authUseCase.GetValidToken(userID)
 	if err != nil {
 		return nil, err
 	}
 
 	// ユーザーのお気に入り情報を取得
+	favoriteMap, err := u.favoriteMap(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 取り込み済みのインデックスがあればBacklog APIを呼び出さずに検索
+	// インデックスの準備前や別のサーバーで発行されたBacklog APIのカーソルで続きを要求された場合は、
+	// ページの途中で検索方法が変わらないようBacklog APIで検索を続ける
+	if u.activityIndex != nil && u.activityIndex.Ready(userID) && (page.Cursor == "" || model.IsOffsetCursor(page.Cursor)) {
+		result, err := u.activityIndex.Search(userID, criteria, page)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs := make([]*BacklogItemOutput, len(result.Hits))
+		for i, hit := range result.Hits {
+			outputs[i] = newBacklogItemOutput(hit.Item, favoriteMap[hit.Item.ID])
+			outputs[i].Score = hit.Score
+			outputs[i].Highlights = hit.Highlights
+		}
+
+		return &BacklogItemPageOutput{Items: outputs, NextCursor: result.NextCursor}, nil
+	}
+
+	// ユーザー自身の権限で更新情報を検索
+	result, err := u.backlogItemService.SearchItems(token.AccessToken, criteria, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBacklogItemPageOutput(result, favoriteMap), nil
+}
+
+// newBacklogItemPageOutput はページングされた更新情報から出力用データを作成
+func newBacklogItemPageOutput(page *model.BacklogItemPage, favoriteMap map[string]bool) *BacklogItemPageOutput {
+	outputs := make([]*BacklogItemOutput, len(page.Items))
+	for i, item := range page.Items {
+		outputs[i] = newBacklogItemOutput(item, favoriteMap[item.ID])
+	}
+	return &BacklogItemPageOutput{Items: outputs, NextCursor: page.NextCursor}
+}
+
+// GetRateLimit はユーザーのアクセストークンのBacklog APIのレート制限の状態を取得（まだ呼び出していない場合はnil）
+func (u *BacklogItemUseCase) GetRateLimit(userID string) (*model.RateLimit, error) {
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.backlogItemService.GetRateLimit(token.AccessToken), nil
+}
+
+// GetProjects はユーザーが参加しているプロジェクトを取得
+func (u *BacklogItemUseCase) GetProjects(userID string) ([]*model.Project, error) {
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.backlogItemService.GetProjects(token.AccessToken)
+}
+
+// GetProjectItems はプロジェクトの更新情報を検索条件で絞り込み、カーソル位置から1ページ分をプロジェクトの情報とともに返す
+// 検索条件のプロジェクトは無視し、指定したプロジェクトの更新情報だけをBacklog APIで遡るため、
+// スペース全体の更新情報が多くても取り込み済みのインデックスより古い更新情報まで閲覧できる
+func (u *BacklogItemUseCase) GetProjectItems(userID, projectIDOrKey string, input SearchInput, page model.PageRequest) (*ProjectItemPageOutput, error) {
+	criteria, err := input.criteria()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 参加していないプロジェクトはErrProjectNotFoundになる
+	project, err := u.backlogItemService.GetProject(token.AccessToken, projectIDOrKey)
+	if err != nil {
+		return nil, err
+	}
+	criteria.ProjectIDs = []string{project.ID}
+
+	favoriteMap, err := u.favoriteMap(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.backlogItemService.SearchItems(token.AccessToken, criteria, page)
+	if err != nil {
+		return nil, err
+	}
+
+	output := newBacklogItemPageOutput(result, favoriteMap)
+	return &ProjectItemPageOutput{Project: project, Items: output.Items, NextCursor: output.NextCursor}, nil
+}
+
+// GetMyItems はユーザー自身が作成した更新情報を新しい順にカーソル位置から1ページ分取得
+func (u *BacklogItemUseCase) GetMyItems(userID string, page model.PageRequest) (*BacklogItemPageOutput, error) {
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	favoriteMap, err := u.favoriteMap(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := u.backlogItemService.GetUserItems(token.AccessToken, userID, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBacklogItemPageOutput(result, favoriteMap), nil
+}
+
+// GetPersonalFeed はユーザー自身の更新情報と、ユーザーが担当・ウォッチしている課題の更新情報を新しい順にカーソル位置から1ページ分取得
+// 担当・ウォッチしている課題はページごとに取得し直すため、担当の変更やウォッチの追加は次のページから反映される
+func (u *BacklogItemUseCase) GetPersonalFeed(userID string, page model.PageRequest) (*BacklogItemPageOutput, error) {
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	favoriteMap, err := u.favoriteMap(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	assigned, err := u.backlogItemService.GetAssignedIssues(token.AccessToken, userID)
+	if err != nil {
+		return nil, err
+	}
+	watched, err := u.backlogItemService.GetWatchedIssues(token.AccessToken, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := model.PersonalFeed{UserID: userID}
+	for _, issue := range append(assigned, watched...) {
+		if !slices.Contains(feed.IssueKeys, issue.IssueKey) {
+			feed.IssueKeys = append(feed.IssueKeys, issue.IssueKey)
+		}
+	}
+
+	result, err := u.backlogItemService.SearchPersonalItems(token.AccessToken, feed, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return newBacklogItemPageOutput(result, favoriteMap), nil
+}
+
+// favoriteMap はユーザーがお気に入りに登録している更新情報IDの集合を取得
+func (u *BacklogItemUseCase) favoriteMap(userID string) (map[string]bool, error) {
 	favorites, err := u.favoriteRepository.FindByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// お気に入りマップを作成
 	favoriteMap := make(map[string]bool)
 	for _, fav := range favorites {
 		favoriteMap[fav.ItemID] = true
 	}
+	return favoriteMap, nil
+}
+
+// favoriteFetchConcurrency はお気に入りの更新情報をBacklog APIから並行して取得する最大数
+const favoriteFetchConcurrency = 4
+
+// GetFavorites はユーザーのお気に入り情報を絞り込み条件で絞り込み、登録日時の新しい順にカーソル位置から1ページ分取得
+// 登録時に保存したスナップショットを表示するため、古くなった・削除された更新情報も一覧から消えない
+// コレクションを指定した場合は、コレクションのメンバー全員が入れたお気に入りを取得する
+func (u *BacklogItemUseCase) GetFavorites(userID string, filter FavoriteFilter, page model.PageRequest) (*BacklogItemPageOutput, error) {
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result *model.FavoritePage
+	isOwnFavorite := func(string) bool { return true }
+	if filter.isEmpty() {
+		// 絞り込まない場合はリポジトリで1ページ分だけ読み込む
+		result, err = u.favoriteRepository.FindPageByUserID(userID, page)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		favorites, err := u.filterFavorites(userID, filter)
+		if err != nil {
+			return nil, err
+		}
+		result, err = paginateFavorites(favorites, page)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.CollectionID != "" {
+			favoriteMap, err := u.favoriteMap(userID)
+			if err != nil {
+				return nil, err
+			}
+			isOwnFavorite = func(itemID string) bool { return favoriteMap[itemID] }
+		}
+	}
+	favorites := result.Favorites
+
+	// スナップショットがないお気に入りの更新情報はIDで取得してスナップショットを補完
+	// 他のメンバーのお気に入りは、ユーザー自身の権限で取得できる場合だけ表示する
+	// （参加していないプロジェクトの内容をコレクション経由で閲覧できないようにする）
+	errs := make([]error, len(favorites))
+	sem := make(chan struct{}, favoriteFetchConcurrency)
+	var wg sync.WaitGroup
+	for i, fav := range favorites {
+		if fav.Snapshot != nil && fav.UserID == userID {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, fav *model.Favorite) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if fav.UserID != userID {
+				_, errs[i] = u.resolveItem(userID, token.AccessToken, fav.ItemID)
+				return
+			}
+			errs[i] = u.fillSnapshot(userID, token.AccessToken, fav)
+		}(i, fav)
+	}
+	wg.Wait()
 
 	// 出力データを作成
-	outputs := make([]*BacklogItemOutput, len(items))
+	outputs := make([]*BacklogItemOutput, 0, len(favorites))
+	for i, fav := range favorites {
+		if errs[i] != nil {
+			// 削除された、または閲覧できなくなった更新情報は表示しない
+			if errors.Is(errs[i], model.ErrItemNotFound) {
+				continue
+			}
+			return nil, errs[i]
+		}
+		if fav.Snapshot == nil {
+			continue
+		}
 
-	for i, item := range items {
-		output := &BacklogItemOutput{
-			ID:             item.ID,
-			ProjectID:      item.ProjectID,
-			ProjectName:    item.ProjectName,
-			Type:           item.Type,
-			ContentSummary: item.ContentSummary,
-			Created:        item.Created,
-			IsFavorite:     favoriteMap[item.ID],
+		output := newBacklogItemOutput(fav.Snapshot.Item(fav.ItemID), isOwnFavorite(fav.ItemID))
+		output.CollectionID = fav.CollectionID
+		// タグとメモは登録したユーザー本人にだけ表示する
+		if fav.UserID == userID {
+			output.Tags = fav.Tags
+			output.Note = fav.Note
 		}
-		output.CreatedUser.ID = item.CreatedUser.ID
-		output.CreatedUser.Name = item.CreatedUser.Name
-		outputs[i] = output
+		outputs = append(outputs, output)
 	}
 
-	return outputs, nil
+	return &BacklogItemPageOutput{Items: outputs, NextCursor: result.NextCursor}, nil
 }
 
-// GetFavorites はユーザーのお気に入り情報を取得
-func (u *BacklogItemUseCase) GetFavorites(userID string) ([]*BacklogItemOutput, error) {
-	_, err := u.authUseCase.GetValidToken(userID)
+// filterFavorites は絞り込み条件に一致するお気に入りを登録日時の新しい順に取得
+// 複数のメンバーが同じ更新情報をコレクションに入れた場合は最も新しいものだけを残す
+func (u *BacklogItemUseCase) filterFavorites(userID string, filter FavoriteFilter) ([]*model.Favorite, error) {
+	var all []*model.Favorite
+	var err error
+	if filter.CollectionID != "" {
+		if _, _, err := collectionAccess(u.favoriteRepository, userID, filter.CollectionID); err != nil {
+			return nil, err
+		}
+		all, err = u.favoriteRepository.FindByCollectionID(filter.CollectionID)
+	} else {
+		all, err = u.favoriteRepository.FindByUserID(userID)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	favorites, err := u.favoriteRepository.FindByUserID(userID)
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].CreatedAt.After(all[j].CreatedAt)
+	})
+
+	favorites := make([]*model.Favorite, 0, len(all))
+	seen := make(map[string]bool, len(all))
+	for _, fav := range all {
+		if !filter.matches(userID, fav) || seen[fav.ItemID] {
+			continue
+		}
+		seen[fav.ItemID] = true
+		favorites = append(favorites, fav)
+	}
+	return favorites, nil
+}
+
+// paginateFavorites は並べ替え済みのお気に入りからカーソル位置の1ページ分を切り出す
+func paginateFavorites(favorites []*model.Favorite, page model.PageRequest) (*model.FavoritePage, error) {
+	offset, err := model.DecodeOffsetCursor(page.Cursor)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(favorites) == 0 {
-		return []*BacklogItemOutput{}, nil
+	result := &model.FavoritePage{Favorites: []*model.Favorite{}}
+	if offset >= len(favorites) {
+		return result, nil
 	}
 
-	favoriteIDs := make(map[string]bool)
-	for _, fav := range favorites {
-		favoriteIDs[fav.ItemID] = true
+	end := offset + page.Size()
+	if end < len(favorites) {
+		result.NextCursor = model.EncodeOffsetCursor(end)
+	} else {
+		end = len(favorites)
 	}
+	result.Favorites = favorites[offset:end]
+	return result, nil
+}
 
-	// すべてのアイテムを取得
-	allItems, err := u.backlogItemService.SearchItems("")
+// fillSnapshot はスナップショットのないお気に入りの更新情報を取得し、スナップショットとして保存
+func (u *BacklogItemUseCase) fillSnapshot(userID, accessToken string, favorite *model.Favorite) error {
+	item, err := u.resolveItem(userID, accessToken, favorite.ItemID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// お気に入りに登録されているアイテムだけをフィルタリング
-	var favoriteItems []*model.BacklogItem
-	for _, item := range allItems {
-		if favoriteIDs[item.ID] {
-			favoriteItems = append(favoriteItems, item)
+	favorite.Snapshot = model.NewFavoriteSnapshot(item, time.Now())
+	return u.favoriteRepository.Update(favorite)
+}
+
+// RefreshFavoriteSnapshots はユーザーのお気に入りのスナップショットを最新の課題の内容で更新
+// 同じ課題を参照するお気に入りが複数あっても課題の取得は1回だけ行う
+func (u *BacklogItemUseCase) RefreshFavoriteSnapshots(userID string) error {
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return err
+	}
+
+	favorites, err := u.favoriteRepository.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	issues := make(map[string]*model.Issue)
+	for _, fav := range favorites {
+		if fav.Snapshot == nil {
+			if err := u.fillSnapshot(userID, token.AccessToken, fav); err != nil && !errors.Is(err, model.ErrItemNotFound) {
+				return err
+			}
+			continue
+		}
+
+		issueKey := fav.Snapshot.IssueKey
+		if issueKey == "" {
+			continue
+		}
+
+		issue, ok := issues[issueKey]
+		if !ok {
+			issue, err = u.backlogItemService.GetIssue(token.AccessToken, issueKey)
+			// 削除された課題はスナップショットをそのまま残す
+			if errors.Is(err, model.ErrItemNotFound) {
+				issues[issueKey] = nil
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			issues[issueKey] = issue
+		}
+		if issue == nil {
+			continue
+		}
+
+		if fav.Snapshot.ApplyIssue(issue, time.Now()) {
+			if err := u.favoriteRepository.Update(fav); err != nil {
+				return err
+			}
 		}
 	}
 
-	// 出力データを作成
-	outputs := make([]*BacklogItemOutput, len(favoriteItems))
-	for i, item := range favoriteItems {
-		output := &BacklogItemOutput{
-			ID:             item.ID,
-			ProjectID:      item.ProjectID,
-			ProjectName:    item.ProjectName,
-			Type:           item.Type,
-			ContentSummary: item.ContentSummary,
-			Created:        item.Created,
-			IsFavorite:     true,
+	return nil
+}
+
+// resolveItem はIDを指定して更新情報を取得。取り込み済みのインデックスにあればBacklog APIを呼び出さない
+func (u *BacklogItemUseCase) resolveItem(userID, accessToken, itemID string) (*model.BacklogItem, error) {
+	if u.activityIndex != nil {
+		if item, ok := u.activityIndex.Get(userID, itemID); ok {
+			return item, nil
 		}
-		output.CreatedUser.ID = item.CreatedUser.ID
-		output.CreatedUser.Name = item.CreatedUser.Name
-		outputs[i] = output
 	}
 
-	return outputs, nil
+	return u.backlogItemService.GetItem(accessToken, itemID)
 }
 
 // AddFavorite はお気に入りを追加
+// 登録時点の更新情報の内容をスナップショットとして保存する
+// 既に登録済みの場合はErrFavoriteAlreadyExists、Backlogのトークンが無効な場合はErrUnauthorizedを含むエラーを返す
 func (u *BacklogItemUseCase) AddFavorite(userID, itemID string) error {
-	// 既に存在するかチェック
+	// 登録済みの場合にBacklog APIを呼び出さないための事前確認
+	// 同時に追加された場合も、保存時にリポジトリが1件だけを登録してErrFavoriteAlreadyExistsを返す
 	exists, err := u.favoriteRepository.Exists(userID, itemID)
 	if err != nil {
 		return err
 	}
 	if exists {
-		return errors.New("favorite already exists")
+		return ErrFavoriteAlreadyExists
+	}
+
+	// ユーザー自身の権限でお気に入りに登録する更新情報を取得
+	token, err := u.authUseCase.GetValidToken(userID)
+	if err != nil {
+		return err
+	}
+	item, err := u.resolveItem(userID, token.AccessToken, itemID)
+	if err != nil {
+		return err
 	}
 
 	// 新しいお気に入りを作成
+	now := time.Now()
 	favorite := &model.Favorite{
 		ID:        uuid.New().String(),
 		UserID:    userID,
 		ItemID:    itemID,
-		CreatedAt: time.Now(),
+		CreatedAt: now,
+		Snapshot:  model.NewFavoriteSnapshot(item, now),
 	}
 
 	// お気に入りを保存
 	return u.favoriteRepository.Save(favorite)
 }
 
+// AnnotateFavorite はお気に入りのコレクション・タグ・メモを更新
+// お気に入りを新たに入れるコレクションでは、ユーザーがオーナーまたは編集者である必要がある
+func (u *BacklogItemUseCase) AnnotateFavorite(userID, itemID string, input FavoriteAnnotationInput) error {
+	input, err := input.validate()
+	if err != nil {
+		return err
+	}
+
+	favorites, err := u.favoriteRepository.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(favorites, func(fav *model.Favorite) bool { return fav.ItemID == itemID })
+	if index < 0 {
+		return model.ErrFavoriteNotFound
+	}
+
+	// 既に入っているコレクションから外されていても、タグとメモは変更できる
+	if input.CollectionID != "" && input.CollectionID != favorites[index].CollectionID {
+		_, role, err := collectionAccess(u.favoriteRepository, userID, input.CollectionID)
+		if err != nil {
+			return err
+		}
+		if !role.CanEdit() {
+			return model.ErrCollectionForbidden
+		}
+	}
+
+	return u.favoriteRepository.UpdateAnnotations(&model.Favorite{
+		UserID:       userID,
+		ItemID:       itemID,
+		CollectionID: input.CollectionID,
+		Tags:         input.Tags,
+		Note:         input.Note,
+	})
+}
+
 // RemoveFavorite はお気に入りを削除
+// 登録されていない場合はErrFavoriteNotFoundを返す
 func (u *BacklogItemUseCase) RemoveFavorite(userID, itemID string) error {
 	return u.favoriteRepository.Delete(userID, itemID)
 }
@@ -1,16 +1,30 @@
 package usecase
 
 import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
 	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/search"
 )
 
 // MockBacklogItemService はBacklogItemServiceのモック実装
 type MockBacklogItemService struct {
-	items []*model.BacklogItem
+	items           []*model.BacklogItem
+	issues          map[string]*model.Issue
+	assignedIssues  []*model.Issue
+	watchedIssues   []*model.Issue
+	lastAccessToken string
+	lastCriteria    model.SearchCriteria
+	searchCalls     int
+	getItemCalls    int
+	// inaccessible はアクセストークンごとに閲覧できない更新情報ID
+	inaccessible map[string][]string
 }
 
 func NewMockBacklogItemService() *MockBacklogItemService {
@@ -50,9 +64,14 @@ func NewMockBacklogItemService() *MockBacklogItemService {
 	}
 }
 
-func (m *MockBacklogItemService) SearchItems(keyword string) ([]*model.BacklogItem, error) {
+func (m *MockBacklogItemService) SearchItems(accessToken string, criteria model.SearchCriteria, page model.PageRequest) (*model.BacklogItemPage, error) {
+	m.lastAccessToken = accessToken
+	m.lastCriteria = criteria
+	m.searchCalls++
+
+	keyword := criteria.Keyword
 	if keyword == "" {
-		return m.items, nil
+		return &model.BacklogItemPage{Items: m.items}, nil
 	}
 
 	var result []*model.BacklogItem
@@ -62,21 +81,72 @@ func (m *MockBacklogItemService) SearchItems(keyword string) ([]*model.BacklogIt
 			result = append(result, item)
 		}
 	}
-	return result, nil
+	return &model.BacklogItemPage{Items: result}, nil
+}
+
+func (m *MockBacklogItemService) GetItem(accessToken string, itemID string) (*model.BacklogItem, error) {
+	m.getItemCalls++
+	if slices.Contains(m.inaccessible[accessToken], itemID) {
+		return nil, model.ErrItemNotFound
+	}
+	for _, item := range m.items {
+		if item.ID == itemID {
+			return item, nil
+		}
+	}
+	return nil, model.ErrItemNotFound
 }
 
-func (m *MockBacklogItemService) GetFavorites(userID string) ([]*model.BacklogItem, error) {
-	return m.items[:1], nil
+func (m *MockBacklogItemService) GetIssue(accessToken string, issueKey string) (*model.Issue, error) {
+	if issue, ok := m.issues[issueKey]; ok {
+		return issue, nil
+	}
+	return nil, model.ErrItemNotFound
 }
 
-func (m *MockBacklogItemService) AddFavorite(userID string, itemID string) error {
-	return nil
+func (m *MockBacklogItemService) GetUserItems(accessToken string, userID string, page model.PageRequest) (*model.BacklogItemPage, error) {
+	var result []*model.BacklogItem
+	for _, item := range m.items {
+		if item.CreatedUser.ID == userID {
+			result = append(result, item)
+		}
+	}
+	return &model.BacklogItemPage{Items: result}, nil
 }
 
-func (m *MockBacklogItemService) RemoveFavorite(userID string, itemID string) error {
+func (m *MockBacklogItemService) SearchPersonalItems(accessToken string, feed model.PersonalFeed, page model.PageRequest) (*model.BacklogItemPage, error) {
+	var result []*model.BacklogItem
+	for _, item := range m.items {
+		if feed.Matches(item) {
+			result = append(result, item)
+		}
+	}
+	return &model.BacklogItemPage{Items: result}, nil
+}
+
+func (m *MockBacklogItemService) GetAssignedIssues(accessToken string, userID string) ([]*model.Issue, error) {
+	return m.assignedIssues, nil
+}
+
+func (m *MockBacklogItemService) GetWatchedIssues(accessToken string, userID string) ([]*model.Issue, error) {
+	return m.watchedIssues, nil
+}
+
+func (m *MockBacklogItemService) GetRateLimit(accessToken string) *model.RateLimit {
 	return nil
 }
 
+func (m *MockBacklogItemService) GetProjects(accessToken string) ([]*model.Project, error) {
+	return []*model.Project{{ID: "1", ProjectKey: "PROJA", Name: "プロジェクトA"}}, nil
+}
+
+func (m *MockBacklogItemService) GetProject(accessToken string, projectIDOrKey string) (*model.Project, error) {
+	if projectIDOrKey != "1" && projectIDOrKey != "PROJA" {
+		return nil, model.ErrProjectNotFound
+	}
+	return &model.Project{ID: "1", ProjectKey: "PROJA", Name: "プロジェクトA"}, nil
+}
+
 // MockAuthRepository はAuthRepositoryのモック実装
 type MockAuthRepository struct{}
 
@@ -120,15 +190,47 @@ func (m *MockAuthRepository) GetToken(userID string) (*model.AuthToken, error) {
 	}, nil
 }
 
+func (m *MockAuthRepository) SaveSession(session *model.Session) error {
+	return nil
+}
+
+func (m *MockAuthRepository) GetSession(sessionID string) (*model.Session, error) {
+	return nil, errors.New("session not found")
+}
+
+func (m *MockAuthRepository) DeleteSession(sessionID string) error {
+	return nil
+}
+
+func (m *MockAuthRepository) DeleteSessionsByUserID(userID string) error {
+	return nil
+}
+
+func (m *MockAuthRepository) SaveExchangeCode(code *model.ExchangeCode) error {
+	return nil
+}
+
+func (m *MockAuthRepository) ConsumeExchangeCode(code string) (*model.ExchangeCode, error) {
+	return nil, errors.New("exchange code not found")
+}
+
+func (m *MockAuthRepository) SaveOAuthState(state *model.OAuthState) error {
+	return nil
+}
+
+func (m *MockAuthRepository) ConsumeOAuthState(state string) (*model.OAuthState, error) {
+	return nil, errors.New("oauth state not found")
+}
+
 // MockAuthService はAuthServiceのモック実装
 type MockAuthService struct{}
 
-func (m *MockAuthService) GetAuthorizationURL() string {
-	return "https://test.com/auth"
+func (m *MockAuthService) GetAuthorizationURL(state string, codeVerifier string) string {
+	return "https://test.com/auth?state=" + state
 }
 
 // 認証認可用ダミートークン取得
-func (m *MockAuthService) ExchangeCodeForToken(code string) (*model.AuthToken, error) {
+func (m *MockAuthService) ExchangeCodeForToken(code string, codeVerifier string) (*model.AuthToken, error) {
 	return &model.AuthToken{
 		AccessToken:  "test-token",
 		TokenType:    "Bearer",
@@ -173,7 +275,7 @@ func TestBacklogItemUseCase_SearchItems(t *testing.T) {
 	mockAuthUseCase := createTestAuthUseCase()
 
 	// テスト対象のユースケースを初期化
-	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, mockAuthUseCase)
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, mockAuthUseCase, nil)
 
 	// テスト実行
 	testCases := []struct {
@@ -204,18 +306,146 @@ func TestBacklogItemUseCase_SearchItems(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			results, err := backlogUseCase.SearchItems(tc.userID, tc.keyword)
+			results, err := backlogUseCase.SearchItems(tc.userID, SearchInput{Keyword: tc.keyword}, model.PageRequest{})
 			if err != nil {
 				t.Fatalf("Failed to search items: %v", err)
 			}
 
-			if len(results) != tc.expected {
-				t.Errorf("Expected %d items, got %d", tc.expected, len(results))
+			if len(results.Items) != tc.expected {
+				t.Errorf("Expected %d items, got %d", tc.expected, len(results.Items))
 			}
 		})
 	}
 }
 
+// 呼び出し元ユーザーのトークンでBacklogを検索することを確認する
+func TestBacklogItemUseCase_SearchItemsUsesCallerToken(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user1", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user2", ExpiresAt: time.Now().Add(time.Hour), UserID: "user2"})
+
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, memory.NewFavoriteRepository(), authUseCase, nil)
+
+	for _, userID := range []string{"user1", "user2"} {
+		if _, err := backlogUseCase.SearchItems(userID, SearchInput{}, model.PageRequest{}); err != nil {
+			t.Fatalf("Failed to search items: %v", err)
+		}
+		if mockBacklogService.lastAccessToken != "token-"+userID {
+			t.Errorf("Expected token-%s, got %s", userID, mockBacklogService.lastAccessToken)
+		}
+	}
+}
+
+// プロジェクトキーで指定したプロジェクトの更新情報をプロジェクトIDで絞り込んで取得することを確認する
+func TestBacklogItemUseCase_GetProjectItems(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	favoriteRepo := memory.NewFavoriteRepository()
+	favoriteRepo.Save(&model.Favorite{ID: "f1", UserID: "user1", ItemID: "2", CreatedAt: time.Now()})
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, createTestAuthUseCase(), nil)
+
+	// 検索条件のプロジェクトは指定したプロジェクトで置き換える
+	result, err := backlogUseCase.GetProjectItems("user1", "PROJA", SearchInput{ProjectIDs: []string{"2"}, TypeIDs: []int{1}}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get project items: %v", err)
+	}
+
+	if result.Project.ID != "1" || result.Project.Name != "プロジェクトA" {
+		t.Errorf("Unexpected project: %+v", result.Project)
+	}
+	criteria := mockBacklogService.lastCriteria
+	if len(criteria.ProjectIDs) != 1 || criteria.ProjectIDs[0] != "1" || len(criteria.TypeIDs) != 1 {
+		t.Errorf("Unexpected criteria: %+v", criteria)
+	}
+	if len(result.Items) != 2 || result.Items[0].IsFavorite || !result.Items[1].IsFavorite {
+		t.Errorf("Unexpected items: %+v", result.Items)
+	}
+
+	if _, err := backlogUseCase.GetProjectItems("user1", "UNKNOWN", SearchInput{}, model.PageRequest{}); !errors.Is(err, model.ErrProjectNotFound) {
+		t.Errorf("Expected ErrProjectNotFound, got %v", err)
+	}
+}
+
+// インデックスの準備前に発行されたBacklog APIのカーソルでは、インデックスの準備後もBacklog APIで続きを検索することを確認する
+func TestBacklogItemUseCase_SearchItemsKeepsCursorSource(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-user1", ExpiresAt: time.Now().Add(time.Hour), UserID: "user1"})
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+
+	index := search.NewActivityIndex(0)
+	if err := index.Add("user1", mockBacklogService.items, model.IngestState{}); err != nil {
+		t.Fatalf("Failed to add items to index: %v", err)
+	}
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, memory.NewFavoriteRepository(), authUseCase, index)
+
+	testCases := []struct {
+		name          string
+		cursor        string
+		expectedCalls int
+	}{
+		{"最初のページはインデックスで検索する", "", 0},
+		{"インデックスのカーソルはインデックスで検索する", model.EncodeOffsetCursor(1), 0},
+		{"Backlog APIのカーソルはBacklog APIで検索する", model.EncodeActivityCursor(2), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockBacklogService.searchCalls = 0
+			if _, err := backlogUseCase.SearchItems("user1", SearchInput{}, model.PageRequest{Cursor: tc.cursor}); err != nil {
+				t.Fatalf("Failed to search items: %v", err)
+			}
+			if mockBacklogService.searchCalls != tc.expectedCalls {
+				t.Errorf("Expected %d Backlog API calls, got %d", tc.expectedCalls, mockBacklogService.searchCalls)
+			}
+		})
+	}
+}
+
+// 自分が作成した更新情報と、担当・ウォッチしている課題の更新情報をまとめて取得することを確認する
+func TestBacklogItemUseCase_GetPersonalFeed(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	mockBacklogService.items = append(mockBacklogService.items,
+		&model.BacklogItem{ID: "3", IssueKey: "PROJ-3", CreatedUser: model.User{ID: "2"}},
+		&model.BacklogItem{ID: "4", IssueKey: "PROJ-4", CreatedUser: model.User{ID: "2"}},
+		&model.BacklogItem{ID: "5", IssueKey: "PROJ-5", CreatedUser: model.User{ID: "3"}},
+	)
+	mockBacklogService.assignedIssues = []*model.Issue{{IssueKey: "PROJ-3"}}
+	mockBacklogService.watchedIssues = []*model.Issue{{IssueKey: "PROJ-5"}}
+
+	authRepo := memory.NewAuthRepository()
+	authRepo.SaveToken(&model.AuthToken{AccessToken: "token-2", ExpiresAt: time.Now().Add(time.Hour), UserID: "2"})
+	authUseCase := NewAuthUseCase(&MockAuthService{}, authRepo)
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, memory.NewFavoriteRepository(), authUseCase, nil)
+
+	mine, err := backlogUseCase.GetMyItems("2", model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get my items: %v", err)
+	}
+	if ids := itemIDs(mine.Items); !slices.Equal(ids, []string{"2", "3", "4"}) {
+		t.Errorf("Unexpected my items: %v", ids)
+	}
+
+	// 他のユーザーが作成した更新情報も、ウォッチしている課題のものは含まれる
+	feed, err := backlogUseCase.GetPersonalFeed("2", model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get personal feed: %v", err)
+	}
+	if ids := itemIDs(feed.Items); !slices.Equal(ids, []string{"2", "3", "4", "5"}) {
+		t.Errorf("Unexpected personal feed: %v", ids)
+	}
+}
+
+// itemIDs は出力データの更新情報IDを並び順のまま返す
+func itemIDs(items []*BacklogItemOutput) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
 func TestBacklogItemUseCase_AddFavorite(t *testing.T) {
 	// テスト用のリポジトリとサービスを初期化
 	mockBacklogService := NewMockBacklogItemService()
@@ -223,7 +453,7 @@ func TestBacklogItemUseCase_AddFavorite(t *testing.T) {
 	authUseCase := createTestAuthUseCase()
 
 	// テスト対象のユースケースを初期化
-	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, authUseCase)
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, authUseCase, nil)
 
 	// テスト実行
 	userID := "user1"
@@ -237,8 +467,8 @@ func TestBacklogItemUseCase_AddFavorite(t *testing.T) {
 
 	// 同じアイテムを再度追加すると重複エラーが発生するはず
 	err = backlogUseCase.AddFavorite(userID, itemID)
-	if err == nil {
-		t.Error("Expected duplicate error, but got nil")
+	if !errors.Is(err, ErrFavoriteAlreadyExists) {
+		t.Errorf("Expected ErrFavoriteAlreadyExists, got %v", err)
 	}
 
 	// お気に入り削除
@@ -247,9 +477,128 @@ func TestBacklogItemUseCase_AddFavorite(t *testing.T) {
 		t.Fatalf("Failed to remove favorite: %v", err)
 	}
 
+	// 登録されていないお気に入りの削除は見つからないエラーになるはず
+	err = backlogUseCase.RemoveFavorite(userID, itemID)
+	if !errors.Is(err, ErrFavoriteNotFound) {
+		t.Errorf("Expected ErrFavoriteNotFound, got %v", err)
+	}
+
 	// 再度お気に入り追加ができることを確認
 	err = backlogUseCase.AddFavorite(userID, itemID)
 	if err != nil {
 		t.Fatalf("Failed to add favorite after removing: %v", err)
 	}
 }
+
+// お気に入りの更新情報を最新の一覧から探さずにIDで解決することを確認する
+func TestBacklogItemUseCase_GetFavoritesResolvesByID(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	favoriteRepo := memory.NewFavoriteRepository()
+	authUseCase := createTestAuthUseCase()
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, authUseCase, nil)
+
+	now := time.Now()
+	favoriteRepo.Save(&model.Favorite{ID: "f1", UserID: "user1", ItemID: "1", CreatedAt: now.Add(-time.Hour)})
+	favoriteRepo.Save(&model.Favorite{ID: "f2", UserID: "user1", ItemID: "2", CreatedAt: now})
+	favoriteRepo.Save(&model.Favorite{ID: "f3", UserID: "user1", ItemID: "deleted", CreatedAt: now})
+
+	page, err := backlogUseCase.GetFavorites("user1", FavoriteFilter{}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get favorites: %v", err)
+	}
+	favorites := page.Items
+
+	// 削除された更新情報は除外され、登録日時の新しい順に並ぶ
+	if len(favorites) != 2 || favorites[0].ID != "2" || favorites[1].ID != "1" {
+		t.Fatalf("Unexpected favorites: %+v", favorites)
+	}
+	if !favorites[0].IsFavorite {
+		t.Error("Expected item to be favorite")
+	}
+	if mockBacklogService.searchCalls != 0 {
+		t.Errorf("Expected no search calls, got %d", mockBacklogService.searchCalls)
+	}
+}
+
+// お気に入りを登録日時の新しい順にページングして取得できることを確認する
+func TestBacklogItemUseCase_GetFavoritesPaging(t *testing.T) {
+	favoriteRepo := memory.NewFavoriteRepository()
+	backlogUseCase := NewBacklogItemUseCase(NewMockBacklogItemService(), favoriteRepo, createTestAuthUseCase(), nil)
+
+	now := time.Now()
+	item := &model.BacklogItem{ProjectName: "プロジェクトA", Type: "課題の追加"}
+	for i := 1; i <= 5; i++ {
+		itemID := strconv.Itoa(i)
+		favoriteRepo.Save(&model.Favorite{ID: itemID, UserID: "user1", ItemID: itemID, CreatedAt: now.Add(time.Duration(i) * time.Minute), Snapshot: model.NewFavoriteSnapshot(item, now), Tags: []string{"チーム"}})
+	}
+
+	// 絞り込まない場合はリポジトリで、絞り込む場合はユースケースでページングする
+	for _, filter := range []FavoriteFilter{{}, {Tag: "チーム"}} {
+		var ids []string
+		page := model.PageRequest{Limit: 2}
+		for {
+			result, err := backlogUseCase.GetFavorites("user1", filter, page)
+			if err != nil {
+				t.Fatalf("Failed to get favorites: %v", err)
+			}
+			for _, output := range result.Items {
+				ids = append(ids, output.ID)
+			}
+			if result.NextCursor == "" {
+				break
+			}
+			page.Cursor = result.NextCursor
+		}
+
+		if strings.Join(ids, ",") != "5,4,3,2,1" {
+			t.Errorf("Expected newest-first pages, got %v", ids)
+		}
+	}
+
+	if _, err := backlogUseCase.GetFavorites("user1", FavoriteFilter{}, model.PageRequest{Cursor: "invalid"}); !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+// お気に入り登録時のスナップショットを表示し、課題の変更を反映することを確認する
+func TestBacklogItemUseCase_FavoriteSnapshot(t *testing.T) {
+	mockBacklogService := NewMockBacklogItemService()
+	mockBacklogService.items[0].IssueKey = "PROJ-1"
+	favoriteRepo := memory.NewFavoriteRepository()
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, createTestAuthUseCase(), nil)
+
+	if err := backlogUseCase.AddFavorite("user1", "1"); err != nil {
+		t.Fatalf("Failed to add favorite: %v", err)
+	}
+	if err := backlogUseCase.AddFavorite("user1", "deleted"); err != model.ErrItemNotFound {
+		t.Errorf("Expected ErrItemNotFound, got %v", err)
+	}
+
+	// 元の更新情報が取得できなくなってもスナップショットを表示する
+	mockBacklogService.items = nil
+	mockBacklogService.getItemCalls = 0
+	page, err := backlogUseCase.GetFavorites("user1", FavoriteFilter{}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get favorites: %v", err)
+	}
+	favorites := page.Items
+	if len(favorites) != 1 || favorites[0].ContentSummary != "ログイン機能の実装" {
+		t.Fatalf("Unexpected favorites: %+v", favorites)
+	}
+	if mockBacklogService.getItemCalls != 0 {
+		t.Errorf("Expected no GetItem calls, got %d", mockBacklogService.getItemCalls)
+	}
+
+	// 課題の件名が変わったらスナップショットを更新する
+	mockBacklogService.issues = map[string]*model.Issue{
+		"PROJ-1": {IssueKey: "PROJ-1", Summary: "ログイン機能の実装（SSO対応）", Status: "処理中"},
+	}
+	if err := backlogUseCase.RefreshFavoriteSnapshots("user1"); err != nil {
+		t.Fatalf("Failed to refresh favorites: %v", err)
+	}
+
+	stored, _ := favoriteRepo.FindByUserID("user1")
+	if stored[0].Snapshot.ContentSummary != "ログイン機能の実装（SSO対応）" || stored[0].Snapshot.IssueStatus != "処理中" {
+		t.Errorf("Expected snapshot to be refreshed, got %+v", stored[0].Snapshot)
+	}
+}
@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// ErrInvalidFavoriteInput はコレクションやお気に入りのタグ・メモの入力が不正なエラー
+var ErrInvalidFavoriteInput = errors.New("invalid favorite input")
+
+const (
+	// maxCollectionNameLength はコレクション名の最大文字数
+	maxCollectionNameLength = 100
+	// maxCollectionDescriptionLength はコレクションの説明の最大文字数
+	maxCollectionDescriptionLength = 1000
+	// maxFavoriteTags はお気に入り1件に付けられるタグの最大数
+	maxFavoriteTags = 20
+	// maxTagLength はタグ1つの最大文字数
+	maxTagLength = 50
+	// maxNoteLength はお気に入りのメモの最大文字数
+	maxNoteLength = 2000
+)
+
+// CollectionUseCase はお気に入りコレクションに関するユースケース
+type CollectionUseCase struct {
+	favoriteRepository model.FavoriteRepository
+}
+
+// CollectionInput はコレクションの作成・更新の入力データ
+type CollectionInput struct {
+	Name        string
+	Description string
+}
+
+// validate は入力データを検証し、前後の空白を取り除いた入力データを返す
+func (in CollectionInput) validate() (CollectionInput, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+
+	if in.Name == "" {
+		return in, fmt.Errorf("%w: collection name is required", ErrInvalidFavoriteInput)
+	}
+	if utf8.RuneCountInString(in.Name) > maxCollectionNameLength {
+		return in, fmt.Errorf("%w: collection name must be at most %d characters", ErrInvalidFavoriteInput, maxCollectionNameLength)
+	}
+	if utf8.RuneCountInString(in.Description) > maxCollectionDescriptionLength {
+		return in, fmt.Errorf("%w: collection description must be at most %d characters", ErrInvalidFavoriteInput, maxCollectionDescriptionLength)
+	}
+	return in, nil
+}
+
+// CollectionOutput はコレクションの出力用データ
+type CollectionOutput struct {
+	ID          string               `json:"id"`
+	OwnerID     string               `json:"ownerId"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Role        model.CollectionRole `json:"role"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+}
+
+// newCollectionOutput はドメインモデルと呼び出し元ユーザーの権限から出力用データを作成
+func newCollectionOutput(collection *model.Collection, role model.CollectionRole) *CollectionOutput {
+	return &CollectionOutput{
+		ID:          collection.ID,
+		OwnerID:     collection.UserID,
+		Name:        collection.Name,
+		Description: collection.Description,
+		Role:        role,
+		CreatedAt:   collection.CreatedAt,
+		UpdatedAt:   collection.UpdatedAt,
+	}
+}
+
+// CollectionMemberInput は共有コレクションへのメンバー招待の入力データ
+// UserIDは招待するユーザーのBacklogユーザーID
+type CollectionMemberInput struct {
+	UserID string
+	Role   model.CollectionRole
+}
+
+// validate は入力データを検証する。オーナー権限は招待で付与できない
+func (in CollectionMemberInput) validate() (CollectionMemberInput, error) {
+	in.UserID = strings.TrimSpace(in.UserID)
+	if in.UserID == "" {
+		return in, fmt.Errorf("%w: member user ID is required", ErrInvalidFavoriteInput)
+	}
+	if in.Role != model.CollectionRoleEditor && in.Role != model.CollectionRoleViewer {
+		return in, fmt.Errorf("%w: role must be %s or %s", ErrInvalidFavoriteInput, model.CollectionRoleEditor, model.CollectionRoleViewer)
+	}
+	return in, nil
+}
+
+// NewCollectionUseCase はCollectionUseCaseのインスタンスを生成
+func NewCollectionUseCase(favoriteRepository model.FavoriteRepository) *CollectionUseCase {
+	return &CollectionUseCase{
+		favoriteRepository: favoriteRepository,
+	}
+}
+
+// ListCollections はユーザーが作成したコレクションと共有されているコレクションを名前順に取得
+func (u *CollectionUseCase) ListCollections(userID string) ([]*CollectionOutput, error) {
+	owned, err := u.favoriteRepository.FindCollectionsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := u.favoriteRepository.FindCollectionsByMemberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]*CollectionOutput, 0, len(owned)+len(shared))
+	for _, collection := range owned {
+		outputs = append(outputs, newCollectionOutput(collection, model.CollectionRoleOwner))
+	}
+	for _, collection := range shared {
+		_, role, err := collectionAccess(u.favoriteRepository, userID, collection.ID)
+		if errors.Is(err, model.ErrCollectionNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, newCollectionOutput(collection, role))
+	}
+
+	sort.SliceStable(outputs, func(i, j int) bool {
+		return outputs[i].Name < outputs[j].Name
+	})
+	return outputs, nil
+}
+
+// GetCollection はユーザーが閲覧できるコレクションを取得
+// メンバーでないユーザーにはコレクションが存在しないものとして扱う
+func (u *CollectionUseCase) GetCollection(userID, collectionID string) (*CollectionOutput, error) {
+	collection, role, err := collectionAccess(u.favoriteRepository, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	return newCollectionOutput(collection, role), nil
+}
+
+// CreateCollection はコレクションを作成
+func (u *CollectionUseCase) CreateCollection(userID string, input CollectionInput) (*CollectionOutput, error) {
+	input, err := input.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	collection := &model.Collection{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := u.favoriteRepository.SaveCollection(collection); err != nil {
+		return nil, err
+	}
+	return newCollectionOutput(collection, model.CollectionRoleOwner), nil
+}
+
+// UpdateCollection はコレクションの名前と説明を更新。オーナーのみ実行できる
+func (u *CollectionUseCase) UpdateCollection(userID, collectionID string, input CollectionInput) (*CollectionOutput, error) {
+	input, err := input.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := u.manageableCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Name = input.Name
+	collection.Description = input.Description
+	collection.UpdatedAt = time.Now()
+
+	if err := u.favoriteRepository.SaveCollection(collection); err != nil {
+		return nil, err
+	}
+	return newCollectionOutput(collection, model.CollectionRoleOwner), nil
+}
+
+// DeleteCollection はコレクションを削除。オーナーのみ実行できる
+// コレクションに入っていたメンバー全員のお気に入りは削除せず未分類に戻す
+func (u *CollectionUseCase) DeleteCollection(userID, collectionID string) error {
+	if _, err := u.manageableCollection(userID, collectionID); err != nil {
+		return err
+	}
+
+	if err := detachFavorites(u.favoriteRepository, collectionID, ""); err != nil {
+		return err
+	}
+
+	members, err := u.favoriteRepository.FindCollectionMembers(collectionID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if err := u.favoriteRepository.DeleteCollectionMember(collectionID, member.UserID); err != nil {
+			return err
+		}
+	}
+
+	return u.favoriteRepository.DeleteCollection(collectionID)
+}
+
+// RemoveCollectionItem は更新情報をコレクションから除外する。オーナーと編集者が実行できる
+// 他のメンバーが入れたお気に入りも未分類に戻す
+func (u *CollectionUseCase) RemoveCollectionItem(userID, collectionID, itemID string) error {
+	_, role, err := collectionAccess(u.favoriteRepository, userID, collectionID)
+	if err != nil {
+		return err
+	}
+	if !role.CanEdit() {
+		return model.ErrCollectionForbidden
+	}
+
+	return detachFavorites(u.favoriteRepository, collectionID, itemID)
+}
+
+// ListMembers はコレクションのオーナーとメンバーを取得。メンバー全員が実行できる
+func (u *CollectionUseCase) ListMembers(userID, collectionID string) ([]*model.CollectionMember, error) {
+	collection, _, err := collectionAccess(u.favoriteRepository, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := u.favoriteRepository.FindCollectionMembers(collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].AddedAt.Before(members[j].AddedAt)
+	})
+
+	owner := &model.CollectionMember{
+		CollectionID: collection.ID,
+		UserID:       collection.UserID,
+		Role:         model.CollectionRoleOwner,
+		AddedAt:      collection.CreatedAt,
+	}
+	return append([]*model.CollectionMember{owner}, members...), nil
+}
+
+// InviteMember はコレクションにメンバーを招待する。招待済みのメンバーの場合は権限を変更する
+// オーナーのみ実行できる
+func (u *CollectionUseCase) InviteMember(userID, collectionID string, input CollectionMemberInput) (*model.CollectionMember, error) {
+	input, err := input.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := u.manageableCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if input.UserID == collection.UserID {
+		return nil, fmt.Errorf("%w: owner cannot be invited", ErrInvalidFavoriteInput)
+	}
+
+	member := &model.CollectionMember{
+		CollectionID: collectionID,
+		UserID:       input.UserID,
+		Role:         input.Role,
+		AddedAt:      time.Now(),
+	}
+	if err := u.favoriteRepository.SaveCollectionMember(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember はコレクションからメンバーを削除する
+// オーナーは任意のメンバーを、メンバーは自分自身を削除（退出）できる
+// 削除されたメンバーがコレクションに入れたお気に入りはコレクションに残す
+func (u *CollectionUseCase) RemoveMember(userID, collectionID, memberID string) error {
+	collection, role, err := collectionAccess(u.favoriteRepository, userID, collectionID)
+	if err != nil {
+		return err
+	}
+	if memberID == collection.UserID {
+		return fmt.Errorf("%w: owner cannot be removed", ErrInvalidFavoriteInput)
+	}
+	if !role.CanManage() && memberID != userID {
+		return model.ErrCollectionForbidden
+	}
+
+	return u.favoriteRepository.DeleteCollectionMember(collectionID, memberID)
+}
+
+// manageableCollection はユーザーがオーナーであるコレクションを取得
+func (u *CollectionUseCase) manageableCollection(userID, collectionID string) (*model.Collection, error) {
+	collection, role, err := collectionAccess(u.favoriteRepository, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	if !role.CanManage() {
+		return nil, model.ErrCollectionForbidden
+	}
+	return collection, nil
+}
+
+// collectionAccess はコレクションとユーザーの権限を取得
+// オーナーでもメンバーでもないユーザーにはコレクションの存在を明かさずErrCollectionNotFoundを返す
+func collectionAccess(repository model.FavoriteRepository, userID, collectionID string) (*model.Collection, model.CollectionRole, error) {
+	collection, err := repository.FindCollection(collectionID)
+	if err != nil {
+		return nil, "", err
+	}
+	if collection.UserID == userID {
+		return collection, model.CollectionRoleOwner, nil
+	}
+
+	members, err := repository.FindCollectionMembers(collectionID)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, member := range members {
+		if member.UserID == userID {
+			return collection, member.Role, nil
+		}
+	}
+
+	return nil, "", model.ErrCollectionNotFound
+}
+
+// detachFavorites はコレクションに入っているお気に入りを未分類に戻す。itemIDが空の場合はすべてのお気に入りが対象
+func detachFavorites(repository model.FavoriteRepository, collectionID, itemID string) error {
+	favorites, err := repository.FindByCollectionID(collectionID)
+	if err != nil {
+		return err
+	}
+
+	for _, fav := range favorites {
+		if itemID != "" && fav.ItemID != itemID {
+			continue
+		}
+
+		fav.CollectionID = ""
+		err := repository.UpdateAnnotations(fav)
+		if err != nil && !errors.Is(err, model.ErrFavoriteNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// FavoriteAnnotationInput はお気に入りのコレクション・タグ・メモの入力データ
+// CollectionIDを空にすると未分類に戻す
+type FavoriteAnnotationInput struct {
+	CollectionID string
+	Tags         []string
+	Note         string
+}
+
+// validate は入力データを検証し、タグの前後の空白と重複を取り除いた入力データを返す
+func (in FavoriteAnnotationInput) validate() (FavoriteAnnotationInput, error) {
+	tags := make([]string, 0, len(in.Tags))
+	for _, tag := range in.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(tags, tag) {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return in, fmt.Errorf("%w: tag must be at most %d characters", ErrInvalidFavoriteInput, maxTagLength)
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > maxFavoriteTags {
+		return in, fmt.Errorf("%w: at most %d tags are allowed", ErrInvalidFavoriteInput, maxFavoriteTags)
+	}
+	in.Tags = tags
+
+	if utf8.RuneCountInString(in.Note) > maxNoteLength {
+		return in, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidFavoriteInput, maxNoteLength)
+	}
+	return in, nil
+}
+
+// FavoriteFilter はお気に入り一覧の絞り込み条件
+// 空の条件は絞り込みに使わない
+type FavoriteFilter struct {
+	CollectionID string
+	Tag          string
+}
+
+// isEmpty は絞り込み条件が指定されていないか判定
+func (f FavoriteFilter) isEmpty() bool {
+	return f.CollectionID == "" && f.Tag == ""
+}
+
+// matches はユーザーから見てお気に入りが絞り込み条件に一致するか判定
+// タグは登録したユーザー本人にしか見えないため、他のメンバーのお気に入りはタグで絞り込むと一致しない
+func (f FavoriteFilter) matches(userID string, favorite *model.Favorite) bool {
+	if f.CollectionID != "" && favorite.CollectionID != f.CollectionID {
+		return false
+	}
+	if f.Tag != "" && (favorite.UserID != userID || !slices.Contains(favorite.Tags, f.Tag)) {
+		return false
+	}
+	return true
+}
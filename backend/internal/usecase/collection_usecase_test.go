@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+)
+
+func TestCollectionUseCase_CRUD(t *testing.T) {
+	collectionUseCase := NewCollectionUseCase(memory.NewFavoriteRepository())
+
+	if _, err := collectionUseCase.CreateCollection("user1", CollectionInput{Name: "  "}); !errors.Is(err, ErrInvalidFavoriteInput) {
+		t.Errorf("Expected ErrInvalidFavoriteInput, got %v", err)
+	}
+
+	created, err := collectionUseCase.CreateCollection("user1", CollectionInput{Name: " リリース ", Description: "次回リリースの課題"})
+	if err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	if created.Name != "リリース" {
+		t.Errorf("Expected trimmed name, got %q", created.Name)
+	}
+
+	updated, err := collectionUseCase.UpdateCollection("user1", created.ID, CollectionInput{Name: "v2リリース"})
+	if err != nil {
+		t.Fatalf("Failed to update collection: %v", err)
+	}
+	if updated.Name != "v2リリース" || updated.Description != "" {
+		t.Errorf("Unexpected collection: %+v", updated)
+	}
+
+	// 他のユーザーのコレクションは存在しないものとして扱う
+	if _, err := collectionUseCase.GetCollection("user2", created.ID); !errors.Is(err, model.ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound, got %v", err)
+	}
+	if err := collectionUseCase.DeleteCollection("user2", created.ID); !errors.Is(err, model.ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound, got %v", err)
+	}
+
+	if err := collectionUseCase.DeleteCollection("user1", created.ID); err != nil {
+		t.Fatalf("Failed to delete collection: %v", err)
+	}
+	collections, _ := collectionUseCase.ListCollections("user1")
+	if len(collections) != 0 {
+		t.Errorf("Expected no collections, got %d", len(collections))
+	}
+}
+
+// お気に入りをコレクションとタグで整理し、絞り込めることを確認する
+func TestBacklogItemUseCase_AnnotateFavorite(t *testing.T) {
+	favoriteRepo := memory.NewFavoriteRepository()
+	backlogUseCase := NewBacklogItemUseCase(NewMockBacklogItemService(), favoriteRepo, createTestAuthUseCase(), nil)
+	collectionUseCase := NewCollectionUseCase(favoriteRepo)
+
+	for _, itemID := range []string{"1", "2"} {
+		if err := backlogUseCase.AddFavorite("user1", itemID); err != nil {
+			t.Fatalf("Failed to add favorite: %v", err)
+		}
+	}
+	collection, err := collectionUseCase.CreateCollection("user1", CollectionInput{Name: "レビュー待ち"})
+	if err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	others, _ := collectionUseCase.CreateCollection("user2", CollectionInput{Name: "他人のコレクション"})
+
+	err = backlogUseCase.AnnotateFavorite("user1", "1", FavoriteAnnotationInput{
+		CollectionID: collection.ID,
+		Tags:         []string{" 重要 ", "重要", "", "UI"},
+		Note:         "金曜までに確認",
+	})
+	if err != nil {
+		t.Fatalf("Failed to annotate favorite: %v", err)
+	}
+
+	testCases := []struct {
+		name          string
+		input         FavoriteAnnotationInput
+		itemID        string
+		expectedError error
+	}{
+		{"他のユーザーのコレクション", FavoriteAnnotationInput{CollectionID: others.ID}, "2", model.ErrCollectionNotFound},
+		{"お気に入りでない更新情報", FavoriteAnnotationInput{}, "3", model.ErrFavoriteNotFound},
+		{"長すぎるメモ", FavoriteAnnotationInput{Note: strings.Repeat("あ", maxNoteLength+1)}, "2", ErrInvalidFavoriteInput},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := backlogUseCase.AnnotateFavorite("user1", tc.itemID, tc.input); !errors.Is(err, tc.expectedError) {
+				t.Errorf("Expected %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+
+	page, err := backlogUseCase.GetFavorites("user1", FavoriteFilter{CollectionID: collection.ID, Tag: "重要"}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get favorites: %v", err)
+	}
+	favorites := page.Items
+	if len(favorites) != 1 || favorites[0].ID != "1" {
+		t.Fatalf("Unexpected favorites: %+v", favorites)
+	}
+	if len(favorites[0].Tags) != 2 || favorites[0].Note != "金曜までに確認" {
+		t.Errorf("Unexpected annotations: %+v", favorites[0])
+	}
+
+	// コレクションを削除するとお気に入りは未分類に戻る
+	if err := collectionUseCase.DeleteCollection("user1", collection.ID); err != nil {
+		t.Fatalf("Failed to delete collection: %v", err)
+	}
+	page, _ = backlogUseCase.GetFavorites("user1", FavoriteFilter{Tag: "重要"}, model.PageRequest{})
+	favorites = page.Items
+	if len(favorites) != 1 || favorites[0].CollectionID != "" {
+		t.Errorf("Expected favorite to be detached from collection, got %+v", favorites)
+	}
+}
+
+// 共有コレクションの権限ごとに閲覧・変更できる範囲を確認する
+func TestCollectionUseCase_SharedCollection(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	for _, userID := range []string{"owner", "editor", "viewer", "outsider"} {
+		authRepo.SaveToken(&model.AuthToken{AccessToken: "token-" + userID, ExpiresAt: time.Now().Add(time.Hour), UserID: userID})
+	}
+	favoriteRepo := memory.NewFavoriteRepository()
+	backlogUseCase := NewBacklogItemUseCase(NewMockBacklogItemService(), favoriteRepo, NewAuthUseCase(&MockAuthService{}, authRepo), nil)
+	collectionUseCase := NewCollectionUseCase(favoriteRepo)
+
+	collection, err := collectionUseCase.CreateCollection("owner", CollectionInput{Name: "チーム共有"})
+	if err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	for userID, role := range map[string]model.CollectionRole{"editor": model.CollectionRoleEditor, "viewer": model.CollectionRoleViewer} {
+		if _, err := collectionUseCase.InviteMember("owner", collection.ID, CollectionMemberInput{UserID: userID, Role: role}); err != nil {
+			t.Fatalf("Failed to invite %s: %v", userID, err)
+		}
+	}
+	if _, err := collectionUseCase.InviteMember("editor", collection.ID, CollectionMemberInput{UserID: "outsider", Role: model.CollectionRoleViewer}); !errors.Is(err, model.ErrCollectionForbidden) {
+		t.Errorf("Expected ErrCollectionForbidden, got %v", err)
+	}
+	if _, err := collectionUseCase.InviteMember("owner", collection.ID, CollectionMemberInput{UserID: "outsider", Role: model.CollectionRoleOwner}); !errors.Is(err, ErrInvalidFavoriteInput) {
+		t.Errorf("Expected ErrInvalidFavoriteInput, got %v", err)
+	}
+
+	// 編集者はコレクションにお気に入りを入れられるが、閲覧者は入れられない
+	for _, userID := range []string{"editor", "viewer"} {
+		if err := backlogUseCase.AddFavorite(userID, "1"); err != nil {
+			t.Fatalf("Failed to add favorite: %v", err)
+		}
+	}
+	if err := backlogUseCase.AnnotateFavorite("editor", "1", FavoriteAnnotationInput{CollectionID: collection.ID, Tags: []string{"障害"}, Note: "要確認"}); err != nil {
+		t.Fatalf("Failed to add favorite to collection: %v", err)
+	}
+	if err := backlogUseCase.AnnotateFavorite("viewer", "1", FavoriteAnnotationInput{CollectionID: collection.ID}); !errors.Is(err, model.ErrCollectionForbidden) {
+		t.Errorf("Expected ErrCollectionForbidden, got %v", err)
+	}
+
+	// メンバーは他のメンバーが入れたお気に入りを閲覧でき、メンバー以外には存在を明かさない
+	page, err := backlogUseCase.GetFavorites("viewer", FavoriteFilter{CollectionID: collection.ID}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get collection items: %v", err)
+	}
+	favorites := page.Items
+	if len(favorites) != 1 || !favorites[0].IsFavorite {
+		t.Errorf("Unexpected collection items: %+v", favorites)
+	}
+
+	// 他のメンバーのタグとメモは表示されず、タグで絞り込んでも一致しない
+	if len(favorites) == 1 && (favorites[0].Note != "" || len(favorites[0].Tags) != 0) {
+		t.Errorf("Expected no note or tags of other member, got %q %v", favorites[0].Note, favorites[0].Tags)
+	}
+	page, err = backlogUseCase.GetFavorites("viewer", FavoriteFilter{CollectionID: collection.ID, Tag: "障害"}, model.PageRequest{})
+	if err != nil || len(page.Items) != 0 {
+		t.Errorf("Expected no items for other member's tag, got %+v %v", page, err)
+	}
+	page, err = backlogUseCase.GetFavorites("editor", FavoriteFilter{CollectionID: collection.ID}, model.PageRequest{})
+	if err != nil || len(page.Items) != 1 || page.Items[0].Note != "要確認" || !slices.Equal(page.Items[0].Tags, []string{"障害"}) {
+		t.Errorf("Expected own note and tags, got %+v %v", page, err)
+	}
+	if _, err := backlogUseCase.GetFavorites("outsider", FavoriteFilter{CollectionID: collection.ID}, model.PageRequest{}); !errors.Is(err, model.ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound, got %v", err)
+	}
+
+	shared, _ := collectionUseCase.ListCollections("viewer")
+	if len(shared) != 1 || shared[0].Role != model.CollectionRoleViewer {
+		t.Errorf("Unexpected shared collections: %+v", shared)
+	}
+
+	if err := collectionUseCase.RemoveCollectionItem("viewer", collection.ID, "1"); !errors.Is(err, model.ErrCollectionForbidden) {
+		t.Errorf("Expected ErrCollectionForbidden, got %v", err)
+	}
+	if _, err := collectionUseCase.UpdateCollection("editor", collection.ID, CollectionInput{Name: "改名"}); !errors.Is(err, model.ErrCollectionForbidden) {
+		t.Errorf("Expected ErrCollectionForbidden, got %v", err)
+	}
+
+	// メンバーは自分自身だけを削除（退出）できる
+	if err := collectionUseCase.RemoveMember("viewer", collection.ID, "editor"); !errors.Is(err, model.ErrCollectionForbidden) {
+		t.Errorf("Expected ErrCollectionForbidden, got %v", err)
+	}
+	if err := collectionUseCase.RemoveMember("viewer", collection.ID, "viewer"); err != nil {
+		t.Fatalf("Failed to leave collection: %v", err)
+	}
+	if _, err := collectionUseCase.GetCollection("viewer", collection.ID); !errors.Is(err, model.ErrCollectionNotFound) {
+		t.Errorf("Expected ErrCollectionNotFound after leaving, got %v", err)
+	}
+
+	members, _ := collectionUseCase.ListMembers("owner", collection.ID)
+	if len(members) != 2 || members[0].Role != model.CollectionRoleOwner {
+		t.Errorf("Unexpected members: %+v", members)
+	}
+}
+
+// 共有コレクションでも、ユーザー自身の権限で閲覧できない他のメンバーのお気に入りは表示しないことを確認する
+func TestCollectionUseCase_SharedCollectionHidesInaccessibleItems(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	for _, userID := range []string{"owner", "viewer"} {
+		authRepo.SaveToken(&model.AuthToken{AccessToken: "token-" + userID, ExpiresAt: time.Now().Add(time.Hour), UserID: userID})
+	}
+	mockBacklogService := NewMockBacklogItemService()
+	// 閲覧者は更新情報2のプロジェクトに参加していない
+	mockBacklogService.inaccessible = map[string][]string{"token-viewer": {"2"}}
+	favoriteRepo := memory.NewFavoriteRepository()
+	backlogUseCase := NewBacklogItemUseCase(mockBacklogService, favoriteRepo, NewAuthUseCase(&MockAuthService{}, authRepo), nil)
+	collectionUseCase := NewCollectionUseCase(favoriteRepo)
+
+	collection, err := collectionUseCase.CreateCollection("owner", CollectionInput{Name: "チーム共有"})
+	if err != nil {
+		t.Fatalf("Failed to create collection: %v", err)
+	}
+	if _, err := collectionUseCase.InviteMember("owner", collection.ID, CollectionMemberInput{UserID: "viewer", Role: model.CollectionRoleViewer}); err != nil {
+		t.Fatalf("Failed to invite viewer: %v", err)
+	}
+	for _, itemID := range []string{"1", "2"} {
+		if err := backlogUseCase.AddFavorite("owner", itemID); err != nil {
+			t.Fatalf("Failed to add favorite: %v", err)
+		}
+		if err := backlogUseCase.AnnotateFavorite("owner", itemID, FavoriteAnnotationInput{CollectionID: collection.ID}); err != nil {
+			t.Fatalf("Failed to add favorite to collection: %v", err)
+		}
+	}
+
+	page, err := backlogUseCase.GetFavorites("viewer", FavoriteFilter{CollectionID: collection.ID}, model.PageRequest{})
+	if err != nil {
+		t.Fatalf("Failed to get collection items: %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "1" {
+		t.Errorf("Expected only item 1 to be visible to the viewer, got %+v", page.Items)
+	}
+
+	page, err = backlogUseCase.GetFavorites("owner", FavoriteFilter{CollectionID: collection.ID}, model.PageRequest{})
+	if err != nil || len(page.Items) != 2 {
+		t.Errorf("Expected both items for the owner, got %+v %v", page, err)
+	}
+}
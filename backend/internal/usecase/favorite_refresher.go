@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// FavoriteRefresher は認証済みユーザーのお気に入りのスナップショットを定期的に最新の課題の内容で更新する
+type FavoriteRefresher struct {
+	authRepository     model.AuthRepository
+	backlogItemUseCase *BacklogItemUseCase
+}
+
+// NewFavoriteRefresher はFavoriteRefresherのインスタンスを生成
+func NewFavoriteRefresher(authRepository model.AuthRepository, backlogItemUseCase *BacklogItemUseCase) *FavoriteRefresher {
+	return &FavoriteRefresher{
+		authRepository:     authRepository,
+		backlogItemUseCase: backlogItemUseCase,
+	}
+}
+
+// Run はintervalごとに全ユーザーのスナップショットを更新し、ctxがキャンセルされるまで繰り返す
+func (r *FavoriteRefresher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RefreshAll()
+		}
+	}
+}
+
+// RefreshAll はトークンを保存している全ユーザーのスナップショットを更新
+func (r *FavoriteRefresher) RefreshAll() {
+	tokens, err := r.authRepository.GetAllTokens()
+	if err != nil {
+		log.Printf("Failed to list tokens for favorite refresh: %v", err)
+		return
+	}
+
+	for _, token := range tokens {
+		if err := r.backlogItemUseCase.RefreshFavoriteSnapshots(token.UserID); err != nil {
+			log.Printf("Failed to refresh favorites for user %s: %v", token.UserID, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// ErrInvalidSearchCriteria は検索条件が不正なエラー
+var ErrInvalidSearchCriteria = errors.New("invalid search criteria")
+
+// maxActivityTypeID はBacklog APIのアクティビティ種別コードの最大値
+const maxActivityTypeID = 26
+
+// dateLayout は日付のみで期間を指定する場合の形式
+const dateLayout = "2006-01-02"
+
+// SearchInput はBacklog更新情報の検索条件の入力データ
+// Since・UntilはRFC3339形式または日付（YYYY-MM-DD）で指定し、日付のみのUntilはその日の終わりまでを含む
+type SearchInput struct {
+	Keyword        string
+	ProjectIDs     []string
+	TypeIDs        []int
+	CreatedUserIDs []string
+	Since          string
+	Until          string
+}
+
+// criteria は入力データを検証し、ドメインの検索条件に変換
+func (in SearchInput) criteria() (model.SearchCriteria, error) {
+	for _, typeID := range in.TypeIDs {
+		if typeID < 1 || typeID > maxActivityTypeID {
+			return model.SearchCriteria{}, fmt.Errorf("%w: unknown activity type %d", ErrInvalidSearchCriteria, typeID)
+		}
+	}
+
+	since, err := parseSearchTime(in.Since, false)
+	if err != nil {
+		return model.SearchCriteria{}, err
+	}
+	until, err := parseSearchTime(in.Until, true)
+	if err != nil {
+		return model.SearchCriteria{}, err
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return model.SearchCriteria{}, fmt.Errorf("%w: since must be before until", ErrInvalidSearchCriteria)
+	}
+
+	return model.SearchCriteria{
+		Keyword:        in.Keyword,
+		ProjectIDs:     in.ProjectIDs,
+		TypeIDs:        in.TypeIDs,
+		CreatedUserIDs: in.CreatedUserIDs,
+		Since:          since,
+		Until:          until,
+	}, nil
+}
+
+// parseSearchTime は期間指定の文字列を日時に変換。空文字列はゼロ値を返す
+func parseSearchTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(dateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q", ErrInvalidSearchCriteria, value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
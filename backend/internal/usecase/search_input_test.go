@@ -0,0 +1,56 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSearchInput_Criteria(t *testing.T) {
+	input := SearchInput{
+		Keyword: "バグ",
+		TypeIDs: []int{1, 26},
+		Since:   "2024-04-01",
+		Until:   "2024-04-30",
+	}
+
+	criteria, err := input.criteria()
+	if err != nil {
+		t.Fatalf("Failed to build criteria: %v", err)
+	}
+
+	if !criteria.Since.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Unexpected since: %v", criteria.Since)
+	}
+	// 日付のみのUntilは当日の終わりまでを含む
+	if !criteria.Until.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Unexpected until: %v", criteria.Until)
+	}
+
+	criteria, err = SearchInput{Since: "2024-04-01T09:00:00+09:00"}.criteria()
+	if err != nil {
+		t.Fatalf("Failed to build criteria: %v", err)
+	}
+	if !criteria.Since.Equal(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected since: %v", criteria.Since)
+	}
+}
+
+func TestSearchInput_InvalidCriteria(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input SearchInput
+	}{
+		{"未知の種別", SearchInput{TypeIDs: []int{27}}},
+		{"不正な日付", SearchInput{Since: "2024/04/01"}},
+		{"逆転した期間", SearchInput{Since: "2024-05-01", Until: "2024-04-01"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.input.criteria(); !errors.Is(err, ErrInvalidSearchCriteria) {
+				t.Errorf("Expected ErrInvalidSearchCriteria, got %v", err)
+			}
+		})
+	}
+}
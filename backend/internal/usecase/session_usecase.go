@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/domain/model"
+)
+
+// ErrInvalidSession は無効なセッションエラー
+var ErrInvalidSession = fmt.Errorf("%w: invalid session", ErrUnauthorized)
+
+// ErrInvalidExchangeCode は無効な交換コードエラー
+var ErrInvalidExchangeCode = fmt.Errorf("%w: invalid exchange code", ErrUnauthorized)
+
+// exchangeCodeTTL は交換コードの有効期間
+const exchangeCodeTTL = time.Minute
+
+// SessionUseCase はログインセッションの発行と検証に関するユースケース
+type SessionUseCase struct {
+	authRepository model.AuthRepository
+	secret         []byte
+	ttl            time.Duration
+}
+
+// NewSessionUseCase はSessionUseCaseのインスタンスを生成
+func NewSessionUseCase(authRepository model.AuthRepository, secret []byte, ttl time.Duration) *SessionUseCase {
+	return &SessionUseCase{
+		authRepository: authRepository,
+		secret:         secret,
+		ttl:            ttl,
+	}
+}
+
+// CreateSession はユーザーのセッションを発行し、署名付きのセッショントークンを返す
+func (u *SessionUseCase) CreateSession(userID string) (string, *model.Session, error) {
+	id, err := randomString(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	now := time.Now()
+	session := &model.Session{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(u.ttl),
+	}
+
+	if err := u.authRepository.SaveSession(session); err != nil {
+		return "", nil, err
+	}
+
+	return id + "." + u.sign(id), session, nil
+}
+
+// CreateExchangeCode はセッションと交換するための一度きりの短命なコードを発行
+func (u *SessionUseCase) CreateExchangeCode(userID string) (string, error) {
+	code, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = u.authRepository.SaveExchangeCode(&model.ExchangeCode{
+		Code:      code,
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(exchangeCodeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// ExchangeSession は交換コードを消費してセッションを発行
+func (u *SessionUseCase) ExchangeSession(code string) (string, *model.Session, error) {
+	if code == "" {
+		return "", nil, ErrInvalidExchangeCode
+	}
+
+	exchangeCode, err := u.authRepository.ConsumeExchangeCode(code)
+	if err != nil {
+		return "", nil, ErrInvalidExchangeCode
+	}
+
+	if exchangeCode.ExpiresAt.Before(time.Now()) {
+		return "", nil, ErrInvalidExchangeCode
+	}
+
+	return u.CreateSession(exchangeCode.UserID)
+}
+
+// ResolveSession はセッショントークンを検証し、対応するセッションを取得
+func (u *SessionUseCase) ResolveSession(token string) (*model.Session, error) {
+	id, ok := u.verify(token)
+	if !ok {
+		return nil, ErrInvalidSession
+	}
+
+	session, err := u.authRepository.GetSession(id)
+	if err != nil {
+		return nil, ErrInvalidSession
+	}
+
+	// 有効期限切れのセッションは削除して拒否
+	if session.ExpiresAt.Before(time.Now()) {
+		_ = u.authRepository.DeleteSession(id)
+		return nil, ErrInvalidSession
+	}
+
+	return session, nil
+}
+
+// DeleteSession はセッショントークンに対応するセッションを破棄
+func (u *SessionUseCase) DeleteSession(token string) error {
+	id, ok := u.verify(token)
+	if !ok {
+		return ErrInvalidSession
+	}
+
+	return u.authRepository.DeleteSession(id)
+}
+
+// TTL はセッションの有効期間を返す
+func (u *SessionUseCase) TTL() time.Duration {
+	return u.ttl
+}
+
+// sign はセッションIDのHMAC署名を生成
+func (u *SessionUseCase) sign(id string) string {
+	mac := hmac.New(sha256.New, u.secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verify はセッショントークンの署名を検証し、セッションIDを返す
+func (u *SessionUseCase) verify(token string) (string, bool) {
+	id, signature, found := strings.Cut(token, ".")
+	if !found || id == "" {
+		return "", false
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(u.sign(id))) {
+		return "", false
+	}
+
+	return id, true
+}
+
+// randomString は暗号論的乱数からURLセーフな文字列を生成
+func randomString(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
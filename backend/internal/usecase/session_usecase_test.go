@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"nulab-exam.backlog.jp/KOU/app/backend/internal/infrastructure/persistence/memory"
+)
+
+func TestSessionUseCase_CreateAndResolve(t *testing.T) {
+	authRepo := memory.NewAuthRepository()
+	sessionUseCase := NewSessionUseCase(authRepo, []byte("test-secret"), time.Hour)
+
+	token, _, err := sessionUseCase.CreateSession("user1")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	session, err := sessionUseCase.ResolveSession(token)
+	if err != nil {
+		t.Fatalf("Failed to resolve session: %v", err)
+	}
+	if session.UserID != "user1" {
+		t.Errorf("Expected user1, got %s", session.UserID)
+	}
+
+	// 別の秘密鍵で署名されたトークンは拒否される
+	otherUseCase := NewSessionUseCase(authRepo, []byte("other-secret"), time.Hour)
+	if _, err := otherUseCase.ResolveSession(token); err != ErrInvalidSession {
+		t.Errorf("Expected ErrInvalidSession for forged token, got %v", err)
+	}
+
+	// 署名部分を改ざんしたトークンは拒否される
+	if _, err := sessionUseCase.ResolveSession(token + "x"); err != ErrInvalidSession {
+		t.Errorf("Expected ErrInvalidSession for tampered token, got %v", err)
+	}
+
+	// 削除後のセッションは拒否される
+	if err := sessionUseCase.DeleteSession(token); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if _, err := sessionUseCase.ResolveSession(token); err != ErrInvalidSession {
+		t.Errorf("Expected ErrInvalidSession after deletion, got %v", err)
+	}
+}
+
+func TestSessionUseCase_Expired(t *testing.T) {
+	sessionUseCase := NewSessionUseCase(memory.NewAuthRepository(), []byte("test-secret"), -time.Minute)
+
+	token, _, err := sessionUseCase.CreateSession("user1")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	if _, err := sessionUseCase.ResolveSession(token); err != ErrInvalidSession {
+		t.Errorf("Expected ErrInvalidSession for expired session, got %v", err)
+	}
+}
+
+func TestSessionUseCase_ExchangeSession(t *testing.T) {
+	sessionUseCase := NewSessionUseCase(memory.NewAuthRepository(), []byte("test-secret"), time.Hour)
+
+	code, err := sessionUseCase.CreateExchangeCode("user1")
+	if err != nil {
+		t.Fatalf("Failed to create exchange code: %v", err)
+	}
+
+	token, session, err := sessionUseCase.ExchangeSession(code)
+	if err != nil {
+		t.Fatalf("Failed to exchange session: %v", err)
+	}
+	if session.UserID != "user1" || token == "" {
+		t.Errorf("Unexpected session: %+v", session)
+	}
+
+	// 交換コードは一度しか使えない
+	if _, _, err := sessionUseCase.ExchangeSession(code); err != ErrInvalidExchangeCode {
+		t.Errorf("Expected ErrInvalidExchangeCode for reused code, got %v", err)
+	}
+}
@@ -12,16 +12,9 @@ interface User {
   mailAddress?: string;
 }
 
-interface AuthToken {
-  accessToken: string;
-  tokenType: string;
-  expiresAt: string;
-}
-
 interface AuthContextType {
   isAuthenticated: boolean;
   user: User | null;
-  token: AuthToken | null;
   login: (code: string) => Promise<void>;
   logout: () => void;
   loading: boolean;
@@ -32,7 +25,6 @@ interface AuthContextType {
 const initialAuthContext: AuthContextType = {
   isAuthenticated: false,
   user: null,
-  token: null,
   login: async () => {},
   logout: () => {},
   loading: false,
@@ -51,24 +43,15 @@ interface AuthProviderProps {
 export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   const [isAuthenticated, setIsAuthenticated] = useState<boolean>(false);
   const [user, setUser] = useState<User | null>(null);
-  const [token, setToken] = useState<AuthToken | null>(null);
   const [loading, setLoading] = useState<boolean>(false);
   const [error, setError] = useState<string | null>(null);
 
-  // ローカルストレージから認証状態を復元
+  // ローカルストレージから認証状態を復元（トークンはセッションCookieとしてバックエンドが管理）
   useEffect(() => {
     const storedUser = localStorage.getItem('user');
-    const storedToken = localStorage.getItem('accessToken');
-    const storedTokenType = localStorage.getItem('tokenType');
-    const storedExpiresAt = localStorage.getItem('expiresAt');
 
-    if (storedUser && storedToken && storedTokenType && storedExpiresAt) {
+    if (storedUser) {
       setUser(JSON.parse(storedUser));
-      setToken({
-        accessToken: storedToken,
-        tokenType: storedTokenType,
-        expiresAt: storedExpiresAt,
-      });
       setIsAuthenticated(true);
     }
   }, []);
@@ -79,23 +62,25 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
     setError(null);
 
     try {
-      const response = await fetch(`${API_URL}/api/auth/callback?code=${code}`);
+      // 一度きりの交換コードをセッションCookieに交換
+      const response = await fetch(`${API_URL}/api/auth/session`, {
+        method: 'POST',
+        credentials: 'include',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ code }),
+      });
       const data = await response.json();
 
       if (!response.ok) {
         throw new Error(data.error || 'Authentication failed');
       }
 
-      const { token, user } = data;
+      const { user } = data;
 
       // ローカルストレージに保存
       localStorage.setItem('user', JSON.stringify(user));
-      localStorage.setItem('accessToken', token.accessToken);
-      localStorage.setItem('tokenType', token.tokenType);
-      localStorage.setItem('expiresAt', token.expiresAt);
 
       setUser(user);
-      setToken(token);
       setIsAuthenticated(true);
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Authentication failed');
@@ -108,24 +93,17 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   const logout = () => {
     // ローカルストレージをクリア
     localStorage.removeItem('user');
-    localStorage.removeItem('accessToken');
-    localStorage.removeItem('tokenType');
-    localStorage.removeItem('expiresAt');
 
     setUser(null);
-    setToken(null);
     setIsAuthenticated(false);
 
-    // サーバーサイドのログアウト処理（オプション）
-    if (user) {
-      fetch(`${API_URL}/api/auth/logout/${user.id}`, { method: 'GET' }).catch(console.error);
-    }
+    // サーバーサイドのログアウト処理（セッションCookieを破棄）
+    fetch(`${API_URL}/api/auth/logout`, { method: 'POST', credentials: 'include' }).catch(console.error);
   };
 
   const value = {
     isAuthenticated,
     user,
-    token,
     login,
     logout,
     loading,
@@ -1,24 +1,13 @@
 import { ApolloClient, InMemoryCache, createHttpLink } from '@apollo/client';
-import { setContext } from '@apollo/client/link/context';
 
-// REST APIをGraphQLで扱うための設定
+// バックエンドのGraphQLエンドポイント（認証はセッションCookieで行う）
 const httpLink = createHttpLink({
-  uri: '/graphql', // バックエンドのGraphQLエンドポイント（実際にはREST APIをマッピング）
-});
-
-// 認証用のヘッダーを追加
-const authLink = setContext((_, { headers }) => {
-  const token = localStorage.getItem('accessToken');
-  return {
-    headers: {
-      ...headers,
-      authorization: token ? `Bearer ${token}` : '',
-    },
-  };
+  uri: '/graphql',
+  credentials: 'include',
 });
 
 // Apollo Clientの初期化
 export const client = new ApolloClient({
-  link: authLink.concat(httpLink),
+  link: httpLink,
   cache: new InMemoryCache(),
-}); 
\ No newline at end of file
+});
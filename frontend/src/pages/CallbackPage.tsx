@@ -1,56 +1,47 @@
-import React, { useEffect, useState } from 'react';
+import React, { useEffect, useRef, useState } from 'react';
 import { useNavigate, useLocation } from 'react-router-dom';
 import { useAuth } from '../context/AuthContext';
 import './CallbackPage.css';
 
 const CallbackPage: React.FC = () => {
-  const { isAuthenticated, loading: authLoading, error: authError } = useAuth();
+  const { isAuthenticated, login, loading: authLoading, error: authError } = useAuth();
   const [loading, setLoading] = useState<boolean>(true);
   const [error, setError] = useState<string | null>(null);
   const navigate = useNavigate();
   const location = useLocation();
 
+  // 交換コードを送信済みのクエリ文字列（交換コードは一度しか使えないため同じコードで再送しない）
+  const exchangedSearch = useRef<string | null>(null);
+
+  // すでに認証済みの場合、または交換コードをセッションに交換できた場合はホームページにリダイレクト
   useEffect(() => {
-    // すでに認証済みの場合はホームページにリダイレクト
-    if (isAuthenticated && !authLoading) {
+    if (isAuthenticated) {
       navigate('/');
+    }
+  }, [isAuthenticated, navigate]);
+
+  useEffect(() => {
+    if (isAuthenticated || exchangedSearch.current === location.search) {
       return;
     }
+    exchangedSearch.current = location.search;
 
     const processCallback = async () => {
       try {
         const params = new URLSearchParams(location.search);
-        const tokenBase64 = params.get('token');
-        const userBase64 = params.get('user');
+        const code = params.get('code');
         const errorParam = params.get('error');
 
         if (errorParam) {
           throw new Error(`Authorization error: ${errorParam}`);
         }
 
-        if (!tokenBase64 || !userBase64) {
-          throw new Error('Token or user data is missing');
+        if (!code) {
+          throw new Error('Exchange code is missing');
         }
 
-        // Base64デコード
-        const tokenJSON = atob(tokenBase64.replace(/-/g, '+').replace(/_/g, '/'));
-        const userJSON = atob(userBase64.replace(/-/g, '+').replace(/_/g, '/'));
-        
-        // JSONをオブジェクトに変換
-        const token = JSON.parse(tokenJSON);
-        const user = JSON.parse(userJSON);
-        
-        // ローカルストレージに保存
-        localStorage.setItem('user', JSON.stringify(user));
-        localStorage.setItem('accessToken', token.accessToken);
-        localStorage.setItem('tokenType', token.tokenType);
-        localStorage.setItem('expiresAt', token.expiresAt);
-        
-        // 短い遅延を追加して、状態が更新される時間を確保
-        setTimeout(() => {
-          // ホームページにリダイレクト
-          navigate('/');
-        }, 500);
+        // 交換コードをセッションに交換（成功するとisAuthenticatedが更新される）
+        await login(code);
       } catch (err) {
         setError(err instanceof Error ? err.message : 'Authentication failed');
       } finally {
@@ -59,7 +50,7 @@ const CallbackPage: React.FC = () => {
     };
 
     processCallback();
-  }, [location, navigate, isAuthenticated, authLoading]);
+  }, [location.search, login, isAuthenticated]);
 
   return (
     <div className="callback-page">
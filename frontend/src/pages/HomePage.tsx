@@ -14,10 +14,33 @@ interface BacklogItem {
     name: string;
   };
   created: string;
+  url?: string;
   isFavorite: boolean;
   isUpdating?: boolean;
+  highlights?: Highlight[];
 }
 
+// 検索キーワードに一致した箇所を含むフィールドの断片
+interface Highlight {
+  field: string;
+  segments: { text: string; match: boolean }[];
+}
+
+// Backlogの対応する画面へのリンクとしてIDを表示
+const renderID = (item: BacklogItem) =>
+  item.url ? <a href={item.url} target="_blank" rel="noopener noreferrer">{item.id}</a> : item.id;
+
+// 内容のハイライトがあれば一致箇所を強調して表示
+const renderSummary = (item: BacklogItem) => {
+  const highlight = item.highlights?.find((h) => h.field === 'contentSummary');
+  if (!highlight) {
+    return item.contentSummary;
+  }
+  return highlight.segments.map((segment, i) =>
+    segment.match ? <mark key={i}>{segment.text}</mark> : <span key={i}>{segment.text}</span>
+  );
+};
+
 // AI分析結果の型定義
 interface AIAnalysis {
   summary: string;
@@ -26,10 +49,12 @@ interface AIAnalysis {
 }
 
 const HomePage: React.FC = () => {
-  const { user, token } = useAuth();
+  const { user } = useAuth();
   const [items, setItems] = useState<BacklogItem[]>([]);
   const [favorites, setFavorites] = useState<BacklogItem[]>([]);
   const [keyword, setKeyword] = useState<string>('');
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [favoritesNextCursor, setFavoritesNextCursor] = useState<string | null>(null);
   const [loading, setLoading] = useState<boolean>(false);
   const [error, setError] = useState<string | null>(null);
   
@@ -79,12 +104,16 @@ const HomePage: React.FC = () => {
     }
   };
 
-  // 更新情報データ取得
-  const fetchItems = async () => {
+  // 更新情報データ取得（cursorを指定した場合は続きのページを末尾に追加）
+  const fetchItems = async (cursor?: string) => {
     if (!user) return;
     
     try {
-      const response = await fetch(`${apiUrl}/api/items?userId=${user.id}&keyword=${keyword}`);
+      const params = new URLSearchParams({ keyword });
+      if (cursor) {
+        params.set('cursor', cursor);
+      }
+      const response = await fetch(`${apiUrl}/api/items?${params.toString()}`, { credentials: 'include' });
       
       // ステータスコードをチェック
       if (!response.ok) {
@@ -108,19 +137,24 @@ const HomePage: React.FC = () => {
       // サーバーから返されたisFavoriteフラグをそのまま使用
       const updatedItems = data.items || [];
       
-      setItems(updatedItems);
+      setItems(cursor ? [...items, ...updatedItems] : updatedItems);
+      setNextCursor(data.nextCursor || null);
     } catch (err) {
       console.error('Failed to fetch items:', err);
       setError(err instanceof Error ? err.message : 'Failed to fetch items');
     }
   };
 
-  // お気に入りデータ取得
-  const fetchFavorites = async () => {
+  // お気に入りデータ取得（cursorを指定した場合は続きのページを末尾に追加）
+  const fetchFavorites = async (cursor?: string) => {
     if (!user) return;
     
     try {
-      const response = await fetch(`${apiUrl}/api/favorites/${user.id}`);
+      const params = new URLSearchParams();
+      if (cursor) {
+        params.set('cursor', cursor);
+      }
+      const response = await fetch(`${apiUrl}/api/favorites?${params.toString()}`, { credentials: 'include' });
       
       // ステータスコードをチェック
       if (!response.ok) {
@@ -151,9 +185,13 @@ const HomePage: React.FC = () => {
         throw new Error(`お気に入りデータが取得できませんでした: ${response.status}`);
       }
       
+      setFavoritesNextCursor(data.nextCursor || null);
+
       // 空の配列の場合は早期リターン
       if (!data.items || !Array.isArray(data.items) || data.items.length === 0) {
-        setFavorites([]);
+        if (!cursor) {
+          setFavorites([]);
+        }
         return;
       }
 
@@ -181,10 +219,10 @@ const HomePage: React.FC = () => {
       
       
       // サーバーから取得したお気に入りデータをセット
-      setFavorites(favoritesWithFlag);
+      setFavorites(cursor ? [...favorites, ...favoritesWithFlag] : favoritesWithFlag);
       
       // 全アイテムのお気に入り状態も更新（すでにアイテムが読み込まれている場合）
-      if (items.length > 0) {
+      if (!cursor && items.length > 0) {
         const updatedItems = items.map(item => {
           const isFavorite = favoritesWithFlag.some((fav: BacklogItem) => fav.id === item.id);
           return { ...item, isFavorite };
@@ -212,10 +250,13 @@ const HomePage: React.FC = () => {
       setItems(tempUpdatedItems);
       
       // APIコールを実行し、完了するまで待機
-      const response = await fetch(`${apiUrl}/api/favorites/${user.id}/${itemId}`, { method });
+      const response = await fetch(`${apiUrl}/api/favorites/${itemId}`, { method, credentials: 'include' });
       
+      // 既に目的の状態になっている場合（追加時の409・削除時の404）も成功として扱う
+      const alreadyApplied = response.status === (isFavorite ? 404 : 409);
+
       // APIコールが成功した場合のみ状態を更新
-      if (response.ok) {
+      if (response.ok || alreadyApplied) {
         // 現在の項目の状態だけを更新
         const updatedItems = items.map(item => 
           item.id === itemId ? { ...item, isFavorite: !isFavorite, isUpdating: false } : item
@@ -380,7 +421,7 @@ const HomePage: React.FC = () => {
                             disabled={item.isUpdating}
                           />
                         </td>
-                        <td>{item.id}</td>
+                        <td>{renderID(item)}</td>
                         <td>{item.projectName}</td>
                         <td>{item.type}</td>
                         <td>{item.contentSummary}</td>
@@ -403,6 +444,11 @@ const HomePage: React.FC = () => {
                     )}
                   </tbody>
                 </table>
+                {favoritesNextCursor && (
+                  <button className="load-more-button" onClick={() => fetchFavorites(favoritesNextCursor)}>
+                    さらにお気に入りを読み込む
+                  </button>
+                )}
               </div>
             </div>
             
@@ -435,10 +481,10 @@ const HomePage: React.FC = () => {
                             disabled={item.isUpdating}
                           />
                         </td>
-                        <td>{item.id}</td>
+                        <td>{renderID(item)}</td>
                         <td>{item.projectName}</td>
                         <td>{item.type}</td>
-                        <td>{item.contentSummary}</td>
+                        <td>{renderSummary(item)}</td>
                         <td>{item.createdUser.name}</td>
                         <td>{new Date(item.created).toLocaleString()}</td>
                         <td>
@@ -458,6 +504,11 @@ const HomePage: React.FC = () => {
                     )}
                   </tbody>
                 </table>
+                {nextCursor && (
+                  <button className="load-more-button" onClick={() => fetchItems(nextCursor)}>
+                    さらに過去の更新情報を読み込む
+                  </button>
+                )}
               </div>
             </div>
           </div>
@@ -27,7 +27,8 @@ const LoginPage: React.FC = () => {
       setError(null);
 
       try {
-        const response = await fetch(`${API_URL}/api/auth/url`);
+        // コールバックをこのブラウザに限定するためのstateのCookieを受け取る
+        const response = await fetch(`${API_URL}/api/auth/url`, { credentials: 'include' });
         const data = await response.json();
 
         if (!response.ok) {